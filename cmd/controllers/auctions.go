@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"solback/internal/models"
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuctionProvider interface {
+	GetAuctions(ctx context.Context) ([]models.Auction, error)
+	GetAuction(ctx context.Context, id string) (models.Auction, error)
+}
+
+type AuctionsController struct {
+	service AuctionProvider
+}
+
+func NewAuctionsController(service AuctionProvider) (*AuctionsController, error) {
+	if service == nil {
+		return nil, errors.New("auction service is nil")
+	}
+
+	return &AuctionsController{service: service}, nil
+}
+
+func (c *AuctionsController) RegisterRoutes(router *gin.Engine) error {
+	if c == nil {
+		return errors.New("auctions controller is nil")
+	}
+	if router == nil {
+		return errors.New("router is nil")
+	}
+
+	router.GET("/auctions", c.getAuctions)
+	router.GET("/auctions/:id", c.getAuction)
+	return nil
+}
+
+func (c *AuctionsController) getAuctions(ctx *gin.Context) {
+	auctions, err := c.service.GetAuctions(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load auctions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auctions)
+}
+
+func (c *AuctionsController) getAuction(ctx *gin.Context) {
+	auction, err := c.service.GetAuction(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAuctionID) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid auction id"})
+			return
+		}
+		if errors.Is(err, services.ErrAuctionNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "auction not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load auction"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auction)
+}
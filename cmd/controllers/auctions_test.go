@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solback/internal/models"
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubAuctionService struct {
+	auctions []models.Auction
+	auction  models.Auction
+	listErr  error
+	getErr   error
+	id       string
+}
+
+func (s *stubAuctionService) GetAuctions(ctx context.Context) ([]models.Auction, error) {
+	if s.listErr != nil {
+		return nil, s.listErr
+	}
+	return s.auctions, nil
+}
+
+func (s *stubAuctionService) GetAuction(ctx context.Context, id string) (models.Auction, error) {
+	s.id = id
+	if s.getErr != nil {
+		return models.Auction{}, s.getErr
+	}
+	return s.auction, nil
+}
+
+func TestAuctionsHandlerList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller, err := NewAuctionsController(&stubAuctionService{auctions: []models.Auction{{ID: "1", Year: 2025, Month: 8}}})
+	if err != nil {
+		t.Fatalf("NewAuctionsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register auctions routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auctions", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	var resp []models.Auction
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].ID != "1" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+}
+
+func TestAuctionsHandlerListError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller, err := NewAuctionsController(&stubAuctionService{listErr: errors.New("boom")})
+	if err != nil {
+		t.Fatalf("NewAuctionsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register auctions routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auctions", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, recorder.Code)
+	}
+}
+
+func TestAuctionsHandlerGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubAuctionService{auction: models.Auction{ID: "abc", Results: []models.AuctionResult{{ID: "r1"}}}}
+
+	controller, err := NewAuctionsController(service)
+	if err != nil {
+		t.Fatalf("NewAuctionsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register auctions routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/auctions/abc", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.id != "abc" {
+		t.Fatalf("id = %q, want %q", service.id, "abc")
+	}
+	var resp models.Auction
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("results = %d, want 1", len(resp.Results))
+	}
+}
+
+func TestAuctionsHandlerGetErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{err: services.ErrInvalidAuctionID, code: http.StatusBadRequest},
+		{err: services.ErrAuctionNotFound, code: http.StatusNotFound},
+		{err: errors.New("boom"), code: http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		controller, err := NewAuctionsController(&stubAuctionService{getErr: tc.err})
+		if err != nil {
+			t.Fatalf("NewAuctionsController: %v", err)
+		}
+
+		router := gin.New()
+		if err := controller.RegisterRoutes(router); err != nil {
+			t.Fatalf("register auctions routes: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/auctions/abc", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.code {
+			t.Fatalf("error %v: expected status %d, got %d", tc.err, tc.code, recorder.Code)
+		}
+	}
+}
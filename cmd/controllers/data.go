@@ -13,7 +13,7 @@ import (
 )
 
 type DataProvider interface {
-	GetData(ctx context.Context, period string, technology string, groupPeriod string, sumTech bool, from string, to string, techIn string, sort string, limit string) ([]models.AuctionResult, error)
+	GetData(ctx context.Context, period string, technology string, region string, groupPeriod string, sumTech bool, sumRegion bool, from string, to string, techIn string, regionIn string, sort string, limit string) ([]models.AuctionResult, error)
 	DeleteData(ctx context.Context) (int, error)
 }
 
@@ -52,6 +52,8 @@ func (c *DataController) getData(ctx *gin.Context) {
 	from := ctx.Query("from")
 	to := ctx.Query("to")
 	techIn := ctx.Query("tech_in")
+	region := ctx.Query("region")
+	regionIn := ctx.Query("region_in")
 	sort := ctx.Query("sort")
 	limit := ctx.Query("limit")
 	technology := ctx.Query("tech")
@@ -68,8 +70,18 @@ func (c *DataController) getData(ctx *gin.Context) {
 		}
 		sumTech = parsed
 	}
+	sumRegionParam := ctx.Query("sum_region")
+	sumRegion := true
+	if sumRegionParam != "" {
+		parsed, err := strconv.ParseBool(sumRegionParam)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid sum_region"})
+			return
+		}
+		sumRegion = parsed
+	}
 
-	results, err := c.service.GetData(ctx.Request.Context(), period, technology, groupPeriod, sumTech, from, to, techIn, sort, limit)
+	results, err := c.service.GetData(ctx.Request.Context(), period, technology, region, groupPeriod, sumTech, sumRegion, from, to, techIn, regionIn, sort, limit)
 	if err != nil {
 		if errors.Is(err, services.ErrInvalidPeriod) {
 			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid period"})
@@ -15,29 +15,35 @@ import (
 )
 
 type stubDataService struct {
-	results []models.AuctionResult
-	getErr  error
-	delErr  error
-	period  string
-	tech    string
-	group   string
-	sumTech bool
-	from    string
-	to      string
-	techIn  string
-	sort    string
-	limit   string
-	deleted int
+	results   []models.AuctionResult
+	getErr    error
+	delErr    error
+	period    string
+	tech      string
+	region    string
+	group     string
+	sumTech   bool
+	sumRegion bool
+	from      string
+	to        string
+	techIn    string
+	regionIn  string
+	sort      string
+	limit     string
+	deleted   int
 }
 
-func (s *stubDataService) GetData(ctx context.Context, period string, technology string, groupPeriod string, sumTech bool, from string, to string, techIn string, sort string, limit string) ([]models.AuctionResult, error) {
+func (s *stubDataService) GetData(ctx context.Context, period string, technology string, region string, groupPeriod string, sumTech bool, sumRegion bool, from string, to string, techIn string, regionIn string, sort string, limit string) ([]models.AuctionResult, error) {
 	s.period = period
 	s.tech = technology
+	s.region = region
 	s.group = groupPeriod
 	s.sumTech = sumTech
+	s.sumRegion = sumRegion
 	s.from = from
 	s.to = to
 	s.techIn = techIn
+	s.regionIn = regionIn
 	s.sort = sort
 	s.limit = limit
 	if s.getErr != nil {
@@ -69,7 +75,7 @@ func TestDataHandlerSuccess(t *testing.T) {
 		t.Fatalf("register data routes: %v", err)
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/data?period=2024-2025&tech=Tech&group_period=year&sum_tech=true&from=2024-01&to=2025-12&tech_in=Solar,Wind&sort=year_desc,month_desc&limit=1", nil)
+	req := httptest.NewRequest(http.MethodGet, "/data?period=2024-2025&tech=Tech&group_period=year&sum_tech=true&from=2024-01&to=2025-12&tech_in=Solar,Wind&region=France&region_in=France,Spain&sum_region=false&sort=region_desc,month_desc&limit=1", nil)
 	recorder := httptest.NewRecorder()
 	router.ServeHTTP(recorder, req)
 
@@ -97,8 +103,17 @@ func TestDataHandlerSuccess(t *testing.T) {
 	if service.techIn != "Solar,Wind" {
 		t.Fatalf("tech_in = %q, want %q", service.techIn, "Solar,Wind")
 	}
-	if service.sort != "year_desc,month_desc" {
-		t.Fatalf("sort = %q, want %q", service.sort, "year_desc,month_desc")
+	if service.region != "France" {
+		t.Fatalf("region = %q, want %q", service.region, "France")
+	}
+	if service.regionIn != "France,Spain" {
+		t.Fatalf("region_in = %q, want %q", service.regionIn, "France,Spain")
+	}
+	if service.sumRegion {
+		t.Fatalf("sum_region = %v, want false", service.sumRegion)
+	}
+	if service.sort != "region_desc,month_desc" {
+		t.Fatalf("sort = %q, want %q", service.sort, "region_desc,month_desc")
 	}
 	if service.limit != "1" {
 		t.Fatalf("limit = %q, want %q", service.limit, "1")
@@ -180,6 +195,54 @@ func TestDataHandlerInvalidSumTech(t *testing.T) {
 	}
 }
 
+func TestDataHandlerSumRegionDefaultsToTrue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubDataService{}
+	controller, err := NewDataController(service)
+	if err != nil {
+		t.Fatalf("NewDataController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register data routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/data?group_period=year", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if !service.sumRegion {
+		t.Fatalf("sum_region = %v, want true", service.sumRegion)
+	}
+}
+
+func TestDataHandlerInvalidSumRegion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller, err := NewDataController(&stubDataService{})
+	if err != nil {
+		t.Fatalf("NewDataController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register data routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/data?sum_region=maybe", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
 func TestDataHandlerInvalidLimit(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
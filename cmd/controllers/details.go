@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"solback/internal/models"
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DetailProvider interface {
+	GetDetails(ctx context.Context, sourceFile string, technology string, region string, from string, to string, limit string) ([]models.AuctionDetail, error)
+}
+
+type DetailsController struct {
+	service DetailProvider
+}
+
+func NewDetailsController(service DetailProvider) (*DetailsController, error) {
+	if service == nil {
+		return nil, errors.New("detail service is nil")
+	}
+
+	return &DetailsController{service: service}, nil
+}
+
+func (c *DetailsController) RegisterRoutes(router *gin.Engine) error {
+	if c == nil {
+		return errors.New("details controller is nil")
+	}
+	if router == nil {
+		return errors.New("router is nil")
+	}
+
+	router.GET("/details", c.getDetails)
+	return nil
+}
+
+func (c *DetailsController) getDetails(ctx *gin.Context) {
+	sourceFile := ctx.Query("source_file")
+	region := ctx.Query("region")
+	from := ctx.Query("from")
+	to := ctx.Query("to")
+	limit := ctx.Query("limit")
+	technology := ctx.Query("tech")
+	if technology == "" {
+		technology = ctx.Query("technology")
+	}
+
+	details, err := c.service.GetDetails(ctx.Request.Context(), sourceFile, technology, region, from, to, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMonthRange) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid month range"})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidLimit) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid limit"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load details"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, details)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solback/internal/models"
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubDetailService struct {
+	details    []models.AuctionDetail
+	err        error
+	sourceFile string
+	tech       string
+	region     string
+	from       string
+	to         string
+	limit      string
+}
+
+func (s *stubDetailService) GetDetails(ctx context.Context, sourceFile string, technology string, region string, from string, to string, limit string) ([]models.AuctionDetail, error) {
+	s.sourceFile = sourceFile
+	s.tech = technology
+	s.region = region
+	s.from = from
+	s.to = to
+	s.limit = limit
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.details, nil
+}
+
+func TestDetailsHandlerSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubDetailService{
+		details: []models.AuctionDetail{{ID: "1", Region: "Bretagne", Technology: "Solaire", BidVolume: 10, BidPrice: 0.4, Awarded: true}},
+	}
+	controller, err := NewDetailsController(service)
+	if err != nil {
+		t.Fatalf("NewDetailsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register details routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/details?source_file=file.xlsx&tech=Solaire&region=Bretagne&from=2025-01&to=2025-12&limit=5", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.sourceFile != "file.xlsx" || service.tech != "Solaire" || service.region != "Bretagne" {
+		t.Fatalf("unexpected filters: %+v", service)
+	}
+	if service.from != "2025-01" || service.to != "2025-12" || service.limit != "5" {
+		t.Fatalf("unexpected range filters: %+v", service)
+	}
+
+	var resp []models.AuctionDetail
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || !resp[0].Awarded {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+}
+
+func TestDetailsHandlerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{err: services.ErrInvalidMonthRange, code: http.StatusBadRequest},
+		{err: services.ErrInvalidLimit, code: http.StatusBadRequest},
+		{err: errors.New("boom"), code: http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		controller, err := NewDetailsController(&stubDetailService{err: tc.err})
+		if err != nil {
+			t.Fatalf("NewDetailsController: %v", err)
+		}
+
+		router := gin.New()
+		if err := controller.RegisterRoutes(router); err != nil {
+			t.Fatalf("register details routes: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/details", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.code {
+			t.Fatalf("error %v: expected status %d, got %d", tc.err, tc.code, recorder.Code)
+		}
+	}
+}
+
+func TestNewDetailsControllerNilService(t *testing.T) {
+	if _, err := NewDetailsController(nil); err == nil {
+		t.Fatalf("expected error for nil service")
+	}
+}
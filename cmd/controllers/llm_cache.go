@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"solback/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultLLMCacheLimit = 20
+
+type LLMCacheProvider interface {
+	GetCacheEntries(ctx context.Context, limit int) ([]models.LLMCacheEntry, error)
+	PurgeCacheEntries(ctx context.Context, expiredOnly bool) (int, error)
+}
+
+type LLMCacheController struct {
+	service LLMCacheProvider
+}
+
+type DeleteLLMCacheResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+func NewLLMCacheController(service LLMCacheProvider) (*LLMCacheController, error) {
+	if service == nil {
+		return nil, errors.New("llm cache service is nil")
+	}
+
+	return &LLMCacheController{service: service}, nil
+}
+
+func (c *LLMCacheController) RegisterRoutes(router *gin.Engine) error {
+	if c == nil {
+		return errors.New("llm cache controller is nil")
+	}
+	if router == nil {
+		return errors.New("router is nil")
+	}
+
+	router.GET("/llm-cache", c.getEntries)
+	router.DELETE("/llm-cache", c.purgeEntries)
+	return nil
+}
+
+func (c *LLMCacheController) getEntries(ctx *gin.Context) {
+	limit := defaultLLMCacheLimit
+	if value := ctx.Query("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid llm cache limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := c.service.GetCacheEntries(ctx.Request.Context(), limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load llm cache"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (c *LLMCacheController) purgeEntries(ctx *gin.Context) {
+	expiredOnly := false
+	if value := ctx.Query("expired"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid expired"})
+			return
+		}
+		expiredOnly = parsed
+	}
+
+	deleted, err := c.service.PurgeCacheEntries(ctx.Request.Context(), expiredOnly)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to purge llm cache"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeleteLLMCacheResponse{Deleted: deleted})
+}
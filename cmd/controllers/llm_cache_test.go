@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solback/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubLLMCacheService struct {
+	entries     []models.LLMCacheEntry
+	err         error
+	limit       int
+	expiredOnly bool
+	deleted     int
+}
+
+func (s *stubLLMCacheService) GetCacheEntries(ctx context.Context, limit int) ([]models.LLMCacheEntry, error) {
+	s.limit = limit
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.entries, nil
+}
+
+func (s *stubLLMCacheService) PurgeCacheEntries(ctx context.Context, expiredOnly bool) (int, error) {
+	s.expiredOnly = expiredOnly
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.deleted, nil
+}
+
+func TestLLMCacheHandlerList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubLLMCacheService{entries: []models.LLMCacheEntry{{Key: "abc", Model: "gpt-4o-mini"}}}
+
+	controller, err := NewLLMCacheController(service)
+	if err != nil {
+		t.Fatalf("NewLLMCacheController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register llm cache routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/llm-cache?n=5", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.limit != 5 {
+		t.Fatalf("limit = %d, want 5", service.limit)
+	}
+
+	var resp []models.LLMCacheEntry
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].Key != "abc" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/llm-cache?n=zero", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestLLMCacheHandlerPurge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubLLMCacheService{deleted: 3}
+
+	controller, err := NewLLMCacheController(service)
+	if err != nil {
+		t.Fatalf("NewLLMCacheController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register llm cache routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/llm-cache?expired=true", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if !service.expiredOnly {
+		t.Fatalf("expected expired-only purge")
+	}
+
+	var resp DeleteLLMCacheResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Deleted != 3 {
+		t.Fatalf("deleted = %d, want 3", resp.Deleted)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/llm-cache?expired=maybe", nil)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestLLMCacheHandlerError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubLLMCacheService{err: errors.New("boom")}
+
+	controller, err := NewLLMCacheController(service)
+	if err != nil {
+		t.Fatalf("NewLLMCacheController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register llm cache routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/llm-cache", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, recorder.Code)
+	}
+	if service.expiredOnly {
+		t.Fatalf("expected full purge by default")
+	}
+}
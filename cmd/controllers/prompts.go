@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromptProvider interface {
+	ListPrompts() []services.PromptInfo
+	RerunPayload(ctx context.Context, sourceFile string, promptVersion string) (services.PromptComparison, error)
+}
+
+type PromptsController struct {
+	service PromptProvider
+}
+
+func NewPromptsController(service PromptProvider) (*PromptsController, error) {
+	if service == nil {
+		return nil, errors.New("prompt service is nil")
+	}
+
+	return &PromptsController{service: service}, nil
+}
+
+func (c *PromptsController) RegisterRoutes(router *gin.Engine) error {
+	if c == nil {
+		return errors.New("prompts controller is nil")
+	}
+	if router == nil {
+		return errors.New("router is nil")
+	}
+
+	router.GET("/prompts", c.listPrompts)
+	router.POST("/prompts/rerun", c.rerunPayload)
+	return nil
+}
+
+func (c *PromptsController) listPrompts(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.service.ListPrompts())
+}
+
+func (c *PromptsController) rerunPayload(ctx *gin.Context) {
+	sourceFile := strings.TrimSpace(ctx.Query("source_file"))
+	if sourceFile == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "source_file is required"})
+		return
+	}
+	version := strings.TrimSpace(ctx.Query("version"))
+
+	comparison, err := c.service.RerunPayload(ctx.Request.Context(), sourceFile, version)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownPrompt) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "unknown prompt version"})
+			return
+		}
+		if errors.Is(err, services.ErrPayloadNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "payload not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to rerun payload"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comparison)
+}
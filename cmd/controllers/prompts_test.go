@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubPromptService struct {
+	prompts    []services.PromptInfo
+	comparison services.PromptComparison
+	err        error
+	sourceFile string
+	version    string
+}
+
+func (s *stubPromptService) ListPrompts() []services.PromptInfo {
+	return s.prompts
+}
+
+func (s *stubPromptService) RerunPayload(ctx context.Context, sourceFile string, promptVersion string) (services.PromptComparison, error) {
+	s.sourceFile = sourceFile
+	s.version = promptVersion
+	if s.err != nil {
+		return services.PromptComparison{}, s.err
+	}
+	return s.comparison, nil
+}
+
+func TestPromptsHandlerList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubPromptService{prompts: []services.PromptInfo{{Name: "auction_results", Active: "v1", Versions: []string{"v1"}}}}
+
+	controller, err := NewPromptsController(service)
+	if err != nil {
+		t.Fatalf("NewPromptsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register prompts routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/prompts", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	var resp []services.PromptInfo
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].Active != "v1" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+}
+
+func TestPromptsHandlerRerun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubPromptService{comparison: services.PromptComparison{SourceFile: "file.xlsx", PromptVersion: "v2", Unchanged: 3}}
+
+	controller, err := NewPromptsController(service)
+	if err != nil {
+		t.Fatalf("NewPromptsController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register prompts routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/prompts/rerun?source_file=file.xlsx&version=v2", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.sourceFile != "file.xlsx" || service.version != "v2" {
+		t.Fatalf("rerun args = %q %q", service.sourceFile, service.version)
+	}
+	var resp services.PromptComparison
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Unchanged != 3 || resp.PromptVersion != "v2" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestPromptsHandlerRerunErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		target string
+		err    error
+		status int
+	}{
+		{name: "missing source file", target: "/prompts/rerun", status: http.StatusBadRequest},
+		{name: "unknown version", target: "/prompts/rerun?source_file=file.xlsx&version=v9", err: fmt.Errorf("%w: auction_results@v9", services.ErrUnknownPrompt), status: http.StatusBadRequest},
+		{name: "payload not found", target: "/prompts/rerun?source_file=file.xlsx", err: services.ErrPayloadNotFound, status: http.StatusNotFound},
+		{name: "rerun failure", target: "/prompts/rerun?source_file=file.xlsx", err: errors.New("boom"), status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller, err := NewPromptsController(&stubPromptService{err: tt.err})
+			if err != nil {
+				t.Fatalf("NewPromptsController: %v", err)
+			}
+
+			router := gin.New()
+			if err := controller.RegisterRoutes(router); err != nil {
+				t.Fatalf("register prompts routes: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+		})
+	}
+}
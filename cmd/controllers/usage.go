@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageProvider interface {
+	GetUsage(ctx context.Context, from string, to string) (services.UsageReport, error)
+}
+
+type UsageController struct {
+	service UsageProvider
+}
+
+func NewUsageController(service UsageProvider) (*UsageController, error) {
+	if service == nil {
+		return nil, errors.New("usage service is nil")
+	}
+
+	return &UsageController{service: service}, nil
+}
+
+func (c *UsageController) RegisterRoutes(router *gin.Engine) error {
+	if c == nil {
+		return errors.New("usage controller is nil")
+	}
+	if router == nil {
+		return errors.New("router is nil")
+	}
+
+	router.GET("/usage", c.getUsage)
+	return nil
+}
+
+func (c *UsageController) getUsage(ctx *gin.Context) {
+	report, err := c.service.GetUsage(ctx.Request.Context(), ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUsageRange) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid usage range"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to load usage"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solback/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubUsageService struct {
+	report services.UsageReport
+	err    error
+	from   string
+	to     string
+}
+
+func (s *stubUsageService) GetUsage(ctx context.Context, from string, to string) (services.UsageReport, error) {
+	s.from = from
+	s.to = to
+	if s.err != nil {
+		return services.UsageReport{}, s.err
+	}
+	return s.report, nil
+}
+
+func TestUsageHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubUsageService{report: services.UsageReport{
+		From:   "2025-01-01",
+		To:     "2025-01-31",
+		Totals: services.UsageTotals{Calls: 2, TotalTokens: 300, CostUSD: 0.5},
+	}}
+
+	controller, err := NewUsageController(service)
+	if err != nil {
+		t.Fatalf("NewUsageController: %v", err)
+	}
+
+	router := gin.New()
+	if err := controller.RegisterRoutes(router); err != nil {
+		t.Fatalf("register usage routes: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/usage?from=2025-01-01&to=2025-01-31", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+	if service.from != "2025-01-01" || service.to != "2025-01-31" {
+		t.Fatalf("range = %s..%s, want 2025-01-01..2025-01-31", service.from, service.to)
+	}
+
+	var resp services.UsageReport
+	if err := json.NewDecoder(recorder.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Totals.Calls != 2 || resp.Totals.TotalTokens != 300 {
+		t.Fatalf("totals = %+v, want 2 calls and 300 tokens", resp.Totals)
+	}
+}
+
+func TestUsageHandlerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{err: fmt.Errorf("%w: from is after to", services.ErrInvalidUsageRange), status: http.StatusBadRequest},
+		{err: errors.New("boom"), status: http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		controller, err := NewUsageController(&stubUsageService{err: tc.err})
+		if err != nil {
+			t.Fatalf("NewUsageController: %v", err)
+		}
+
+		router := gin.New()
+		if err := controller.RegisterRoutes(router); err != nil {
+			t.Fatalf("register usage routes: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status {
+			t.Fatalf("error %v: expected status %d, got %d", tc.err, tc.status, recorder.Code)
+		}
+	}
+}
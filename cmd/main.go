@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"solback/cmd/controllers"
 	"solback/internal/config"
+	"solback/internal/llmfake"
 	"solback/internal/repo"
 	"solback/internal/services"
 
@@ -17,19 +24,69 @@ import (
 	"github.com/robfig/cron/v3"
 )
 
-const defaultConfigPath = "secrets.json"
+const (
+	defaultConfigPath  = "secrets.json"
+	defaultFakeLLMAddr = "127.0.0.1:8089"
+	defaultSamplesDir  = "docs"
+)
+
+type serveOptions struct {
+	fakeLLM     bool
+	fakeAddr    string
+	fixturesDir string
+	samplesDir  string
+	fakeLatency time.Duration
+	fakeFaults  []llmfake.Fault
+}
 
 func main() {
+	options, err := parseServeOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("parse arguments: %v", err)
+	}
+
 	cfgPath := os.Getenv("CONFIG_PATH")
 	if cfgPath == "" {
 		cfgPath = defaultConfigPath
 	}
 
-	cfg, err := config.Load(cfgPath)
+	provider := ""
+	if options.fakeLLM {
+		provider = config.LLMProviderFake
+	}
+	cfg, err := config.LoadWithLLMProvider(cfgPath, provider)
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
 
+	var rootCAs *x509.CertPool
+	if options.fakeLLM {
+		fakeServer, err := startFakeLLM(options)
+		if err != nil {
+			log.Fatalf("start fake llm: %v", err)
+		}
+		defer fakeServer.Close()
+
+		cfg.LLM.BaseURL = fakeServer.URL
+		rootCAs = x509.NewCertPool()
+		rootCAs.AddCert(fakeServer.Certificate())
+		log.Printf("fake llm listening on %s, samples served from %s/samples/", fakeServer.URL, fakeServer.URL)
+	}
+
+	httpClient, err := newHTTPClient(cfg, rootCAs, !cfg.HTTP.AllowPrivateNetworks, nil)
+	if err != nil {
+		log.Fatalf("create http client: %v", err)
+	}
+
+	llmAddr, err := dialAddress(cfg.LLM.BaseURL)
+	if err != nil {
+		log.Fatalf("llm base url: %v", err)
+	}
+	llmHTTPClient, err := newHTTPClient(cfg, rootCAs, !cfg.HTTP.AllowPrivateNetworks, []string{llmAddr})
+	if err != nil {
+		log.Fatalf("create llm http client: %v", err)
+	}
+
 	db, err := repo.Connect(cfg.DBDSN)
 	if err != nil {
 		log.Fatalf("connect to database: %v", err)
@@ -49,17 +106,47 @@ func main() {
 		log.Fatalf("create log service: %v", err)
 	}
 
-	htmlService, err := services.NewHtmlService(nil)
+	retrier, err := newRetrier(cfg, logService)
+	if err != nil {
+		log.Fatalf("create retrier: %v", err)
+	}
+
+	htmlService, err := services.NewHtmlService(httpClient, retrier, cfg.HTTP.MaxHTMLBytes)
 	if err != nil {
 		log.Fatalf("create html service: %v", err)
 	}
 
-	openAiService, err := services.NewOpenAiService(cfg.OpenAIAPIKey, logService, nil, "")
+	llmUsageService, err := services.NewLLMUsageService(db, llmPrices(cfg))
+	if err != nil {
+		log.Fatalf("create llm usage service: %v", err)
+	}
+
+	llmClient, err := newLLMClient(cfg, llmHTTPClient, retrier, llmUsageService, logService)
+	if err != nil {
+		log.Fatalf("create llm client: %v", err)
+	}
+
+	llmCacheService, err := services.NewLLMCacheService(db, time.Duration(cfg.LLM.CacheTTLHours)*time.Hour)
+	if err != nil {
+		log.Fatalf("create llm cache service: %v", err)
+	}
+
+	promptRegistry, err := services.NewPromptRegistry(cfg.LLM.PromptsDir, cfg.LLM.PromptVersions)
+	if err != nil {
+		log.Fatalf("create prompt registry: %v", err)
+	}
+
+	openAiService, err := services.NewOpenAiService(llmClient, llmCacheService, promptRegistry, cfg.LLM.HTMLModel, logService)
 	if err != nil {
 		log.Fatalf("create openai service: %v", err)
 	}
 
-	zipService, err := services.NewZipService(logService, nil)
+	linkExtractor, err := services.NewHeuristicLinkExtractor(openAiService, logService)
+	if err != nil {
+		log.Fatalf("create link extractor: %v", err)
+	}
+
+	zipService, err := services.NewZipService(logService, httpClient, retrier, cfg.HTTP.MaxZipBytes)
 	if err != nil {
 		log.Fatalf("create zip service: %v", err)
 	}
@@ -69,30 +156,67 @@ func main() {
 		log.Fatalf("create xlsx service: %v", err)
 	}
 
-	csvService, err := services.NewOpenAiCsvService(cfg.OpenAIAPIKey, logService, nil, "")
+	llmCsvService, err := newOpenAiCsvService(cfg, llmClient, llmCacheService, promptRegistry, logService)
 	if err != nil {
 		log.Fatalf("create openai csv service: %v", err)
 	}
 
+	csvService, err := newAuctionParser(cfg, llmCsvService, logService)
+	if err != nil {
+		log.Fatalf("create auction parser: %v", err)
+	}
+
+	payloadService, err := services.NewAuctionPayloadService(db)
+	if err != nil {
+		log.Fatalf("create auction payload service: %v", err)
+	}
+
+	promptRerunService, err := services.NewPromptRerunService(db, promptRegistry, payloadService, llmCsvService, logService)
+	if err != nil {
+		log.Fatalf("create prompt rerun service: %v", err)
+	}
+
 	processedFileService, err := services.NewProcessedFileService(db)
 	if err != nil {
 		log.Fatalf("create processed file service: %v", err)
 	}
 
+	discoveredLinkService, err := services.NewDiscoveredLinkService(db)
+	if err != nil {
+		log.Fatalf("create discovered link service: %v", err)
+	}
+
 	dataService, err := services.NewDataService(db, logService)
 	if err != nil {
 		log.Fatalf("create data service: %v", err)
 	}
 
+	if _, err := dataService.BackfillAuctions(context.Background()); err != nil {
+		log.Fatalf("backfill auctions: %v", err)
+	}
+
+	auctionService, err := services.NewAuctionService(db)
+	if err != nil {
+		log.Fatalf("create auction service: %v", err)
+	}
+
+	workbookPool, err := services.NewBatchPool(cfg.LLM.MaxWorkbooks)
+	if err != nil {
+		log.Fatalf("create workbook pool: %v", err)
+	}
+
 	pipelineService, err := services.NewPipelineService(
 		sourceService,
 		htmlService,
-		openAiService,
+		linkExtractor,
 		zipService,
 		xlsxService,
 		processedFileService,
+		discoveredLinkService,
 		csvService,
+		payloadService,
 		dataService,
+		workbookPool,
 		logService,
 	)
 	if err != nil {
@@ -114,6 +238,31 @@ func main() {
 		log.Fatalf("create data controller: %v", err)
 	}
 
+	detailsController, err := controllers.NewDetailsController(dataService)
+	if err != nil {
+		log.Fatalf("create details controller: %v", err)
+	}
+
+	auctionsController, err := controllers.NewAuctionsController(auctionService)
+	if err != nil {
+		log.Fatalf("create auctions controller: %v", err)
+	}
+
+	llmCacheController, err := controllers.NewLLMCacheController(llmCacheService)
+	if err != nil {
+		log.Fatalf("create llm cache controller: %v", err)
+	}
+
+	usageController, err := controllers.NewUsageController(llmUsageService)
+	if err != nil {
+		log.Fatalf("create usage controller: %v", err)
+	}
+
+	promptsController, err := controllers.NewPromptsController(promptRerunService)
+	if err != nil {
+		log.Fatalf("create prompts controller: %v", err)
+	}
+
 	refreshController, err := controllers.NewRefreshController(pipelineService)
 	if err != nil {
 		log.Fatalf("create refresh controller: %v", err)
@@ -136,6 +285,21 @@ func main() {
 	if err := dataController.RegisterRoutes(router); err != nil {
 		log.Fatalf("register data routes: %v", err)
 	}
+	if err := detailsController.RegisterRoutes(router); err != nil {
+		log.Fatalf("register details routes: %v", err)
+	}
+	if err := auctionsController.RegisterRoutes(router); err != nil {
+		log.Fatalf("register auctions routes: %v", err)
+	}
+	if err := llmCacheController.RegisterRoutes(router); err != nil {
+		log.Fatalf("register llm cache routes: %v", err)
+	}
+	if err := usageController.RegisterRoutes(router); err != nil {
+		log.Fatalf("register usage routes: %v", err)
+	}
+	if err := promptsController.RegisterRoutes(router); err != nil {
+		log.Fatalf("register prompts routes: %v", err)
+	}
 	if err := refreshController.RegisterRoutes(router); err != nil {
 		log.Fatalf("register refresh routes: %v", err)
 	}
@@ -152,6 +316,147 @@ func main() {
 	}
 }
 
+func newRetrier(cfg config.Config, logService services.LogWriter) (*services.Retrier, error) {
+	breaker, err := services.NewCircuitBreaker(cfg.Retry.BreakerThreshold, time.Duration(cfg.Retry.BreakerCooldownSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := services.RetryPolicy{
+		MaxAttempts: cfg.Retry.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.Retry.BaseDelayMs) * time.Millisecond,
+		MaxDelay:    time.Duration(cfg.Retry.MaxDelayMs) * time.Millisecond,
+	}
+	return services.NewRetrier(policy, breaker, logService)
+}
+
+func parseServeOptions(args []string) (serveOptions, error) {
+	if len(args) > 0 && args[0] == "serve" {
+		args = args[1:]
+	}
+
+	options := serveOptions{}
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.BoolVar(&options.fakeLLM, "fake-llm", false, "answer llm calls from a local fake server instead of the configured provider")
+	flags.StringVar(&options.fakeAddr, "fake-llm-addr", defaultFakeLLMAddr, "listen address of the fake llm server")
+	flags.StringVar(&options.fixturesDir, "fake-llm-fixtures", "", "directory with <schema>/<prompt hash>.json or <schema>.json fixtures")
+	flags.StringVar(&options.samplesDir, "fake-llm-samples", defaultSamplesDir, "directory served under /samples/ by the fake llm server")
+	flags.DurationVar(&options.fakeLatency, "fake-llm-latency", 0, "delay added to every fake llm response")
+	flags.Func("fake-llm-fault", "simulated failure as status[:times[:retry_after_seconds]], repeatable", func(value string) error {
+		fault, err := llmfake.ParseFault(value)
+		if err != nil {
+			return err
+		}
+		options.fakeFaults = append(options.fakeFaults, fault)
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return serveOptions{}, err
+	}
+	if flags.NArg() > 0 {
+		return serveOptions{}, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	return options, nil
+}
+
+func startFakeLLM(options serveOptions) (*llmfake.RunningServer, error) {
+	server, err := llmfake.New(llmfake.Options{
+		FixturesDir: options.fixturesDir,
+		SamplesDir:  options.samplesDir,
+		Latency:     options.fakeLatency,
+		Faults:      options.fakeFaults,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return llmfake.Start(options.fakeAddr, server)
+}
+
+func newHTTPClient(cfg config.Config, rootCAs *x509.CertPool, blockPrivate bool, allowedAddrs []string) (*http.Client, error) {
+	return services.NewHTTPClient(services.HTTPClientOptions{
+		ConnectTimeout:      time.Duration(cfg.HTTP.ConnectTimeoutSeconds) * time.Second,
+		TLSHandshakeTimeout: time.Duration(cfg.HTTP.TLSTimeoutSeconds) * time.Second,
+		Timeout:             time.Duration(cfg.HTTP.TimeoutSeconds) * time.Second,
+		IdleConnTimeout:     time.Duration(cfg.HTTP.IdleTimeoutSeconds) * time.Second,
+		MaxIdleConns:        cfg.HTTP.MaxIdleConns,
+		BlockPrivate:        blockPrivate,
+		AllowedAddrs:        allowedAddrs,
+		UserAgent:           cfg.HTTP.UserAgent,
+		ProxyURL:            cfg.HTTP.ProxyURL,
+		CABundle:            cfg.HTTP.CABundle,
+		RootCAs:             rootCAs,
+	})
+}
+
+// The configured llm endpoint (the fake server under --fake-llm) may be a
+// local address; it is the only one exempt from the private network block.
+func dialAddress(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Hostname() == "" {
+		return "", fmt.Errorf("url %q has no host", rawURL)
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[parsed.Scheme]
+	}
+	return net.JoinHostPort(parsed.Hostname(), port), nil
+}
+
+func newLLMClient(cfg config.Config, httpClient *http.Client, retrier *services.Retrier, recorder services.UsageRecorder, logService services.LogWriter) (services.LLMClient, error) {
+	client := &http.Client{
+		Transport: httpClient.Transport,
+		Timeout:   time.Duration(cfg.LLM.TimeoutSeconds) * time.Second,
+	}
+	limiter, err := services.NewRateLimiter(cfg.LLM.RequestsPerMin)
+	if err != nil {
+		return nil, err
+	}
+	llmClient, err := services.NewOpenAiCompatibleClient(cfg.OpenAIAPIKey, cfg.LLM.BaseURL, cfg.LLM.Temperature, client, retrier, limiter)
+	if err != nil {
+		return nil, err
+	}
+	return services.NewUsageTrackingLLMClient(llmClient, recorder, logService)
+}
+
+func llmPrices(cfg config.Config) map[string]services.LLMPrice {
+	prices := make(map[string]services.LLMPrice, len(cfg.LLM.Prices))
+	for model, price := range cfg.LLM.Prices {
+		prices[model] = services.LLMPrice{
+			PromptPerMillion:     price.PromptPerMillion,
+			CompletionPerMillion: price.CompletionPerMillion,
+		}
+	}
+	return prices
+}
+
+func newOpenAiCsvService(cfg config.Config, llmClient services.LLMClient, llmCache services.LLMCache, prompts *services.PromptRegistry, logService services.LogWriter) (*services.OpenAiCsvService, error) {
+	pool, err := services.NewBatchPool(cfg.LLM.MaxConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	return services.NewOpenAiCsvService(llmClient, llmCache, prompts, cfg.LLM.CSVModel, pool, logService)
+}
+
+func newAuctionParser(cfg config.Config, llmParser *services.OpenAiCsvService, logService services.LogWriter) (services.AuctionParser, error) {
+	if cfg.AuctionParser == config.AuctionParserLLM {
+		return llmParser, nil
+	}
+
+	localParser, err := services.NewLocalAuctionParser(logService)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AuctionParser == config.AuctionParserLocal {
+		return localParser, nil
+	}
+
+	return services.NewFallbackAuctionParser(localParser, llmParser, logService)
+}
+
 type pipelineRefresher interface {
 	Refresh(ctx context.Context) error
 }
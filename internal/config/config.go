@@ -3,15 +3,112 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 )
 
+const (
+	AuctionParserLLM               = "llm"
+	AuctionParserLocal             = "local"
+	AuctionParserLocalWithFallback = "local_with_llm_fallback"
+)
+
+const (
+	LLMProviderOpenAI           = "openai"
+	LLMProviderOpenAICompatible = "openai_compatible"
+	LLMProviderFake             = "fake"
+
+	DefaultLLMBaseURL        = "https://api.openai.com"
+	DefaultLLMModel          = "gpt-4o-mini"
+	DefaultLLMTimeoutSeconds = 120
+	DefaultLLMCacheTTLHours  = 24
+	DefaultLLMMaxConcurrency = 4
+	DefaultLLMMaxWorkbooks   = 2
+	DefaultLLMRequestsPerMin = 60
+)
+
+const (
+	DefaultRetryMaxAttempts            = 4
+	DefaultRetryBaseDelayMs            = 500
+	DefaultRetryMaxDelayMs             = 30000
+	DefaultRetryBreakerThreshold       = 5
+	DefaultRetryBreakerCooldownSeconds = 300
+)
+
+const (
+	DefaultHTTPConnectTimeoutSeconds = 10
+	DefaultHTTPTLSTimeoutSeconds     = 10
+	DefaultHTTPTimeoutSeconds        = 60
+	DefaultHTTPIdleTimeoutSeconds    = 90
+	DefaultHTTPMaxIdleConns          = 20
+	DefaultHTTPUserAgent             = "solback/1.0 (+https://sol.trf.is)"
+	DefaultHTTPMaxHTMLBytes          = 10 << 20
+	DefaultHTTPMaxZipBytes           = 100 << 20
+)
+
+var DefaultLLMPrices = map[string]LLMPrice{
+	"gpt-4o-mini": {PromptPerMillion: 0.15, CompletionPerMillion: 0.6},
+	"gpt-4o":      {PromptPerMillion: 2.5, CompletionPerMillion: 10},
+}
+
 type Config struct {
-	DBDSN        string `json:"db_dsn"`
-	OpenAIAPIKey string `json:"openai_api_key"`
+	DBDSN         string      `json:"db_dsn"`
+	OpenAIAPIKey  string      `json:"openai_api_key"`
+	AuctionParser string      `json:"auction_parser"`
+	LLM           LLMConfig   `json:"llm"`
+	Retry         RetryConfig `json:"retry"`
+	HTTP          HTTPConfig  `json:"http"`
+}
+
+type LLMConfig struct {
+	Provider       string              `json:"provider"`
+	BaseURL        string              `json:"base_url"`
+	Model          string              `json:"model"`
+	HTMLModel      string              `json:"html_model"`
+	CSVModel       string              `json:"csv_model"`
+	Temperature    float32             `json:"temperature"`
+	TimeoutSeconds int                 `json:"timeout_seconds"`
+	CacheTTLHours  int                 `json:"cache_ttl_hours"`
+	MaxConcurrency int                 `json:"max_concurrency"`
+	MaxWorkbooks   int                 `json:"max_parallel_workbooks"`
+	RequestsPerMin int                 `json:"requests_per_minute"`
+	Prices         map[string]LLMPrice `json:"prices"`
+	PromptsDir     string              `json:"prompts_dir"`
+	PromptVersions map[string]string   `json:"prompt_versions"`
+}
+
+type RetryConfig struct {
+	MaxAttempts            int `json:"max_attempts"`
+	BaseDelayMs            int `json:"base_delay_ms"`
+	MaxDelayMs             int `json:"max_delay_ms"`
+	BreakerThreshold       int `json:"breaker_threshold"`
+	BreakerCooldownSeconds int `json:"breaker_cooldown_seconds"`
+}
+
+type HTTPConfig struct {
+	ConnectTimeoutSeconds int    `json:"connect_timeout_seconds"`
+	TLSTimeoutSeconds     int    `json:"tls_timeout_seconds"`
+	TimeoutSeconds        int    `json:"timeout_seconds"`
+	IdleTimeoutSeconds    int    `json:"idle_timeout_seconds"`
+	MaxIdleConns          int    `json:"max_idle_conns"`
+	MaxHTMLBytes          int64  `json:"max_html_bytes"`
+	MaxZipBytes           int64  `json:"max_zip_bytes"`
+	UserAgent             string `json:"user_agent"`
+	ProxyURL              string `json:"proxy_url"`
+	CABundle              string `json:"ca_bundle"`
+	AllowPrivateNetworks  bool   `json:"allow_private_networks"`
+}
+
+type LLMPrice struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
 }
 
 func Load(path string) (Config, error) {
+	return LoadWithLLMProvider(path, "")
+}
+
+func LoadWithLLMProvider(path string, provider string) (Config, error) {
 	if path == "" {
 		return Config{}, fmt.Errorf("config path is empty")
 	}
@@ -25,13 +122,207 @@ func Load(path string) (Config, error) {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("parse config: %w", err)
 	}
+	if cfg.LLM.Provider == LLMProviderFake && provider != LLMProviderFake {
+		return Config{}, fmt.Errorf("llm.provider %q is only available with --fake-llm", LLMProviderFake)
+	}
+	if provider != "" {
+		cfg.LLM.Provider = provider
+	}
 
 	if cfg.DBDSN == "" {
 		return Config{}, fmt.Errorf("db_dsn is required")
 	}
-	if cfg.OpenAIAPIKey == "" {
-		return Config{}, fmt.Errorf("openai_api_key is required")
+
+	switch cfg.AuctionParser {
+	case "":
+		cfg.AuctionParser = AuctionParserLLM
+	case AuctionParserLLM, AuctionParserLocal, AuctionParserLocalWithFallback:
+	default:
+		return Config{}, fmt.Errorf("auction_parser %q is invalid", cfg.AuctionParser)
+	}
+
+	if err := applyLLMDefaults(&cfg); err != nil {
+		return Config{}, err
+	}
+	if err := applyRetryDefaults(&cfg.Retry); err != nil {
+		return Config{}, err
+	}
+	if err := applyHTTPDefaults(&cfg.HTTP); err != nil {
+		return Config{}, err
 	}
 
 	return cfg, nil
 }
+
+func applyLLMDefaults(cfg *Config) error {
+	llm := &cfg.LLM
+
+	switch llm.Provider {
+	case "":
+		llm.Provider = LLMProviderOpenAI
+		fallthrough
+	case LLMProviderOpenAI:
+		if cfg.OpenAIAPIKey == "" {
+			return fmt.Errorf("openai_api_key is required")
+		}
+		if llm.BaseURL == "" {
+			llm.BaseURL = DefaultLLMBaseURL
+		}
+	case LLMProviderOpenAICompatible:
+		if llm.BaseURL == "" {
+			return fmt.Errorf("llm.base_url is required for provider %q", llm.Provider)
+		}
+	case LLMProviderFake:
+		llm.BaseURL = ""
+	default:
+		return fmt.Errorf("llm.provider %q is invalid", llm.Provider)
+	}
+
+	if llm.Model == "" && (llm.Provider == LLMProviderOpenAI || llm.Provider == LLMProviderFake) {
+		llm.Model = DefaultLLMModel
+	}
+	if llm.HTMLModel == "" {
+		llm.HTMLModel = llm.Model
+	}
+	if llm.CSVModel == "" {
+		llm.CSVModel = llm.Model
+	}
+	if llm.HTMLModel == "" || llm.CSVModel == "" {
+		return fmt.Errorf("llm.model is required for provider %q", llm.Provider)
+	}
+
+	if llm.Temperature < 0 || llm.Temperature > 2 {
+		return fmt.Errorf("llm.temperature %v is out of range", llm.Temperature)
+	}
+
+	switch {
+	case llm.TimeoutSeconds == 0:
+		llm.TimeoutSeconds = DefaultLLMTimeoutSeconds
+	case llm.TimeoutSeconds < 0:
+		return fmt.Errorf("llm.timeout_seconds %d is invalid", llm.TimeoutSeconds)
+	}
+
+	switch {
+	case llm.CacheTTLHours == 0:
+		llm.CacheTTLHours = DefaultLLMCacheTTLHours
+	case llm.CacheTTLHours < 0:
+		return fmt.Errorf("llm.cache_ttl_hours %d is invalid", llm.CacheTTLHours)
+	}
+
+	switch {
+	case llm.MaxConcurrency == 0:
+		llm.MaxConcurrency = DefaultLLMMaxConcurrency
+	case llm.MaxConcurrency < 0:
+		return fmt.Errorf("llm.max_concurrency %d is invalid", llm.MaxConcurrency)
+	}
+
+	switch {
+	case llm.MaxWorkbooks == 0:
+		llm.MaxWorkbooks = DefaultLLMMaxWorkbooks
+	case llm.MaxWorkbooks < 0:
+		return fmt.Errorf("llm.max_parallel_workbooks %d is invalid", llm.MaxWorkbooks)
+	}
+
+	switch {
+	case llm.RequestsPerMin == 0:
+		llm.RequestsPerMin = DefaultLLMRequestsPerMin
+	case llm.RequestsPerMin < 0:
+		return fmt.Errorf("llm.requests_per_minute %d is invalid", llm.RequestsPerMin)
+	}
+
+	if llm.Prices == nil {
+		llm.Prices = make(map[string]LLMPrice, len(DefaultLLMPrices))
+		for model, price := range DefaultLLMPrices {
+			llm.Prices[model] = price
+		}
+	}
+	for model, price := range llm.Prices {
+		if price.PromptPerMillion < 0 || price.CompletionPerMillion < 0 {
+			return fmt.Errorf("llm.prices %q must not be negative", model)
+		}
+	}
+
+	return nil
+}
+
+func applyRetryDefaults(retry *RetryConfig) error {
+	defaults := []struct {
+		name  string
+		value *int
+		def   int
+	}{
+		{"retry.max_attempts", &retry.MaxAttempts, DefaultRetryMaxAttempts},
+		{"retry.base_delay_ms", &retry.BaseDelayMs, DefaultRetryBaseDelayMs},
+		{"retry.max_delay_ms", &retry.MaxDelayMs, DefaultRetryMaxDelayMs},
+		{"retry.breaker_threshold", &retry.BreakerThreshold, DefaultRetryBreakerThreshold},
+		{"retry.breaker_cooldown_seconds", &retry.BreakerCooldownSeconds, DefaultRetryBreakerCooldownSeconds},
+	}
+	for _, field := range defaults {
+		switch {
+		case *field.value == 0:
+			*field.value = field.def
+		case *field.value < 0:
+			return fmt.Errorf("%s %d is invalid", field.name, *field.value)
+		}
+	}
+
+	if retry.MaxDelayMs < retry.BaseDelayMs {
+		return fmt.Errorf("retry.max_delay_ms %d is below retry.base_delay_ms %d", retry.MaxDelayMs, retry.BaseDelayMs)
+	}
+
+	return nil
+}
+
+func applyHTTPDefaults(httpCfg *HTTPConfig) error {
+	defaults := []struct {
+		name  string
+		value *int
+		def   int
+	}{
+		{"http.connect_timeout_seconds", &httpCfg.ConnectTimeoutSeconds, DefaultHTTPConnectTimeoutSeconds},
+		{"http.tls_timeout_seconds", &httpCfg.TLSTimeoutSeconds, DefaultHTTPTLSTimeoutSeconds},
+		{"http.timeout_seconds", &httpCfg.TimeoutSeconds, DefaultHTTPTimeoutSeconds},
+		{"http.idle_timeout_seconds", &httpCfg.IdleTimeoutSeconds, DefaultHTTPIdleTimeoutSeconds},
+		{"http.max_idle_conns", &httpCfg.MaxIdleConns, DefaultHTTPMaxIdleConns},
+	}
+	for _, field := range defaults {
+		switch {
+		case *field.value == 0:
+			*field.value = field.def
+		case *field.value < 0:
+			return fmt.Errorf("%s %d is invalid", field.name, *field.value)
+		}
+	}
+
+	limits := []struct {
+		name  string
+		value *int64
+		def   int64
+	}{
+		{"http.max_html_bytes", &httpCfg.MaxHTMLBytes, DefaultHTTPMaxHTMLBytes},
+		{"http.max_zip_bytes", &httpCfg.MaxZipBytes, DefaultHTTPMaxZipBytes},
+	}
+	for _, field := range limits {
+		switch {
+		case *field.value == 0:
+			*field.value = field.def
+		case *field.value < 0:
+			return fmt.Errorf("%s %d is invalid", field.name, *field.value)
+		}
+	}
+
+	if httpCfg.UserAgent == "" {
+		httpCfg.UserAgent = DefaultHTTPUserAgent
+	}
+	if httpCfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(httpCfg.ProxyURL)
+		if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+			return fmt.Errorf("http.proxy_url %q is invalid", httpCfg.ProxyURL)
+		}
+		if !httpCfg.AllowPrivateNetworks {
+			return fmt.Errorf("http.proxy_url requires http.allow_private_networks: the proxy resolves source hosts, so private addresses cannot be blocked")
+		}
+	}
+
+	return nil
+}
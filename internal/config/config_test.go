@@ -31,6 +31,201 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.OpenAIAPIKey != "key" {
 		t.Fatalf("OpenAIAPIKey = %q, want %q", cfg.OpenAIAPIKey, "key")
 	}
+	if cfg.AuctionParser != AuctionParserLLM {
+		t.Fatalf("AuctionParser = %q, want %q", cfg.AuctionParser, AuctionParserLLM)
+	}
+}
+
+func TestLoadConfigAuctionParser(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","openai_api_key":"key","auction_parser":"local_with_llm_fallback"}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AuctionParser != AuctionParserLocalWithFallback {
+		t.Fatalf("AuctionParser = %q, want %q", cfg.AuctionParser, AuctionParserLocalWithFallback)
+	}
+
+	invalid := writeTempFile(t, dir, "invalid_parser.json", `{"db_dsn":"dsn","openai_api_key":"key","auction_parser":"magic"}`)
+	if _, err := Load(invalid); err == nil {
+		t.Fatalf("Load invalid auction_parser: expected error")
+	}
+}
+
+func TestLoadConfigLLMDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","openai_api_key":"key"}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.LLM.Provider != LLMProviderOpenAI {
+		t.Fatalf("Provider = %q, want %q", cfg.LLM.Provider, LLMProviderOpenAI)
+	}
+	if cfg.LLM.BaseURL != DefaultLLMBaseURL {
+		t.Fatalf("BaseURL = %q, want %q", cfg.LLM.BaseURL, DefaultLLMBaseURL)
+	}
+	if cfg.LLM.HTMLModel != DefaultLLMModel || cfg.LLM.CSVModel != DefaultLLMModel {
+		t.Fatalf("models = %q/%q, want %q", cfg.LLM.HTMLModel, cfg.LLM.CSVModel, DefaultLLMModel)
+	}
+	if cfg.LLM.TimeoutSeconds != DefaultLLMTimeoutSeconds {
+		t.Fatalf("TimeoutSeconds = %d, want %d", cfg.LLM.TimeoutSeconds, DefaultLLMTimeoutSeconds)
+	}
+	if cfg.LLM.CacheTTLHours != DefaultLLMCacheTTLHours {
+		t.Fatalf("CacheTTLHours = %d, want %d", cfg.LLM.CacheTTLHours, DefaultLLMCacheTTLHours)
+	}
+	if cfg.LLM.MaxConcurrency != DefaultLLMMaxConcurrency || cfg.LLM.MaxWorkbooks != DefaultLLMMaxWorkbooks || cfg.LLM.RequestsPerMin != DefaultLLMRequestsPerMin {
+		t.Fatalf("MaxConcurrency/MaxWorkbooks/RequestsPerMin = %d/%d/%d, want defaults", cfg.LLM.MaxConcurrency, cfg.LLM.MaxWorkbooks, cfg.LLM.RequestsPerMin)
+	}
+	if price, ok := cfg.LLM.Prices[DefaultLLMModel]; !ok || price.PromptPerMillion <= 0 {
+		t.Fatalf("Prices[%q] = %+v, want default price", DefaultLLMModel, price)
+	}
+}
+
+func TestLoadConfigLLMCompatible(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","llm":{"provider":"openai_compatible","base_url":"http://localhost:11434","model":"llama3","csv_model":"qwen2.5","temperature":0.2,"timeout_seconds":30,"prices":{"llama3":{"prompt_per_million":0,"completion_per_million":0}}}}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.LLM.BaseURL != "http://localhost:11434" {
+		t.Fatalf("BaseURL = %q, want %q", cfg.LLM.BaseURL, "http://localhost:11434")
+	}
+	if cfg.LLM.HTMLModel != "llama3" {
+		t.Fatalf("HTMLModel = %q, want %q", cfg.LLM.HTMLModel, "llama3")
+	}
+	if cfg.LLM.CSVModel != "qwen2.5" {
+		t.Fatalf("CSVModel = %q, want %q", cfg.LLM.CSVModel, "qwen2.5")
+	}
+	if cfg.LLM.TimeoutSeconds != 30 {
+		t.Fatalf("TimeoutSeconds = %d, want 30", cfg.LLM.TimeoutSeconds)
+	}
+	if len(cfg.LLM.Prices) != 1 {
+		t.Fatalf("Prices = %v, want only configured prices", cfg.LLM.Prices)
+	}
+
+	cases := map[string]string{
+		"missing_base_url.json": `{"db_dsn":"dsn","llm":{"provider":"openai_compatible","model":"llama3"}}`,
+		"missing_model.json":    `{"db_dsn":"dsn","llm":{"provider":"openai_compatible","base_url":"http://localhost:11434"}}`,
+		"bad_provider.json":     `{"db_dsn":"dsn","openai_api_key":"key","llm":{"provider":"magic"}}`,
+		"bad_temperature.json":  `{"db_dsn":"dsn","openai_api_key":"key","llm":{"temperature":3}}`,
+		"bad_timeout.json":      `{"db_dsn":"dsn","openai_api_key":"key","llm":{"timeout_seconds":-1}}`,
+		"bad_cache_ttl.json":    `{"db_dsn":"dsn","openai_api_key":"key","llm":{"cache_ttl_hours":-1}}`,
+		"bad_concurrency.json":  `{"db_dsn":"dsn","openai_api_key":"key","llm":{"max_concurrency":-2}}`,
+		"bad_workbooks.json":    `{"db_dsn":"dsn","openai_api_key":"key","llm":{"max_parallel_workbooks":-1}}`,
+		"bad_rpm.json":          `{"db_dsn":"dsn","openai_api_key":"key","llm":{"requests_per_minute":-5}}`,
+		"bad_price.json":        `{"db_dsn":"dsn","openai_api_key":"key","llm":{"prices":{"gpt-4o-mini":{"prompt_per_million":-1}}}}`,
+	}
+	for name, content := range cases {
+		invalid := writeTempFile(t, dir, name, content)
+		if _, err := Load(invalid); err == nil {
+			t.Fatalf("Load %s: expected error", name)
+		}
+	}
+}
+
+func TestLoadConfigFakeLLM(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","llm":{"base_url":"https://api.openai.com"}}`)
+
+	if _, err := Load(path); err == nil {
+		t.Fatalf("Load without api key: expected error")
+	}
+
+	cfg, err := LoadWithLLMProvider(path, LLMProviderFake)
+	if err != nil {
+		t.Fatalf("LoadWithLLMProvider: %v", err)
+	}
+	if cfg.LLM.Provider != LLMProviderFake {
+		t.Fatalf("Provider = %q, want %q", cfg.LLM.Provider, LLMProviderFake)
+	}
+	if cfg.LLM.BaseURL != "" {
+		t.Fatalf("BaseURL = %q, want empty until the fake server starts", cfg.LLM.BaseURL)
+	}
+	if cfg.LLM.HTMLModel != DefaultLLMModel || cfg.LLM.CSVModel != DefaultLLMModel {
+		t.Fatalf("models = %q/%q, want %q", cfg.LLM.HTMLModel, cfg.LLM.CSVModel, DefaultLLMModel)
+	}
+
+	configured := writeTempFile(t, dir, "fake.json", `{"db_dsn":"dsn","openai_api_key":"key","llm":{"provider":"fake"}}`)
+	if _, err := Load(configured); err == nil {
+		t.Fatalf("Load with provider fake: expected error without the fake server")
+	}
+	if _, err := LoadWithLLMProvider(configured, LLMProviderFake); err != nil {
+		t.Fatalf("LoadWithLLMProvider fake config: %v", err)
+	}
+}
+
+func TestLoadConfigRetry(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","openai_api_key":"key","retry":{"max_attempts":2,"base_delay_ms":100}}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Retry.MaxAttempts != 2 || cfg.Retry.BaseDelayMs != 100 {
+		t.Fatalf("Retry = %+v, want configured attempts and base delay", cfg.Retry)
+	}
+	if cfg.Retry.MaxDelayMs != DefaultRetryMaxDelayMs || cfg.Retry.BreakerThreshold != DefaultRetryBreakerThreshold || cfg.Retry.BreakerCooldownSeconds != DefaultRetryBreakerCooldownSeconds {
+		t.Fatalf("Retry = %+v, want defaults for unset fields", cfg.Retry)
+	}
+
+	for name, content := range map[string]string{
+		"negative_attempts.json": `{"db_dsn":"dsn","openai_api_key":"key","retry":{"max_attempts":-1}}`,
+		"inverted_delays.json":   `{"db_dsn":"dsn","openai_api_key":"key","retry":{"base_delay_ms":5000,"max_delay_ms":1000}}`,
+	} {
+		invalid := writeTempFile(t, dir, name, content)
+		if _, err := Load(invalid); err == nil {
+			t.Fatalf("Load %s: expected error", name)
+		}
+	}
+}
+
+func TestLoadConfigHTTP(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTempFile(t, dir, "secrets.json", `{"db_dsn":"dsn","openai_api_key":"key"}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.HTTP.TimeoutSeconds != DefaultHTTPTimeoutSeconds || cfg.HTTP.ConnectTimeoutSeconds != DefaultHTTPConnectTimeoutSeconds {
+		t.Fatalf("HTTP = %+v, want default timeouts", cfg.HTTP)
+	}
+	if cfg.HTTP.UserAgent != DefaultHTTPUserAgent || cfg.HTTP.MaxIdleConns != DefaultHTTPMaxIdleConns {
+		t.Fatalf("HTTP = %+v, want default user agent and idle connections", cfg.HTTP)
+	}
+	if cfg.HTTP.MaxHTMLBytes != DefaultHTTPMaxHTMLBytes || cfg.HTTP.MaxZipBytes != DefaultHTTPMaxZipBytes {
+		t.Fatalf("HTTP = %+v, want default body limits", cfg.HTTP)
+	}
+
+	custom := writeTempFile(t, dir, "custom.json", `{"db_dsn":"dsn","openai_api_key":"key","http":{"timeout_seconds":15,"user_agent":"team-sol","proxy_url":"http://proxy:3128","allow_private_networks":true,"ca_bundle":"/etc/ssl/corp.pem"}}`)
+	cfg, err = Load(custom)
+	if err != nil {
+		t.Fatalf("Load custom: %v", err)
+	}
+	if cfg.HTTP.TimeoutSeconds != 15 || cfg.HTTP.UserAgent != "team-sol" || cfg.HTTP.ProxyURL != "http://proxy:3128" || cfg.HTTP.CABundle != "/etc/ssl/corp.pem" {
+		t.Fatalf("HTTP = %+v, want configured values", cfg.HTTP)
+	}
+
+	cases := map[string]string{
+		"bad_connect.json":   `{"db_dsn":"dsn","openai_api_key":"key","http":{"connect_timeout_seconds":-1}}`,
+		"bad_idle.json":      `{"db_dsn":"dsn","openai_api_key":"key","http":{"max_idle_conns":-1}}`,
+		"bad_proxy.json":     `{"db_dsn":"dsn","openai_api_key":"key","http":{"proxy_url":"proxy:3128","allow_private_networks":true}}`,
+		"blocked_proxy.json": `{"db_dsn":"dsn","openai_api_key":"key","http":{"proxy_url":"http://proxy:3128"}}`,
+		"bad_zip_limit.json": `{"db_dsn":"dsn","openai_api_key":"key","http":{"max_zip_bytes":-1}}`,
+	}
+	for name, content := range cases {
+		invalid := writeTempFile(t, dir, name, content)
+		if _, err := Load(invalid); err == nil {
+			t.Fatalf("Load %s: expected error", name)
+		}
+	}
 }
 
 func TestLoadConfigErrors(t *testing.T) {
@@ -44,6 +239,11 @@ func TestLoadConfigErrors(t *testing.T) {
 		t.Fatalf("Load missing db_dsn: expected error")
 	}
 
+	missingKey := writeTempFile(t, dir, "missing_key.json", `{"db_dsn":"dsn"}`)
+	if _, err := Load(missingKey); err == nil {
+		t.Fatalf("Load missing openai_api_key: expected error")
+	}
+
 	invalid := writeTempFile(t, dir, "invalid.json", "{")
 	if _, err := Load(invalid); err == nil {
 		t.Fatalf("Load invalid json: expected error")
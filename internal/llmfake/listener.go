@@ -0,0 +1,116 @@
+package llmfake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	certificateLifetime = 365 * 24 * time.Hour
+	readHeaderTimeout   = 10 * time.Second
+)
+
+type RunningServer struct {
+	URL         string
+	certificate *x509.Certificate
+	server      *http.Server
+}
+
+func Start(addr string, server *Server) (*RunningServer, error) {
+	if server == nil {
+		return nil, errors.New("fake llm server is nil")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	certificate, keyPair, err := selfSignedCertificate(listener.Addr())
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	httpServer := &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{keyPair},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	go func() {
+		_ = httpServer.ServeTLS(listener, "", "")
+	}()
+
+	return &RunningServer{
+		URL:         "https://" + listener.Addr().String(),
+		certificate: certificate,
+		server:      httpServer,
+	}, nil
+}
+
+func (r *RunningServer) Certificate() *x509.Certificate {
+	if r == nil {
+		return nil
+	}
+	return r.certificate
+}
+
+func (r *RunningServer) Close() error {
+	if r == nil || r.server == nil {
+		return nil
+	}
+	return r.server.Close()
+}
+
+func selfSignedCertificate(addr net.Addr) (*x509.Certificate, tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("generate serial: %w", err)
+	}
+
+	ips := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok && !tcpAddr.IP.IsUnspecified() {
+		ips = append(ips, tcpAddr.IP)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"solback fake llm"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certificateLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("create certificate: %w", err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, tls.Certificate{}, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	return certificate, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: certificate}, nil
+}
@@ -0,0 +1,258 @@
+package llmfake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	htmlMarker    = "HTML:\n"
+	payloadMarker = "Payload:\n"
+)
+
+var periodPattern = regexp.MustCompile(`(20\d{2})\s*[-_–/]\s*(20\d{2})`)
+
+type zipLinksAnswer struct {
+	Error string        `json:"error"`
+	Links []zipLinkItem `json:"links"`
+}
+
+type zipLinkItem struct {
+	Period      string `json:"period"`
+	Description string `json:"description"`
+	Link        string `json:"link"`
+}
+
+type auctionPayload struct {
+	SourceFile   string     `json:"source_file"`
+	Participants int        `json:"participants"`
+	Headers      []string   `json:"headers"`
+	Rows         [][]string `json:"rows"`
+}
+
+type auctionAnswer struct {
+	SourceFile   string       `json:"source_file"`
+	Participants int          `json:"participants"`
+	Rows         []auctionRow `json:"rows"`
+}
+
+type auctionRow struct {
+	Region                    string  `json:"region"`
+	Technology                string  `json:"technology"`
+	TotalVolumeAuctioned      float64 `json:"total_volume_auctioned"`
+	TotalVolumeSold           float64 `json:"total_volume_sold"`
+	WeightedAvgPriceEurPerMwh float64 `json:"weighted_avg_price_eur_per_mwh"`
+}
+
+type auctionColumns struct {
+	region     int
+	technology int
+	auctioned  int
+	sold       int
+	price      int
+}
+
+func answerZipLinks(prompt string) (string, error) {
+	rawHTML := prompt
+	if index := strings.LastIndex(prompt, htmlMarker); index != -1 {
+		rawHTML = prompt[index+len(htmlMarker):]
+	}
+
+	answer := zipLinksAnswer{Links: []zipLinkItem{}}
+	if strings.TrimSpace(rawHTML) == "" {
+		answer.Error = "EMPTY_HTML"
+		return encodeAnswer(answer)
+	}
+
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return "", fmt.Errorf("parse html: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "a" {
+			if link, ok := zipLinkFromAnchor(node); ok && !seen[link.Link] {
+				seen[link.Link] = true
+				answer.Links = append(answer.Links, link)
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	if len(answer.Links) == 0 {
+		answer.Error = "NO_RESULTS"
+	}
+	return encodeAnswer(answer)
+}
+
+func zipLinkFromAnchor(node *html.Node) (zipLinkItem, bool) {
+	var href string
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, "href") {
+			href = strings.TrimSpace(attr.Val)
+		}
+	}
+	if !strings.HasPrefix(href, "https://") || !strings.HasSuffix(strings.ToLower(href), ".zip") {
+		return zipLinkItem{}, false
+	}
+
+	text := strings.Join(strings.Fields(anchorText(node)), " ")
+	match := periodPattern.FindStringSubmatch(text)
+	if match == nil {
+		match = periodPattern.FindStringSubmatch(href)
+	}
+	if match == nil {
+		return zipLinkItem{}, false
+	}
+	if text == "" {
+		text = "GO " + match[1] + "-" + match[2] + " results"
+	}
+
+	return zipLinkItem{Period: match[1] + "-" + match[2], Description: text, Link: href}, true
+}
+
+func anchorText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(anchorText(child))
+		builder.WriteString(" ")
+	}
+	return builder.String()
+}
+
+func answerAuctionResults(prompt string) (string, error) {
+	rawPayload := prompt
+	if index := strings.LastIndex(prompt, payloadMarker); index != -1 {
+		rawPayload = prompt[index+len(payloadMarker):]
+	}
+	start := strings.Index(rawPayload, "{")
+	if start == -1 {
+		return "", errors.New("prompt has no json payload")
+	}
+
+	var payload auctionPayload
+	if err := json.NewDecoder(strings.NewReader(rawPayload[start:])).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode payload: %w", err)
+	}
+	if payload.SourceFile == "" {
+		return "", errors.New("payload source_file is empty")
+	}
+
+	columns, err := mapColumns(payload.Headers)
+	if err != nil {
+		return "", err
+	}
+
+	answer := auctionAnswer{
+		SourceFile:   payload.SourceFile,
+		Participants: payload.Participants,
+		Rows:         []auctionRow{},
+	}
+	for _, cells := range payload.Rows {
+		region := strings.TrimSpace(cellAt(cells, columns.region))
+		technology := strings.TrimSpace(cellAt(cells, columns.technology))
+		if region == "" || technology == "" {
+			continue
+		}
+		answer.Rows = append(answer.Rows, auctionRow{
+			Region:                    region,
+			Technology:                technology,
+			TotalVolumeAuctioned:      parseNumber(cellAt(cells, columns.auctioned)),
+			TotalVolumeSold:           parseNumber(cellAt(cells, columns.sold)),
+			WeightedAvgPriceEurPerMwh: parseNumber(cellAt(cells, columns.price)),
+		})
+	}
+	return encodeAnswer(answer)
+}
+
+func mapColumns(headers []string) (auctionColumns, error) {
+	columns := auctionColumns{region: -1, technology: -1, auctioned: -1, sold: -1, price: -1}
+	for index, header := range headers {
+		normalized := strings.ToLower(strings.Join(strings.Fields(header), " "))
+		var target *int
+		switch {
+		case strings.HasPrefix(normalized, "my "), strings.Contains(normalized, "number of winners"):
+		case strings.Contains(normalized, "total volume auction"):
+			target = &columns.auctioned
+		case strings.Contains(normalized, "total volume sold"):
+			target = &columns.sold
+		case strings.Contains(normalized, "weighted average price"):
+			target = &columns.price
+		case strings.Contains(normalized, "region"):
+			target = &columns.region
+		case strings.Contains(normalized, "technology"):
+			target = &columns.technology
+		}
+		if target != nil && *target == -1 {
+			*target = index
+		}
+	}
+
+	if columns.region == -1 {
+		return auctionColumns{}, errors.New("region column not found")
+	}
+	if columns.technology == -1 {
+		return auctionColumns{}, errors.New("technology column not found")
+	}
+	return columns, nil
+}
+
+func cellAt(cells []string, index int) string {
+	if index < 0 || index >= len(cells) {
+		return ""
+	}
+	return cells[index]
+}
+
+func parseNumber(value string) float64 {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\t':
+			return -1
+		}
+		return r
+	}, value)
+	if cleaned == "" || cleaned == "-" {
+		return 0
+	}
+
+	lastComma := strings.LastIndex(cleaned, ",")
+	lastDot := strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma != -1 && lastDot != -1 && lastComma > lastDot:
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case lastComma != -1 && lastDot != -1:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	case lastComma != -1:
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	}
+
+	parsed, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0
+	}
+	return parsed
+}
+
+func encodeAnswer(answer any) (string, error) {
+	data, err := json.Marshal(answer)
+	if err != nil {
+		return "", fmt.Errorf("encode answer: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,78 @@
+package llmfake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAnswerZipLinks(t *testing.T) {
+	prompt := "Instructions:\nignore\n\nHTML:\n" + `<table>
+<tr><td><a href="https://example.com/files/20251119_GO_2024_2025_GLOBAL_Results.zip">GO 2024-2025 auction results</a></td></tr>
+<tr><td><a href="https://example.com/files/GO_2019-2023_Global_Results.zip">Archive</a></td></tr>
+<tr><td><a href="/relative/GO_2022-2023.zip">GO 2022-2023 results</a></td></tr>
+<tr><td><a href="https://example.com/files/readme.zip">Readme</a></td></tr>
+</table>`
+
+	content, err := answerZipLinks(prompt)
+	if err != nil {
+		t.Fatalf("answerZipLinks: %v", err)
+	}
+
+	var answer zipLinksAnswer
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		t.Fatalf("decode answer: %v", err)
+	}
+	if answer.Error != "" || len(answer.Links) != 2 {
+		t.Fatalf("answer = %+v, want two absolute links", answer)
+	}
+	if answer.Links[0].Period != "2024-2025" || answer.Links[0].Description != "GO 2024-2025 auction results" {
+		t.Fatalf("first link = %+v", answer.Links[0])
+	}
+	if answer.Links[1].Period != "2019-2023" {
+		t.Fatalf("second link period = %q, want period from href", answer.Links[1].Period)
+	}
+
+	for prompt, want := range map[string]string{
+		"HTML:\n":                    "EMPTY_HTML",
+		"HTML:\n<p>no links</p>":     "NO_RESULTS",
+		"HTML:\n<a href=\"x\">x</a>": "NO_RESULTS",
+	} {
+		content, err := answerZipLinks(prompt)
+		if err != nil {
+			t.Fatalf("answerZipLinks: %v", err)
+		}
+		if err := json.Unmarshal([]byte(content), &answer); err != nil {
+			t.Fatalf("decode answer: %v", err)
+		}
+		if answer.Error != want || answer.Links == nil {
+			t.Fatalf("answer = %+v, want %s with empty links", answer, want)
+		}
+	}
+}
+
+func TestAnswerAuctionResults(t *testing.T) {
+	prompt := "Payload:\n" + `{"source_file":"file.xlsx","participants":2,"headers":["Region","Technology","My total volume","Total Volume Auctionned","Total Volume Sold","Weighted Average Price"],"rows":[["Bretagne","Wind","9","1.234,5","1,234.5",""],["","Solar","1","2","3","4"]]}`
+
+	content, err := answerAuctionResults(prompt)
+	if err != nil {
+		t.Fatalf("answerAuctionResults: %v", err)
+	}
+
+	var answer auctionAnswer
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		t.Fatalf("decode answer: %v", err)
+	}
+	if answer.SourceFile != "file.xlsx" || answer.Participants != 2 || len(answer.Rows) != 1 {
+		t.Fatalf("answer = %+v", answer)
+	}
+	row := answer.Rows[0]
+	if row.TotalVolumeAuctioned != 1234.5 || row.TotalVolumeSold != 1234.5 || row.WeightedAvgPriceEurPerMwh != 0 {
+		t.Fatalf("row = %+v", row)
+	}
+
+	for _, bad := range []string{"no payload", "Payload:\n{broken", "Payload:\n" + `{"source_file":"f","headers":["Technology"]}`} {
+		if _, err := answerAuctionResults(bad); err == nil {
+			t.Fatalf("answerAuctionResults(%q): expected error", bad)
+		}
+	}
+}
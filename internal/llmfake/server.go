@@ -0,0 +1,437 @@
+package llmfake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	completionsPath = "/v1/chat/completions"
+	samplesPrefix   = "/samples/"
+	charsPerToken   = 4
+)
+
+type Fault struct {
+	Status     int
+	Times      int
+	RetryAfter time.Duration
+	Match      string
+	Content    string
+}
+
+type Options struct {
+	FixturesDir     string
+	SamplesDir      string
+	Latency         time.Duration
+	Faults          []Fault
+	APIKey          string
+	MaxPromptTokens int
+}
+
+type Call struct {
+	Model       string
+	Temperature float64
+	Schema      string
+	Prompt      string
+	APIKey      string
+}
+
+type Server struct {
+	fixturesDir     string
+	samplesDir      string
+	latency         time.Duration
+	apiKey          string
+	maxPromptTokens int
+
+	mu       sync.Mutex
+	faults   []Fault
+	served   []int
+	requests int
+	calls    []Call
+}
+
+type chatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema json.RawMessage `json:"json_schema"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   chatUsage    `json:"usage"`
+}
+
+type chatChoice struct {
+	Index        int         `json:"index"`
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+func New(options Options) (*Server, error) {
+	for _, dir := range []string{options.FixturesDir, options.SamplesDir} {
+		if dir == "" {
+			continue
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+	}
+	if options.Latency < 0 {
+		return nil, errors.New("latency must not be negative")
+	}
+	if options.MaxPromptTokens < 0 {
+		return nil, errors.New("max prompt tokens must not be negative")
+	}
+	for _, fault := range options.Faults {
+		if fault.Content != "" && fault.Status != 0 && fault.Status != http.StatusOK {
+			return nil, fmt.Errorf("fault with content must not set status %d", fault.Status)
+		}
+		if fault.Content == "" && fault.Status < http.StatusBadRequest {
+			return nil, fmt.Errorf("fault status %d is not an error status", fault.Status)
+		}
+		if fault.Times < 0 {
+			return nil, errors.New("fault times must not be negative")
+		}
+	}
+
+	return &Server{
+		fixturesDir:     options.FixturesDir,
+		samplesDir:      options.SamplesDir,
+		latency:         options.Latency,
+		apiKey:          options.APIKey,
+		maxPromptTokens: options.MaxPromptTokens,
+		faults:          append([]Fault(nil), options.Faults...),
+		served:          make([]int, len(options.Faults)),
+	}, nil
+}
+
+func ParseFault(spec string) (Fault, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return Fault{}, fmt.Errorf("fault %q must look like status[:times[:retry_after_seconds]]", spec)
+	}
+
+	status, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Fault{}, fmt.Errorf("fault %q: parse status: %w", spec, err)
+	}
+	fault := Fault{Status: status}
+	if len(parts) > 1 {
+		times, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return Fault{}, fmt.Errorf("fault %q: parse times: %w", spec, err)
+		}
+		fault.Times = times
+	}
+	if len(parts) > 2 {
+		seconds, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return Fault{}, fmt.Errorf("fault %q: parse retry after: %w", spec, err)
+		}
+		fault.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return fault, nil
+}
+
+func (s *Server) Requests() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) Calls() []Call {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == completionsPath:
+		s.serveCompletion(w, r)
+	case r.Method == http.MethodGet && s.samplesDir != "" && strings.HasPrefix(r.URL.Path, samplesPrefix):
+		s.serveSample(w, r, strings.TrimPrefix(r.URL.Path, samplesPrefix))
+	case r.Method == http.MethodGet && s.samplesDir != "" && strings.HasSuffix(strings.ToLower(r.URL.Path), ".zip"):
+		s.serveSample(w, r, path.Base(r.URL.Path))
+	default:
+		writeError(w, http.StatusNotFound, "not found", "invalid_request_error", "")
+	}
+}
+
+func (s *Server) serveCompletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed", "invalid_request_error", "")
+		return
+	}
+	apiKey := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if s.apiKey != "" && apiKey != s.apiKey {
+		writeError(w, http.StatusUnauthorized, "Incorrect API key provided", "invalid_request_error", "invalid_api_key")
+		return
+	}
+
+	var request chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("decode request: %v", err), "invalid_request_error", "")
+		return
+	}
+	if request.Model == "" {
+		writeError(w, http.StatusBadRequest, "model is required", "invalid_request_error", "")
+		return
+	}
+	if len(request.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages are required", "invalid_request_error", "")
+		return
+	}
+	prompt := request.Messages[len(request.Messages)-1].Content
+
+	schemaName, err := requestSchemaName(request.ResponseFormat)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "invalid_request_error", "")
+		return
+	}
+
+	requestNumber := s.countRequest(Call{
+		Model:       request.Model,
+		Temperature: request.Temperature,
+		Schema:      schemaName,
+		Prompt:      prompt,
+		APIKey:      apiKey,
+	})
+	if s.latency > 0 {
+		timer := time.NewTimer(s.latency)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	fault, faulted := s.nextFault(prompt)
+	if faulted && fault.Content == "" {
+		writeFault(w, fault)
+		return
+	}
+
+	promptTokens := EstimateTokens(prompt)
+	if s.maxPromptTokens > 0 && promptTokens > s.maxPromptTokens {
+		message := fmt.Sprintf("This model's maximum context length is %d tokens, however you requested %d tokens", s.maxPromptTokens, promptTokens)
+		writeError(w, http.StatusBadRequest, message, "invalid_request_error", "context_length_exceeded")
+		return
+	}
+
+	content := fault.Content
+	if !faulted {
+		content, err = s.answer(schemaName, prompt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error(), "invalid_request_error", "")
+			return
+		}
+	}
+
+	completionTokens := EstimateTokens(content)
+	writeJSON(w, http.StatusOK, chatResponse{
+		ID:     fmt.Sprintf("chatcmpl-fake-%d", requestNumber),
+		Object: "chat.completion",
+		Model:  request.Model,
+		Choices: []chatChoice{
+			{Message: chatMessage{Role: "assistant", Content: content}, FinishReason: "stop"},
+		},
+		Usage: chatUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	})
+}
+
+func (s *Server) answer(schemaName string, prompt string) (string, error) {
+	content, ok, err := s.fixture(schemaName, prompt)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return content, nil
+	}
+
+	switch schemaName {
+	case "zip_links":
+		return answerZipLinks(prompt)
+	case "auction_results":
+		return answerAuctionResults(prompt)
+	}
+	return "", fmt.Errorf("no fixture or rule for schema %q", schemaName)
+}
+
+func (s *Server) fixture(schemaName string, prompt string) (string, bool, error) {
+	if s.fixturesDir == "" {
+		return "", false, nil
+	}
+
+	candidates := []string{
+		filepath.Join(s.fixturesDir, schemaName, PromptHash(prompt)+".json"),
+		filepath.Join(s.fixturesDir, schemaName+".json"),
+	}
+	for _, candidate := range candidates {
+		data, err := os.ReadFile(candidate)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("read fixture: %w", err)
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	return "", false, nil
+}
+
+func (s *Server) serveSample(w http.ResponseWriter, r *http.Request, name string) {
+	cleaned := path.Clean("/" + name)
+	if cleaned == "/" {
+		writeError(w, http.StatusNotFound, "sample not found", "invalid_request_error", "")
+		return
+	}
+
+	file := filepath.Join(s.samplesDir, filepath.FromSlash(cleaned))
+	info, err := os.Stat(file)
+	if err != nil || info.IsDir() {
+		writeError(w, http.StatusNotFound, "sample not found", "invalid_request_error", "")
+		return
+	}
+	http.ServeFile(w, r, file)
+}
+
+func (s *Server) countRequest(call Call) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.calls = append(s.calls, call)
+	return s.requests
+}
+
+func (s *Server) nextFault(prompt string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for index, fault := range s.faults {
+		if fault.Match != "" && !strings.Contains(prompt, fault.Match) {
+			continue
+		}
+		if fault.Times > 0 && s.served[index] >= fault.Times {
+			continue
+		}
+		s.served[index]++
+		return fault, true
+	}
+	return Fault{}, false
+}
+
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:8])
+}
+
+func requestSchemaName(format *responseFormat) (string, error) {
+	if format == nil || len(format.JSONSchema) == 0 {
+		return "text", nil
+	}
+	if format.Type != "json_schema" {
+		return "", fmt.Errorf("response_format type %q is not supported", format.Type)
+	}
+
+	var schema struct {
+		Name   string          `json:"name"`
+		Schema json.RawMessage `json:"schema"`
+	}
+	if err := json.Unmarshal(format.JSONSchema, &schema); err != nil {
+		return "", fmt.Errorf("decode json_schema: %w", err)
+	}
+	if schema.Name == "" {
+		return "", errors.New("json_schema name is required")
+	}
+	if len(schema.Schema) == 0 {
+		return "", errors.New("json_schema schema is required")
+	}
+	return schema.Name, nil
+}
+
+func writeFault(w http.ResponseWriter, fault Fault) {
+	switch {
+	case fault.Status == http.StatusTooManyRequests:
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int((fault.RetryAfter+time.Second-1)/time.Second)))
+		}
+		writeError(w, fault.Status, "Rate limit reached for requests", "rate_limit_error", "rate_limit_exceeded")
+	case fault.Status == http.StatusBadRequest || fault.Status == http.StatusRequestEntityTooLarge:
+		writeError(w, fault.Status, "This model's maximum context length is exceeded", "invalid_request_error", "context_length_exceeded")
+	default:
+		writeError(w, fault.Status, "The server had an error while processing your request", "server_error", "")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string, errorType string, code string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Message: message, Type: errorType, Code: code}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,296 @@
+package llmfake
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const auctionResultsSchema = `{"name":"auction_results","strict":true,"schema":{"type":"object"}}`
+
+func postCompletion(t *testing.T, server *httptest.Server, schema string, prompt string) (*http.Response, []byte) {
+	t.Helper()
+
+	request := chatRequest{
+		Model:    "gpt-4o-mini",
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+	}
+	if schema != "" {
+		request.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: json.RawMessage(schema)}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("encode request: %v", err)
+	}
+
+	resp, err := server.Client().Post(server.URL+completionsPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("post completion: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	return resp, data
+}
+
+func decodeContent(t *testing.T, data []byte) (string, chatUsage) {
+	t.Helper()
+
+	var response chatResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.Choices) != 1 {
+		t.Fatalf("choices = %d, want 1", len(response.Choices))
+	}
+	return response.Choices[0].Message.Content, response.Usage
+}
+
+func newTestServer(t *testing.T, options Options) (*Server, *httptest.Server) {
+	t.Helper()
+
+	fake, err := New(options)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func TestServerAnswersFromRules(t *testing.T) {
+	fake, server := newTestServer(t, Options{})
+
+	prompt := "Payload:\n" + `{"source_file":"file.xlsx","participants":3,"headers":["Region","Technology","Total Volume Auctionned","Total Volume Sold","Weighted Average Price"],"rows":[["Normandie","Solar","1 000","-","0,35"]],"batch":1}`
+	resp, data := postCompletion(t, server, auctionResultsSchema, prompt)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.StatusCode, data)
+	}
+
+	content, usage := decodeContent(t, data)
+	want := `{"source_file":"file.xlsx","participants":3,"rows":[{"region":"Normandie","technology":"Solar","total_volume_auctioned":1000,"total_volume_sold":0,"weighted_avg_price_eur_per_mwh":0.35}]}`
+	if content != want {
+		t.Fatalf("content = %s, want %s", content, want)
+	}
+	if usage.PromptTokens == 0 || usage.TotalTokens != usage.PromptTokens+usage.CompletionTokens {
+		t.Fatalf("usage = %+v", usage)
+	}
+	if fake.Requests() != 1 {
+		t.Fatalf("requests = %d, want 1", fake.Requests())
+	}
+}
+
+func TestServerAnswersFromFixtures(t *testing.T) {
+	dir := t.TempDir()
+	prompt := "Payload:\n{}"
+	if err := os.MkdirAll(filepath.Join(dir, "auction_results"), 0o755); err != nil {
+		t.Fatalf("create fixture dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "auction_results", PromptHash(prompt)+".json"), []byte(`{"exact":true}`+"\n"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "zip_links.json"), []byte(`{"error":"NO_RESULTS","links":[]}`), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	_, server := newTestServer(t, Options{FixturesDir: dir})
+
+	_, data := postCompletion(t, server, auctionResultsSchema, prompt)
+	if content, _ := decodeContent(t, data); content != `{"exact":true}` {
+		t.Fatalf("content = %s, want exact fixture", content)
+	}
+	_, data = postCompletion(t, server, `{"name":"zip_links","schema":{"type":"object"}}`, "HTML:\n<a href=\"https://example.com/GO_2024-2025_results.zip\">GO 2024-2025 results</a>")
+	if content, _ := decodeContent(t, data); content != `{"error":"NO_RESULTS","links":[]}` {
+		t.Fatalf("content = %s, want schema fixture", content)
+	}
+
+	resp, _ := postCompletion(t, server, `{"name":"unknown","schema":{"type":"object"}}`, "hello")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown schema status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestServerSimulatesFaults(t *testing.T) {
+	_, server := newTestServer(t, Options{Faults: []Fault{
+		{Status: http.StatusTooManyRequests, Times: 1, RetryAfter: 2 * time.Second},
+		{Status: http.StatusBadRequest, Match: "too-big"},
+	}})
+
+	prompt := "Payload:\n" + `{"source_file":"file.xlsx","participants":1,"headers":["Region","Technology"],"rows":[]}`
+	resp, data := postCompletion(t, server, auctionResultsSchema, prompt)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "2" {
+		t.Fatalf("status = %d retry-after = %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+	if !strings.Contains(string(data), "rate_limit_exceeded") {
+		t.Fatalf("body = %s, want rate limit code", data)
+	}
+
+	if resp, _ := postCompletion(t, server, auctionResultsSchema, prompt); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status after fault = %d, want 200", resp.StatusCode)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, data := postCompletion(t, server, auctionResultsSchema, prompt+" too-big")
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(data), "context_length_exceeded") {
+			t.Fatalf("status = %d body = %s, want context length error", resp.StatusCode, data)
+		}
+	}
+}
+
+func TestServerChecksKeyBudgetAndRecordsCalls(t *testing.T) {
+	fake, server := newTestServer(t, Options{
+		APIKey:          "test-key",
+		MaxPromptTokens: 40,
+		Faults:          []Fault{{Content: `{"rows":"broken"}`, Times: 1}},
+	})
+
+	prompt := "Payload:\n" + `{"source_file":"file.xlsx","participants":1,"headers":["Region","Technology"],"rows":[]}`
+	if resp, _ := postCompletion(t, server, auctionResultsSchema, prompt); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without key = %d, want 401", resp.StatusCode)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, server.URL+completionsPath, strings.NewReader(`{"model":"m","temperature":0.5,"messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer test-key")
+	resp, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("post completion: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if content, _ := decodeContent(t, data); content != `{"rows":"broken"}` {
+		t.Fatalf("content = %s, want fault content", content)
+	}
+
+	request, err = http.NewRequest(http.MethodPost, server.URL+completionsPath, strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"`+strings.Repeat("x", 200)+`"}]}`))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	request.Header.Set("Authorization", "Bearer test-key")
+	resp, err = server.Client().Do(request)
+	if err != nil {
+		t.Fatalf("post completion: %v", err)
+	}
+	data, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(data), "context_length_exceeded") {
+		t.Fatalf("status = %d body = %s, want context length error", resp.StatusCode, data)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 2 || fake.Requests() != 2 {
+		t.Fatalf("calls = %+v, want the two authorized requests", calls)
+	}
+	if calls[0].Model != "m" || calls[0].Temperature != 0.5 || calls[0].Schema != "text" || calls[0].Prompt != "hi" || calls[0].APIKey != "test-key" {
+		t.Fatalf("first call = %+v", calls[0])
+	}
+
+	if _, err := New(Options{Faults: []Fault{{Status: http.StatusTooManyRequests, Content: "{}"}}}); err == nil {
+		t.Fatalf("expected error for a content fault with an error status")
+	}
+}
+
+func TestServerLatencyRespectsContext(t *testing.T) {
+	_, server := newTestServer(t, Options{Latency: time.Second})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+completionsPath, strings.NewReader(`{"model":"m","messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("create request: %v", err)
+	}
+	started := time.Now()
+	if _, err := server.Client().Do(req); err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	if time.Since(started) > 500*time.Millisecond {
+		t.Fatalf("request took %v, want cancellation before latency", time.Since(started))
+	}
+}
+
+func TestServerServesSamples(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "example.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatalf("write sample: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "results.zip"), []byte("zip"), 0o644); err != nil {
+		t.Fatalf("write sample: %v", err)
+	}
+
+	fake, err := New(Options{SamplesDir: dir})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	server, err := Start("127.0.0.1:0", fake)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer server.Close()
+	if !strings.HasPrefix(server.URL, "https://") {
+		t.Fatalf("url = %s, want https", server.URL)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+
+	for path, want := range map[string]string{
+		"/samples/example.html":          "<html></html>",
+		"/fileadmin/archive/results.zip": "zip",
+	} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(data) != want {
+			t.Fatalf("%s = %d %q, want %q", path, resp.StatusCode, data, want)
+		}
+	}
+
+	resp, err := client.Get(server.URL + "/samples/../../etc/passwd")
+	if err != nil {
+		t.Fatalf("get traversal: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("traversal status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestNewAndParseFaultErrors(t *testing.T) {
+	if _, err := New(Options{FixturesDir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatalf("expected error for missing fixtures dir")
+	}
+	if _, err := New(Options{Faults: []Fault{{Status: http.StatusOK}}}); err == nil {
+		t.Fatalf("expected error for non-error fault status")
+	}
+
+	fault, err := ParseFault("429:3:5")
+	if err != nil {
+		t.Fatalf("ParseFault: %v", err)
+	}
+	if fault.Status != http.StatusTooManyRequests || fault.Times != 3 || fault.RetryAfter != 5*time.Second {
+		t.Fatalf("fault = %+v", fault)
+	}
+	for _, spec := range []string{"", "abc", "500:x", "429:1:2:3"} {
+		if _, err := ParseFault(spec); err == nil {
+			t.Fatalf("ParseFault(%q): expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Auction struct {
+	ID            string          `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AuctionNumber *int            `gorm:"type:int" json:"auction_number"`
+	AuctionDate   *time.Time      `gorm:"type:date" json:"auction_date"`
+	Year          int             `gorm:"type:int;not null" json:"year"`
+	Month         int             `gorm:"type:int;not null" json:"month"`
+	Participants  int             `gorm:"type:int;not null" json:"participants"`
+	SourceFile    string          `gorm:"type:text;not null;uniqueIndex" json:"source_file"`
+	ZipName       *string         `gorm:"type:text" json:"zip_name,omitempty"`
+	Results       []AuctionResult `gorm:"foreignKey:AuctionID" json:"results,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+type AuctionDetail struct {
+	ID         string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	AuctionID  *string `gorm:"type:uuid;index" json:"auction_id,omitempty"`
+	SourceFile string  `gorm:"type:text;not null;index;uniqueIndex:idx_auction_details_natural_key,priority:1" json:"source_file"`
+	Sheet      string  `gorm:"type:text;not null;uniqueIndex:idx_auction_details_natural_key,priority:2" json:"sheet"`
+	SourceRow  int     `gorm:"type:int;not null;uniqueIndex:idx_auction_details_natural_key,priority:3" json:"source_row"`
+	Year       int     `gorm:"type:int;not null" json:"year"`
+	Month      int     `gorm:"type:int;not null" json:"month"`
+	Region     string  `gorm:"type:text;not null" json:"region"`
+	Technology string  `gorm:"type:text;not null" json:"technology"`
+	BidVolume  float64 `gorm:"type:double precision;not null" json:"bid_volume"`
+	BidPrice   float64 `gorm:"type:double precision;not null" json:"bid_price"`
+	Awarded    bool    `gorm:"not null;default:false" json:"awarded"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type AuctionPayload struct {
+	ID           string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SourceFile   string    `gorm:"type:text;not null;uniqueIndex" json:"source_file"`
+	ZipName      *string   `gorm:"type:text" json:"zip_name,omitempty"`
+	ContentHash  string    `gorm:"type:text;not null" json:"content_hash"`
+	Participants int       `gorm:"type:int;not null" json:"participants"`
+	HeadersJSON  string    `gorm:"type:text;not null" json:"headers_json"`
+	RowsJSON     string    `gorm:"type:text;not null" json:"rows_json"`
+	UpdatedAt    time.Time `gorm:"not null" json:"updated_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type DiscoveredLink struct {
+	ID          string     `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SourceURL   string     `gorm:"type:text;not null;index" json:"source_url"`
+	Link        string     `gorm:"type:text;not null;uniqueIndex" json:"link"`
+	Period      string     `gorm:"type:text;not null" json:"period"`
+	Description string     `gorm:"type:text;not null" json:"description"`
+	Evidence    *string    `gorm:"type:text" json:"evidence,omitempty"`
+	FirstSeenAt time.Time  `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time  `gorm:"not null" json:"last_seen_at"`
+	IngestedAt  *time.Time `json:"ingested_at"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type LLMCacheEntry struct {
+	ID        string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Key       string    `gorm:"type:text;not null;uniqueIndex" json:"key"`
+	Model     string    `gorm:"type:text;not null" json:"model"`
+	Response  string    `gorm:"type:text;not null" json:"response"`
+	Hits      int       `gorm:"not null;default:0" json:"hits"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func (LLMCacheEntry) TableName() string {
+	return "llm_cache"
+}
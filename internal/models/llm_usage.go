@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type LLMUsage struct {
+	ID               string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	EventID          *string   `gorm:"type:uuid;index" json:"event_id,omitempty"`
+	Action           string    `gorm:"type:text;not null" json:"action"`
+	Model            string    `gorm:"type:text;not null" json:"model"`
+	SourceFile       string    `gorm:"type:text;not null;default:''" json:"source_file"`
+	PromptTokens     int       `gorm:"not null" json:"prompt_tokens"`
+	CompletionTokens int       `gorm:"not null" json:"completion_tokens"`
+	TotalTokens      int       `gorm:"not null" json:"total_tokens"`
+	CostUSD          *float64  `gorm:"column:cost_usd" json:"cost_usd,omitempty"`
+	CreatedAt        time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+func (LLMUsage) TableName() string {
+	return "llm_usage"
+}
@@ -3,7 +3,9 @@ package models
 import "time"
 
 type ProcessedFile struct {
-	ID          string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	ZipFilename string    `gorm:"type:text;not null;uniqueIndex" json:"zip_filename"`
-	ProcessedAt time.Time `gorm:"not null" json:"processed_at"`
+	ID               string    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ZipFilename      string    `gorm:"type:text;not null;uniqueIndex:idx_processed_files_entry,priority:1" json:"zip_filename"`
+	WorkbookFilename string    `gorm:"type:text;not null;default:'';uniqueIndex:idx_processed_files_entry,priority:2;index" json:"workbook_filename,omitempty"`
+	ContentHash      string    `gorm:"type:text;not null;default:''" json:"content_hash,omitempty"`
+	ProcessedAt      time.Time `gorm:"not null" json:"processed_at"`
 }
@@ -1,7 +1,11 @@
 package models
 
 type Source struct {
-	ID      string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	URL     string  `gorm:"type:text;not null" json:"url"`
-	Comment *string `gorm:"type:text" json:"comment,omitempty"`
+	ID           string  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	URL          string  `gorm:"type:text;not null" json:"url"`
+	Comment      *string `gorm:"type:text" json:"comment,omitempty"`
+	ETag         *string `gorm:"column:etag;type:text" json:"etag,omitempty"`
+	LastModified *string `gorm:"type:text" json:"last_modified,omitempty"`
+	ContentHash  *string `gorm:"type:text" json:"content_hash,omitempty"`
+	AllowedHosts *string `gorm:"type:text" json:"allowed_hosts,omitempty"`
 }
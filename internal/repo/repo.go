@@ -12,7 +12,11 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-const defaultSourceConfigPath = "config.json"
+const (
+	defaultSourceConfigPath      = "config.json"
+	legacyProcessedFileZipIndex  = "idx_processed_files_zip_filename"
+	auctionResultNaturalKeyIndex = "idx_auction_results_natural_key"
+)
 
 func Connect(dsn string) (*gorm.DB, error) {
 	if dsn == "" {
@@ -34,10 +38,18 @@ func Migrate(db *gorm.DB) error {
 		return errors.New("db is nil")
 	}
 
-	if err := db.AutoMigrate(&models.Source{}, &models.Log{}, &models.AuctionResult{}, &models.ProcessedFile{}); err != nil {
+	if err := dedupeAuctionResults(db); err != nil {
+		return fmt.Errorf("dedupe auction results: %w", err)
+	}
+
+	if err := db.AutoMigrate(&models.Source{}, &models.Log{}, &models.Auction{}, &models.AuctionResult{}, &models.AuctionDetail{}, &models.ProcessedFile{}, &models.DiscoveredLink{}, &models.LLMCacheEntry{}, &models.LLMUsage{}, &models.AuctionPayload{}); err != nil {
 		return fmt.Errorf("auto migrate: %w", err)
 	}
 
+	if err := dropLegacyProcessedFileIndex(db); err != nil {
+		return fmt.Errorf("drop legacy processed file index: %w", err)
+	}
+
 	if err := ensureDefaultSource(db); err != nil {
 		return fmt.Errorf("ensure default source: %w", err)
 	}
@@ -45,6 +57,41 @@ func Migrate(db *gorm.DB) error {
 	return nil
 }
 
+func dropLegacyProcessedFileIndex(db *gorm.DB) error {
+	if db == nil {
+		return errors.New("db is nil")
+	}
+
+	migrator := db.Migrator()
+	if !migrator.HasIndex(&models.ProcessedFile{}, legacyProcessedFileZipIndex) {
+		return nil
+	}
+
+	return migrator.DropIndex(&models.ProcessedFile{}, legacyProcessedFileZipIndex)
+}
+
+func dedupeAuctionResults(db *gorm.DB) error {
+	if db == nil {
+		return errors.New("db is nil")
+	}
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.AuctionResult{}) || migrator.HasIndex(&models.AuctionResult{}, auctionResultNaturalKeyIndex) {
+		return nil
+	}
+
+	query := `DELETE FROM auction_results WHERE EXISTS (
+		SELECT 1 FROM auction_results AS kept
+		WHERE kept.year = auction_results.year
+			AND kept.month = auction_results.month
+			AND kept.region = auction_results.region
+			AND kept.technology = auction_results.technology
+			AND kept.source_file = auction_results.source_file
+			AND kept.id < auction_results.id
+	)`
+	return db.Exec(query).Error
+}
+
 func ensureDefaultSource(db *gorm.DB) error {
 	if db == nil {
 		return errors.New("db is nil")
@@ -25,7 +25,7 @@ func openRepoTestDB(t *testing.T) *gorm.DB {
 func createSourcesTableWithDefault(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	query := "CREATE TABLE sources (id TEXT PRIMARY KEY DEFAULT 'test-id', url TEXT NOT NULL, comment TEXT)"
+	query := "CREATE TABLE sources (id TEXT PRIMARY KEY DEFAULT 'test-id', url TEXT NOT NULL, comment TEXT, etag TEXT, last_modified TEXT, content_hash TEXT, allowed_hosts TEXT)"
 	if err := db.Exec(query).Error; err != nil {
 		t.Fatalf("create sources table: %v", err)
 	}
@@ -34,7 +34,7 @@ func createSourcesTableWithDefault(t *testing.T, db *gorm.DB) {
 func createSourcesTable(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	query := "CREATE TABLE sources (id TEXT PRIMARY KEY, url TEXT NOT NULL, comment TEXT)"
+	query := "CREATE TABLE sources (id TEXT PRIMARY KEY, url TEXT NOT NULL, comment TEXT, etag TEXT, last_modified TEXT, content_hash TEXT, allowed_hosts TEXT)"
 	if err := db.Exec(query).Error; err != nil {
 		t.Fatalf("create sources table: %v", err)
 	}
@@ -128,3 +128,56 @@ func TestEnsureDefaultSourceSkipsWhenNotEmpty(t *testing.T) {
 		t.Fatalf("sources count = %d, want 1", count)
 	}
 }
+
+func TestDropLegacyProcessedFileIndex(t *testing.T) {
+	db := openRepoTestDB(t)
+
+	if err := db.Exec("CREATE TABLE processed_files (id TEXT PRIMARY KEY, zip_filename TEXT NOT NULL, processed_at DATETIME NOT NULL)").Error; err != nil {
+		t.Fatalf("create processed_files table: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX idx_processed_files_zip_filename ON processed_files (zip_filename)").Error; err != nil {
+		t.Fatalf("create legacy index: %v", err)
+	}
+
+	if err := dropLegacyProcessedFileIndex(db); err != nil {
+		t.Fatalf("dropLegacyProcessedFileIndex: %v", err)
+	}
+	if db.Migrator().HasIndex(&models.ProcessedFile{}, legacyProcessedFileZipIndex) {
+		t.Fatalf("expected legacy index to be dropped")
+	}
+	if err := dropLegacyProcessedFileIndex(db); err != nil {
+		t.Fatalf("dropLegacyProcessedFileIndex second: %v", err)
+	}
+}
+
+func TestDedupeAuctionResults(t *testing.T) {
+	db := openRepoTestDB(t)
+
+	if err := db.Exec("CREATE TABLE auction_results (id TEXT PRIMARY KEY, source_file TEXT NOT NULL, year INTEGER NOT NULL, month INTEGER NOT NULL, region TEXT NOT NULL, technology TEXT NOT NULL)").Error; err != nil {
+		t.Fatalf("create auction_results table: %v", err)
+	}
+	insert := `INSERT INTO auction_results (id, source_file, year, month, region, technology) VALUES
+		('a', 'file.xlsx', 2025, 8, 'Bretagne', 'Solaire'),
+		('b', 'file.xlsx', 2025, 8, 'Bretagne', 'Solaire'),
+		('c', 'file.xlsx', 2025, 8, 'Normandie', 'Solaire'),
+		('d', 'other.xlsx', 2025, 8, 'Bretagne', 'Solaire')`
+	if err := db.Exec(insert).Error; err != nil {
+		t.Fatalf("insert auction results: %v", err)
+	}
+
+	if err := dedupeAuctionResults(db); err != nil {
+		t.Fatalf("dedupeAuctionResults: %v", err)
+	}
+
+	var ids []string
+	if err := db.Table("auction_results").Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatalf("select ids: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != "a" || ids[1] != "c" || ids[2] != "d" {
+		t.Fatalf("ids = %v, want [a c d]", ids)
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX idx_auction_results_natural_key ON auction_results (year, month, region, technology, source_file)").Error; err != nil {
+		t.Fatalf("create natural key index: %v", err)
+	}
+}
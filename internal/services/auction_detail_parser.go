@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type auctionDetailColumns struct {
+	region     int
+	technology int
+	bidVolume  int
+	bidPrice   int
+	awarded    int
+}
+
+func ParseAuctionDetails(payload AuctionDetailPayload) (AuctionDetailResults, error) {
+	if payload.SourceFile == "" {
+		return AuctionDetailResults{}, errors.New("source file is empty")
+	}
+	if len(payload.Headers) == 0 {
+		return AuctionDetailResults{}, errors.New("headers are empty")
+	}
+	if len(payload.Rows) == 0 {
+		return AuctionDetailResults{}, errors.New("rows are empty")
+	}
+
+	columns, err := mapAuctionDetailColumns(payload.Headers)
+	if err != nil {
+		return AuctionDetailResults{}, err
+	}
+
+	year, month, err := parseYearMonthFromSourceFile(payload.SourceFile)
+	if err != nil {
+		return AuctionDetailResults{}, err
+	}
+
+	result := AuctionDetailResults{SourceFile: payload.SourceFile, Sheet: payload.Sheet}
+	var parseErr error
+	for index, cells := range payload.Rows {
+		row, err := parseAuctionDetailRow(cells, columns)
+		if err != nil {
+			if parseErr == nil {
+				parseErr = fmt.Errorf("sheet %s row %d: %w", payload.Sheet, index, err)
+			}
+			continue
+		}
+		row.SourceRow = index + 1
+		row.Year = year
+		row.Month = month
+		result.Rows = append(result.Rows, row)
+	}
+
+	if len(result.Rows) == 0 {
+		if parseErr != nil {
+			return AuctionDetailResults{}, parseErr
+		}
+		return AuctionDetailResults{}, errors.New("detail rows are empty")
+	}
+	if parseErr != nil {
+		return result, parseErr
+	}
+
+	return result, nil
+}
+
+// Detail sheets follow the layout documented in docs/SPEC.md: bilingual
+// headers are matched on their English part and units in parentheses are
+// ignored, so "Bid Price (€ / MWh)" maps to "bid price".
+func mapAuctionDetailColumns(headers []string) (auctionDetailColumns, error) {
+	columns := auctionDetailColumns{region: -1, technology: -1, bidVolume: -1, bidPrice: -1, awarded: -1}
+
+	for index, header := range headers {
+		var column *int
+		switch normalizeDetailHeader(header) {
+		case "region":
+			column = &columns.region
+		case "technology":
+			column = &columns.technology
+		case "bid volume":
+			column = &columns.bidVolume
+		case "bid price":
+			column = &columns.bidPrice
+		case "awarded":
+			column = &columns.awarded
+		default:
+			continue
+		}
+		if *column == -1 {
+			*column = index
+		}
+	}
+
+	if columns.region == -1 {
+		return auctionDetailColumns{}, errors.New("region column not found")
+	}
+	if columns.technology == -1 {
+		return auctionDetailColumns{}, errors.New("technology column not found")
+	}
+	if columns.bidVolume == -1 {
+		return auctionDetailColumns{}, errors.New("bid volume column not found")
+	}
+	if columns.bidPrice == -1 {
+		return auctionDetailColumns{}, errors.New("bid price column not found")
+	}
+	if columns.awarded == -1 {
+		return auctionDetailColumns{}, errors.New("awarded column not found")
+	}
+
+	return columns, nil
+}
+
+func normalizeDetailHeader(header string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(header), " "))
+	if index := strings.Index(normalized, "("); index >= 0 {
+		normalized = normalized[:index]
+	}
+	if index := strings.LastIndex(normalized, "/"); index >= 0 {
+		normalized = normalized[index+1:]
+	}
+	return strings.TrimSpace(normalized)
+}
+
+func parseAuctionDetailRow(cells []string, columns auctionDetailColumns) (AuctionDetailRow, error) {
+	region := strings.TrimSpace(cellAt(cells, columns.region))
+	if region == "" {
+		return AuctionDetailRow{}, errors.New("region is empty")
+	}
+	technology := strings.TrimSpace(cellAt(cells, columns.technology))
+	if technology == "" {
+		return AuctionDetailRow{}, errors.New("technology is empty")
+	}
+
+	volume, err := parseCellNumber(cellAt(cells, columns.bidVolume))
+	if err != nil {
+		return AuctionDetailRow{}, fmt.Errorf("bid volume: %w", err)
+	}
+	if volume == nil {
+		return AuctionDetailRow{}, errors.New("bid volume is empty")
+	}
+	price, err := parseCellNumber(cellAt(cells, columns.bidPrice))
+	if err != nil {
+		return AuctionDetailRow{}, fmt.Errorf("bid price: %w", err)
+	}
+	if price == nil {
+		return AuctionDetailRow{}, errors.New("bid price is empty")
+	}
+	awarded, err := parseAwardedCell(cellAt(cells, columns.awarded))
+	if err != nil {
+		return AuctionDetailRow{}, fmt.Errorf("awarded: %w", err)
+	}
+
+	return AuctionDetailRow{
+		Region:     region,
+		Technology: technology,
+		BidVolume:  *volume,
+		BidPrice:   *price,
+		Awarded:    awarded,
+	}, nil
+}
+
+func parseAwardedCell(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "oui":
+		return true, nil
+	case "no", "non", "", "-":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown awarded value %q", value)
+}
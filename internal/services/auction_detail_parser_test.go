@@ -0,0 +1,66 @@
+package services
+
+import "testing"
+
+func TestParseAuctionDetails(t *testing.T) {
+	payload := AuctionDetailPayload{
+		SourceFile: "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Sheet:      "Detailed Results",
+		Headers:    []string{"Région / Region", "Technologie / Technology", "Bid Volume (MWh)", "Bid Price (€ / MWh)", "Awarded"},
+		Rows: [][]string{
+			{"Bretagne", "Solaire", "1 000", "0,45", "Oui"},
+			{"Bretagne", "Solaire", "500", "0.20", "No"},
+			{"Normandie", "Hydraulique", "-", "0.30", "Yes"},
+		},
+	}
+
+	result, err := ParseAuctionDetails(payload)
+	if err == nil {
+		t.Fatalf("expected error for row without volume")
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(result.Rows))
+	}
+
+	first := result.Rows[0]
+	if first.Year != 2025 || first.Month != 8 {
+		t.Fatalf("year/month = %d/%d, want 2025/8", first.Year, first.Month)
+	}
+	if first.BidVolume != 1000 || first.BidPrice != 0.45 || !first.Awarded {
+		t.Fatalf("first row = %+v", first)
+	}
+	if result.Rows[1].Awarded {
+		t.Fatalf("second row awarded = true, want false")
+	}
+	if result.Sheet != "Detailed Results" || first.SourceRow != 1 || result.Rows[1].SourceRow != 2 {
+		t.Fatalf("sheet/source rows = %q %d/%d, want Detailed Results 1/2", result.Sheet, first.SourceRow, result.Rows[1].SourceRow)
+	}
+}
+
+func TestParseAuctionDetailsRejectsUnknownLayout(t *testing.T) {
+	payload := AuctionDetailPayload{
+		SourceFile: "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Headers:    []string{"Region", "Technology", "Order Volume", "Order Price", "Awarded Volume"},
+		Rows:       [][]string{{"Bretagne", "Solaire", "100", "0.4", "100"}},
+	}
+	if _, err := ParseAuctionDetails(payload); err == nil {
+		t.Fatalf("expected error for headers outside the documented layout")
+	}
+
+	payload.Headers = []string{"Region", "Technology", "Bid Volume", "Bid Price", "Awarded"}
+	if _, err := ParseAuctionDetails(payload); err == nil {
+		t.Fatalf("expected error for awarded value %q", "100")
+	}
+}
+
+func TestParseAuctionDetailsMissingColumns(t *testing.T) {
+	payload := AuctionDetailPayload{
+		SourceFile: "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Headers:    []string{"Region", "Technology", "Bid Volume"},
+		Rows:       [][]string{{"Bretagne", "Solaire", "100"}},
+	}
+
+	if _, err := ParseAuctionDetails(payload); err == nil {
+		t.Fatalf("expected error for missing price column")
+	}
+}
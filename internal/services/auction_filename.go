@@ -0,0 +1,45 @@
+package services
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type auctionFileInfo struct {
+	AuctionDate   *time.Time
+	AuctionNumber *int
+	Year          int
+	Month         int
+}
+
+func parseAuctionFileInfo(sourceFile string) (auctionFileInfo, error) {
+	base := path.Base(sourceFile)
+	year, month, err := parseYearMonthFromSourceFile(base)
+	if err != nil {
+		return auctionFileInfo{}, err
+	}
+
+	info := auctionFileInfo{Year: year, Month: month}
+	parts := strings.Split(base, "_")
+	if len(parts[0]) == 8 {
+		if date, err := time.Parse("20060102", parts[0]); err == nil {
+			info.AuctionDate = &date
+		}
+	}
+
+	for i, part := range parts {
+		if _, ok := monthFromName(strings.ToLower(strings.TrimSpace(part))); !ok {
+			continue
+		}
+		if i+2 < len(parts) {
+			if number, err := strconv.Atoi(strings.TrimSpace(parts[i+2])); err == nil && number > 0 {
+				info.AuctionNumber = &number
+			}
+		}
+		break
+	}
+
+	return info, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import "testing"
+
+func TestParseAuctionFileInfo(t *testing.T) {
+	info, err := parseAuctionFileInfo("results/20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx")
+	if err != nil {
+		t.Fatalf("parseAuctionFileInfo: %v", err)
+	}
+	if info.Year != 2025 || info.Month != 8 {
+		t.Fatalf("year/month = %d/%d, want 2025/8", info.Year, info.Month)
+	}
+	if info.AuctionNumber == nil || *info.AuctionNumber != 83 {
+		t.Fatalf("auction_number = %v, want 83", info.AuctionNumber)
+	}
+	if info.AuctionDate == nil || info.AuctionDate.Format("2006-01-02") != "2025-11-19" {
+		t.Fatalf("auction_date = %v, want 2025-11-19", info.AuctionDate)
+	}
+}
+
+func TestParseAuctionFileInfoWithoutDateAndNumber(t *testing.T) {
+	info, err := parseAuctionFileInfo("August_2025.xlsx")
+	if err != nil {
+		t.Fatalf("parseAuctionFileInfo: %v", err)
+	}
+	if info.AuctionDate != nil || info.AuctionNumber != nil {
+		t.Fatalf("expected no date or number, got %+v", info)
+	}
+
+	if _, err := parseAuctionFileInfo("file.xlsx"); err == nil {
+		t.Fatalf("expected error for filename without month")
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPayloadNotFound = errors.New("payload not found")
+
+type AuctionPayloadService struct {
+	db *gorm.DB
+}
+
+func NewAuctionPayloadService(db *gorm.DB) (*AuctionPayloadService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	return &AuctionPayloadService{db: db}, nil
+}
+
+func (s *AuctionPayloadService) SavePayload(ctx context.Context, payload AuctionPayload, zipName string) error {
+	if s == nil {
+		return errors.New("auction payload service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if payload.SourceFile == "" {
+		return errors.New("source file is empty")
+	}
+
+	headers, err := json.Marshal(payload.Headers)
+	if err != nil {
+		return fmt.Errorf("encode headers: %w", err)
+	}
+	rows, err := json.Marshal(payload.Rows)
+	if err != nil {
+		return fmt.Errorf("encode rows: %w", err)
+	}
+
+	record := models.AuctionPayload{
+		SourceFile:   payload.SourceFile,
+		ContentHash:  payload.ContentHash,
+		Participants: payload.Participants,
+		HeadersJSON:  string(headers),
+		RowsJSON:     string(rows),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	if zipName != "" {
+		record.ZipName = &zipName
+	}
+
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "source_file"}},
+		DoUpdates: clause.AssignmentColumns([]string{"zip_name", "content_hash", "participants", "headers_json", "rows_json", "updated_at"}),
+	}).Create(&record).Error; err != nil {
+		return fmt.Errorf("save auction payload: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuctionPayloadService) GetPayload(ctx context.Context, sourceFile string) (AuctionPayload, error) {
+	if s == nil {
+		return AuctionPayload{}, errors.New("auction payload service is nil")
+	}
+	if s.db == nil {
+		return AuctionPayload{}, errors.New("db is nil")
+	}
+	if sourceFile == "" {
+		return AuctionPayload{}, errors.New("source file is empty")
+	}
+
+	var records []models.AuctionPayload
+	if err := s.db.WithContext(ctx).Where("source_file = ?", sourceFile).Limit(1).Find(&records).Error; err != nil {
+		return AuctionPayload{}, fmt.Errorf("load auction payload: %w", err)
+	}
+	if len(records) == 0 {
+		return AuctionPayload{}, fmt.Errorf("%w: %s", ErrPayloadNotFound, sourceFile)
+	}
+
+	record := records[0]
+	payload := AuctionPayload{
+		SourceFile:   record.SourceFile,
+		ContentHash:  record.ContentHash,
+		Participants: record.Participants,
+	}
+	if err := json.Unmarshal([]byte(record.HeadersJSON), &payload.Headers); err != nil {
+		return AuctionPayload{}, fmt.Errorf("decode headers: %w", err)
+	}
+	if err := json.Unmarshal([]byte(record.RowsJSON), &payload.Rows); err != nil {
+		return AuctionPayload{}, fmt.Errorf("decode rows: %w", err)
+	}
+
+	return payload, nil
+}
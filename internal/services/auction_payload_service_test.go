@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func createAuctionPayloadsTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := `CREATE TABLE auction_payloads (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		source_file TEXT NOT NULL UNIQUE,
+		zip_name TEXT,
+		content_hash TEXT NOT NULL,
+		participants INTEGER NOT NULL,
+		headers_json TEXT NOT NULL,
+		rows_json TEXT NOT NULL,
+		updated_at DATETIME NOT NULL
+	);`
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create auction_payloads table: %v", err)
+	}
+}
+
+func TestAuctionPayloadServiceSaveAndGet(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionPayloadsTable(t, db)
+
+	service, err := NewAuctionPayloadService(db)
+	if err != nil {
+		t.Fatalf("NewAuctionPayloadService: %v", err)
+	}
+
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		ContentHash:  "hash-1",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology"},
+		Rows:         [][]string{{"Normandie", "Hydraulique"}},
+	}
+	if err := service.SavePayload(context.Background(), payload, "results.zip"); err != nil {
+		t.Fatalf("SavePayload: %v", err)
+	}
+
+	payload.ContentHash = "hash-2"
+	payload.Rows = append(payload.Rows, []string{"Bretagne", "Eolien"})
+	if err := service.SavePayload(context.Background(), payload, "results.zip"); err != nil {
+		t.Fatalf("SavePayload update: %v", err)
+	}
+
+	var count int64
+	if err := db.Table("auction_payloads").Count(&count).Error; err != nil {
+		t.Fatalf("count payloads: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("payloads = %d, want 1", count)
+	}
+
+	loaded, err := service.GetPayload(context.Background(), payload.SourceFile)
+	if err != nil {
+		t.Fatalf("GetPayload: %v", err)
+	}
+	if loaded.ContentHash != "hash-2" || loaded.Participants != 34 || len(loaded.Headers) != 2 || len(loaded.Rows) != 2 || loaded.Rows[1][0] != "Bretagne" {
+		t.Fatalf("loaded = %+v", loaded)
+	}
+
+	if _, err := service.GetPayload(context.Background(), "missing.xlsx"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Fatalf("missing err = %v, want ErrPayloadNotFound", err)
+	}
+	if err := service.SavePayload(context.Background(), AuctionPayload{}, ""); err == nil {
+		t.Fatalf("expected error for empty source file")
+	}
+}
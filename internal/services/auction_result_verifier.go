@@ -0,0 +1,165 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const verifyTolerance = 1e-9
+
+type QuarantinedRow struct {
+	Index     int
+	SourceRow int
+	Row       AuctionRow
+	Reason    string
+}
+
+type sourceRowCells struct {
+	text    map[string]bool
+	numbers map[int]*float64
+	claimed map[int]bool
+}
+
+func VerifyAuctionResults(payload AuctionPayload, result AuctionResults) (AuctionResults, []QuarantinedRow) {
+	columns := findAuctionColumns(payload.Headers)
+	claimed := map[int]bool{}
+	for _, column := range []int{columns.region, columns.technology, columns.totalVolumeAuctioned, columns.totalVolumeSold, columns.weightedAvgPriceEurPerMwh, columns.myTotalVolume, columns.myWeightedAvgPriceEurPerMwh, columns.numberOfWinners} {
+		if column >= 0 {
+			claimed[column] = true
+		}
+	}
+
+	sources := make([]sourceRowCells, len(payload.Rows))
+	for index, cells := range payload.Rows {
+		sources[index] = indexSourceRow(cells, claimed)
+	}
+
+	verified := result
+	verified.Rows = nil
+	var quarantined []QuarantinedRow
+	used := make([]bool, len(sources))
+	for index, row := range result.Rows {
+		sourceRow, reason := matchSourceRow(row, columns, sources, used)
+		if reason != "" {
+			quarantined = append(quarantined, QuarantinedRow{
+				Index:     index,
+				SourceRow: sourceRow,
+				Row:       row,
+				Reason:    reason,
+			})
+			continue
+		}
+		used[sourceRow] = true
+		verified.Rows = append(verified.Rows, row)
+	}
+
+	return verified, quarantined
+}
+
+func indexSourceRow(cells []string, claimed map[int]bool) sourceRowCells {
+	indexed := sourceRowCells{
+		text:    make(map[string]bool, len(cells)),
+		numbers: make(map[int]*float64, len(cells)),
+		claimed: claimed,
+	}
+	for index, cell := range cells {
+		indexed.text[normalizeCellText(cell)] = true
+
+		number, err := parseCellNumber(cell)
+		if err != nil {
+			continue
+		}
+		indexed.numbers[index] = number
+	}
+	return indexed
+}
+
+func matchSourceRow(row AuctionRow, columns auctionColumns, sources []sourceRowCells, used []bool) (int, string) {
+	region := normalizeCellText(row.Region)
+	technology := normalizeCellText(row.Technology)
+
+	firstCandidate := -1
+	var firstReason string
+	for index, source := range sources {
+		if !source.text[region] || !source.text[technology] {
+			continue
+		}
+		reason := verifyRowNumbers(row, columns, source)
+		if reason == "" && !used[index] {
+			return index, ""
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("duplicate of source row %d", index)
+		}
+		if firstCandidate == -1 {
+			firstCandidate = index
+			firstReason = reason
+		}
+	}
+
+	if firstCandidate == -1 {
+		return -1, fmt.Sprintf("no source row for region=%q technology=%q", row.Region, row.Technology)
+	}
+	return firstCandidate, firstReason
+}
+
+type verifiedField struct {
+	name   string
+	column int
+	value  *float64
+}
+
+func verifyRowNumbers(row AuctionRow, columns auctionColumns, source sourceRowCells) string {
+	fields := []verifiedField{
+		{name: "total_volume_auctioned", column: columns.totalVolumeAuctioned, value: &row.TotalVolumeAuctioned},
+		{name: "total_volume_sold", column: columns.totalVolumeSold, value: &row.TotalVolumeSold},
+		{name: "weighted_avg_price_eur_per_mwh", column: columns.weightedAvgPriceEurPerMwh, value: &row.WeightedAvgPriceEurPerMwh},
+		{name: "my_total_volume", column: columns.myTotalVolume, value: row.MyTotalVolume},
+		{name: "my_weighted_avg_price_eur_per_mwh", column: columns.myWeightedAvgPriceEurPerMwh, value: row.MyWeightedAvgPriceEurPerMwh},
+	}
+	if row.NumberOfWinners != 0 {
+		winners := float64(row.NumberOfWinners)
+		fields = append(fields, verifiedField{name: "number_of_winners", column: columns.numberOfWinners, value: &winners})
+	}
+
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		if !source.contains(field.column, *field.value) {
+			if field.column >= 0 {
+				return fmt.Sprintf("%s=%v not found in column %d of source row", field.name, *field.value, field.column)
+			}
+			return fmt.Sprintf("%s=%v not found in source row", field.name, *field.value)
+		}
+	}
+	return ""
+}
+
+func (s sourceRowCells) contains(column int, value float64) bool {
+	if column >= 0 {
+		number, ok := s.numbers[column]
+		return ok && numberMatches(number, value)
+	}
+	for index, number := range s.numbers {
+		if s.claimed[index] {
+			continue
+		}
+		if numberMatches(number, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func numberMatches(number *float64, value float64) bool {
+	if number == nil {
+		return value == 0
+	}
+	return math.Abs(*number-value) <= verifyTolerance*math.Max(1, math.Abs(*number))
+}
+
+func normalizeCellText(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
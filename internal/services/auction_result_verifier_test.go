@@ -0,0 +1,105 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyAuctionResults(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile: "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Headers:    []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+		Rows: [][]string{
+			{"Normandie", "Hydraulique", "1 200", "1 000,5", "0,35"},
+			{"Bretagne ", "Eolien", "500", "-", "0,20"},
+			{"Occitanie", "Solaire", "300", "300", "0,41"},
+		},
+	}
+	result := AuctionResults{
+		SourceFile: payload.SourceFile,
+		Rows: []AuctionRow{
+			{Region: "normandie", Technology: "Hydraulique", TotalVolumeAuctioned: 1200, TotalVolumeSold: 1000.5, WeightedAvgPriceEurPerMwh: 0.35},
+			{Region: "Bretagne", Technology: "Eolien", TotalVolumeAuctioned: 500, TotalVolumeSold: 0, WeightedAvgPriceEurPerMwh: 0.2},
+			{Region: "Occitanie", Technology: "Solaire", TotalVolumeAuctioned: 300, TotalVolumeSold: 300, WeightedAvgPriceEurPerMwh: 4.1},
+			{Region: "Normandie", Technology: "Hydraulique", TotalVolumeAuctioned: 1200, TotalVolumeSold: 1000.5, WeightedAvgPriceEurPerMwh: 0.35},
+			{Region: "Corse", Technology: "Solaire", TotalVolumeAuctioned: 300, TotalVolumeSold: 300, WeightedAvgPriceEurPerMwh: 0.41},
+		},
+	}
+
+	verified, quarantined := VerifyAuctionResults(payload, result)
+	if len(verified.Rows) != 2 {
+		t.Fatalf("verified rows = %d, want 2", len(verified.Rows))
+	}
+	if verified.SourceFile != payload.SourceFile {
+		t.Fatalf("source_file = %q, want %q", verified.SourceFile, payload.SourceFile)
+	}
+	if len(quarantined) != 3 {
+		t.Fatalf("quarantined = %d, want 3", len(quarantined))
+	}
+
+	expected := []struct {
+		index     int
+		sourceRow int
+		reason    string
+	}{
+		{index: 2, sourceRow: 2, reason: "weighted_avg_price_eur_per_mwh=4.1 not found"},
+		{index: 3, sourceRow: 0, reason: "duplicate of source row 0"},
+		{index: 4, sourceRow: -1, reason: "no source row"},
+	}
+	for i, want := range expected {
+		got := quarantined[i]
+		if got.Index != want.index || got.SourceRow != want.sourceRow || !strings.Contains(got.Reason, want.reason) {
+			t.Fatalf("quarantined[%d] = %+v, want index=%d source_row=%d reason containing %q", i, got, want.index, want.sourceRow, want.reason)
+		}
+	}
+}
+
+func TestVerifyAuctionResultsOptionalFields(t *testing.T) {
+	payload := AuctionPayload{
+		Rows: [][]string{{"Normandie", "Hydraulique", "10", "10", "0.3", "4", "7"}},
+	}
+	myVolume := 4.0
+	wrongPrice := 9.0
+	result := AuctionResults{Rows: []AuctionRow{
+		{Region: "Normandie", Technology: "Hydraulique", TotalVolumeAuctioned: 10, TotalVolumeSold: 10, WeightedAvgPriceEurPerMwh: 0.3, MyTotalVolume: &myVolume, NumberOfWinners: 7},
+	}}
+
+	verified, quarantined := VerifyAuctionResults(payload, result)
+	if len(verified.Rows) != 1 || len(quarantined) != 0 {
+		t.Fatalf("verified=%d quarantined=%v, want 1 verified", len(verified.Rows), quarantined)
+	}
+
+	result.Rows[0].MyWeightedAvgPriceEurPerMwh = &wrongPrice
+	verified, quarantined = VerifyAuctionResults(payload, result)
+	if len(verified.Rows) != 0 || len(quarantined) != 1 {
+		t.Fatalf("verified=%d quarantined=%d, want 1 quarantined", len(verified.Rows), len(quarantined))
+	}
+	if !strings.Contains(quarantined[0].Reason, "my_weighted_avg_price_eur_per_mwh=9") {
+		t.Fatalf("reason = %q, want my_weighted_avg_price_eur_per_mwh", quarantined[0].Reason)
+	}
+}
+
+func TestVerifyAuctionResultsMatchesHeaderColumns(t *testing.T) {
+	payload := AuctionPayload{
+		Headers: []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price", "Comment"},
+		Rows: [][]string{
+			{"Normandie", "Hydraulique", "1 200", "1 000", "0,35", ""},
+			{"Bretagne", "Eolien", "500", "-", "0,20", "12"},
+		},
+	}
+	result := AuctionResults{Rows: []AuctionRow{
+		{Region: "Normandie", Technology: "Hydraulique", TotalVolumeAuctioned: 1000, TotalVolumeSold: 1200, WeightedAvgPriceEurPerMwh: 0.35},
+		{Region: "Bretagne", Technology: "Eolien", TotalVolumeAuctioned: 500, TotalVolumeSold: 12, WeightedAvgPriceEurPerMwh: 0.2},
+		{Region: "Normandie", Technology: "Hydraulique", TotalVolumeAuctioned: 1200, TotalVolumeSold: 1000, WeightedAvgPriceEurPerMwh: 0},
+	}}
+
+	verified, quarantined := VerifyAuctionResults(payload, result)
+	if len(verified.Rows) != 0 || len(quarantined) != 3 {
+		t.Fatalf("verified=%d quarantined=%v, want all rows quarantined", len(verified.Rows), quarantined)
+	}
+	for i, want := range []string{"total_volume_auctioned=1000", "total_volume_sold=12", "weighted_avg_price_eur_per_mwh=0"} {
+		if !strings.Contains(quarantined[i].Reason, want) || !strings.Contains(quarantined[i].Reason, "not found in column") {
+			t.Fatalf("quarantined[%d] reason = %q, want %q", i, quarantined[i].Reason, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"solback/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrAuctionNotFound = errors.New("auction not found")
+var ErrInvalidAuctionID = errors.New("invalid auction id")
+
+type AuctionService struct {
+	db *gorm.DB
+}
+
+func NewAuctionService(db *gorm.DB) (*AuctionService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	return &AuctionService{db: db}, nil
+}
+
+func (s *AuctionService) GetAuctions(ctx context.Context) ([]models.Auction, error) {
+	if s == nil {
+		return nil, errors.New("auction service is nil")
+	}
+	if s.db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	var auctions []models.Auction
+	if err := s.db.WithContext(ctx).Order("year desc, month desc, auction_number desc").Find(&auctions).Error; err != nil {
+		return nil, fmt.Errorf("get auctions: %w", err)
+	}
+
+	return auctions, nil
+}
+
+func (s *AuctionService) GetAuction(ctx context.Context, id string) (models.Auction, error) {
+	if s == nil {
+		return models.Auction{}, errors.New("auction service is nil")
+	}
+	if s.db == nil {
+		return models.Auction{}, errors.New("db is nil")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return models.Auction{}, ErrInvalidAuctionID
+	}
+
+	var auction models.Auction
+	err := s.db.WithContext(ctx).
+		Preload("Results", func(db *gorm.DB) *gorm.DB {
+			return db.Order("region, technology")
+		}).
+		Where("id = ?", id).
+		First(&auction).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Auction{}, ErrAuctionNotFound
+	}
+	if err != nil {
+		return models.Auction{}, fmt.Errorf("get auction: %w", err)
+	}
+
+	return auction, nil
+}
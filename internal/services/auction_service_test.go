@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"solback/internal/models"
+)
+
+func TestAuctionServiceGetAuctions(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionsTable(t, db)
+	createAuctionResultsTable(t, db)
+
+	number := 83
+	auctions := []models.Auction{
+		{ID: "9f0c7a3e-8f7e-4b59-9b49-3f3b8c7f5a01", Year: 2025, Month: 2, Participants: 32, SourceFile: "feb.xlsx"},
+		{ID: "9f0c7a3e-8f7e-4b59-9b49-3f3b8c7f5a02", AuctionNumber: &number, Year: 2025, Month: 8, Participants: 34, SourceFile: "aug.xlsx"},
+	}
+	if err := db.Create(&auctions).Error; err != nil {
+		t.Fatalf("insert auctions: %v", err)
+	}
+
+	auctionID := auctions[1].ID
+	results := []models.AuctionResult{
+		{ID: "row-1", AuctionID: &auctionID, SourceFile: "aug.xlsx", Participants: 34, Year: 2025, Month: 8, Region: "B", Technology: "Solaire", WeightedAvgPriceEurPerMwh: float64Ptr(0.3)},
+		{ID: "row-2", AuctionID: &auctionID, SourceFile: "aug.xlsx", Participants: 34, Year: 2025, Month: 8, Region: "A", Technology: "Solaire", WeightedAvgPriceEurPerMwh: float64Ptr(0.3)},
+	}
+	if err := db.Create(&results).Error; err != nil {
+		t.Fatalf("insert results: %v", err)
+	}
+
+	service, err := NewAuctionService(db)
+	if err != nil {
+		t.Fatalf("NewAuctionService: %v", err)
+	}
+
+	list, err := service.GetAuctions(context.Background())
+	if err != nil {
+		t.Fatalf("GetAuctions: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("auctions = %d, want 2", len(list))
+	}
+	if list[0].SourceFile != "aug.xlsx" {
+		t.Fatalf("first auction = %q, want latest %q", list[0].SourceFile, "aug.xlsx")
+	}
+
+	auction, err := service.GetAuction(context.Background(), auctionID)
+	if err != nil {
+		t.Fatalf("GetAuction: %v", err)
+	}
+	if len(auction.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(auction.Results))
+	}
+	if auction.Results[0].Region != "A" {
+		t.Fatalf("first region = %q, want %q", auction.Results[0].Region, "A")
+	}
+}
+
+func TestAuctionServiceGetAuctionErrors(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionsTable(t, db)
+	createAuctionResultsTable(t, db)
+
+	service, err := NewAuctionService(db)
+	if err != nil {
+		t.Fatalf("NewAuctionService: %v", err)
+	}
+
+	if _, err := service.GetAuction(context.Background(), "not-a-uuid"); !errors.Is(err, ErrInvalidAuctionID) {
+		t.Fatalf("expected ErrInvalidAuctionID, got %v", err)
+	}
+	if _, err := service.GetAuction(context.Background(), "9f0c7a3e-8f7e-4b59-9b49-3f3b8c7f5a09"); !errors.Is(err, ErrAuctionNotFound) {
+		t.Fatalf("expected ErrAuctionNotFound, got %v", err)
+	}
+}
@@ -2,15 +2,28 @@ package services
 
 type AuctionPayload struct {
 	SourceFile   string
+	ContentHash  string
 	Participants int
 	Headers      []string
 	Rows         [][]string
+	Details      []AuctionDetailPayload
+}
+
+type AuctionDetailPayload struct {
+	SourceFile string
+	Sheet      string
+	Headers    []string
+	Rows       [][]string
 }
 
 type AuctionResults struct {
-	SourceFile   string       `json:"source_file"`
-	Participants int          `json:"participants"`
-	Rows         []AuctionRow `json:"rows"`
+	SourceFile    string           `json:"source_file"`
+	Participants  int              `json:"participants"`
+	Rows          []AuctionRow     `json:"rows"`
+	ZipName       string           `json:"-"`
+	Quarantined   []QuarantinedRow `json:"-"`
+	PromptName    string           `json:"-"`
+	PromptVersion string           `json:"-"`
 }
 
 type AuctionRow struct {
@@ -26,8 +39,32 @@ type AuctionRow struct {
 	NumberOfWinners             int      `json:"number_of_winners"`
 }
 
+type AuctionDetailResults struct {
+	SourceFile string             `json:"source_file"`
+	Sheet      string             `json:"sheet"`
+	Rows       []AuctionDetailRow `json:"rows"`
+	ZipName    string             `json:"-"`
+}
+
+type AuctionDetailRow struct {
+	SourceRow  int     `json:"source_row"`
+	Year       int     `json:"year"`
+	Month      int     `json:"month"`
+	Region     string  `json:"region"`
+	Technology string  `json:"technology"`
+	BidVolume  float64 `json:"bid_volume"`
+	BidPrice   float64 `json:"bid_price"`
+	Awarded    bool    `json:"awarded"`
+}
+
+type UpsertCounts struct {
+	Inserted int
+	Updated  int
+}
+
 type ZipResult struct {
-	URL        string
-	StatusCode int
-	Bytes      []byte
+	URL         string
+	StatusCode  int
+	Bytes       []byte
+	ContentHash string
 }
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type BatchPool struct {
+	slots chan struct{}
+}
+
+func NewBatchPool(concurrency int) (*BatchPool, error) {
+	if concurrency <= 0 {
+		return nil, errors.New("concurrency must be positive")
+	}
+
+	return &BatchPool{slots: make(chan struct{}, concurrency)}, nil
+}
+
+func (p *BatchPool) Run(ctx context.Context, count int, fn func(ctx context.Context, index int) error) []error {
+	errs := make([]error, count)
+	if p == nil || p.slots == nil {
+		for index := range errs {
+			errs[index] = errors.New("batch pool is nil")
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	for index := 0; index < count; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
+				errs[index] = ctx.Err()
+				return
+			}
+			defer func() { <-p.slots }()
+
+			if err := ctx.Err(); err != nil {
+				errs[index] = err
+				return
+			}
+			errs[index] = fn(ctx, index)
+		}(index)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+type RateLimiter struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func NewRateLimiter(requestsPerMinute int) (*RateLimiter, error) {
+	if requestsPerMinute < 0 {
+		return nil, errors.New("requests per minute must not be negative")
+	}
+	limiter := &RateLimiter{now: time.Now}
+	if requestsPerMinute > 0 {
+		limiter.interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	return limiter, nil
+}
+
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleepContext(ctx, slot.Sub(now))
+}
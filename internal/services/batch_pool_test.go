@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchPoolBoundsConcurrency(t *testing.T) {
+	pool, err := NewBatchPool(2)
+	if err != nil {
+		t.Fatalf("NewBatchPool: %v", err)
+	}
+
+	var running, peak int32
+	results := make([]int, 6)
+	errs := pool.Run(context.Background(), len(results), func(ctx context.Context, index int) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if index == 3 {
+			return errors.New("boom")
+		}
+		results[index] = index * 10
+		return nil
+	})
+
+	if peak > 2 {
+		t.Fatalf("peak concurrency = %d, want at most 2", peak)
+	}
+	for index, err := range errs {
+		if index == 3 {
+			if err == nil {
+				t.Fatalf("errs[3] = nil, want error")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("errs[%d] = %v, want nil", index, err)
+		}
+		if results[index] != index*10 {
+			t.Fatalf("results[%d] = %d, want %d", index, results[index], index*10)
+		}
+	}
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	limiter, err := NewRateLimiter(6000)
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("elapsed = %s, want at least 40ms for 5 requests at 6000 rpm", elapsed)
+	}
+
+	unlimited, err := NewRateLimiter(0)
+	if err != nil {
+		t.Fatalf("NewRateLimiter unlimited: %v", err)
+	}
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		if err := unlimited.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait unlimited %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("elapsed = %s, want no wait without a rate limit", elapsed)
+	}
+
+	if _, err := NewRateLimiter(-1); err == nil {
+		t.Fatalf("NewRateLimiter negative: expected error")
+	}
+}
+
+func TestBatchPoolCancelled(t *testing.T) {
+	pool, err := NewBatchPool(1)
+	if err != nil {
+		t.Fatalf("NewBatchPool: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	errs := pool.Run(ctx, 3, func(ctx context.Context, index int) error {
+		atomic.AddInt32(&calls, 1)
+		cancel()
+		return nil
+	})
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 before the context is cancelled", calls)
+	}
+	var cancelled int
+	for _, err := range errs {
+		if errors.Is(err, context.Canceled) {
+			cancelled++
+		}
+	}
+	if cancelled != 2 {
+		t.Fatalf("cancelled = %d, want 2", cancelled)
+	}
+
+	if _, err := NewBatchPool(0); err == nil {
+		t.Fatalf("NewBatchPool zero concurrency: expected error")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"solback/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var ErrInvalidPeriod = errors.New("invalid period")
@@ -38,7 +39,63 @@ func NewDataService(db *gorm.DB, logService LogWriter) (*DataService, error) {
 	}, nil
 }
 
-func (s *DataService) StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (int, error) {
+func (s *DataService) StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (UpsertCounts, error) {
+	if s == nil {
+		return UpsertCounts{}, errors.New("data service is nil")
+	}
+	if s.db == nil {
+		return UpsertCounts{}, errors.New("db is nil")
+	}
+	if s.logService == nil {
+		return UpsertCounts{}, errors.New("log service is nil")
+	}
+
+	records, err := buildAuctionResultRecords(results)
+	if err != nil {
+		return UpsertCounts{}, err
+	}
+
+	var counts UpsertCounts
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []struct {
+			Year       int
+			Month      int
+			Region     string
+			Technology string
+		}
+		if err := tx.Model(&models.AuctionResult{}).
+			Select("year, month, region, technology").
+			Where("source_file = ?", results.SourceFile).
+			Scan(&existing).Error; err != nil {
+			return fmt.Errorf("find existing rows: %w", err)
+		}
+		existingKeys := make(map[string]bool, len(existing))
+		for _, row := range existing {
+			existingKeys[auctionResultKey(row.Year, row.Month, row.Region, row.Technology)] = true
+		}
+		counts = UpsertCounts{}
+		for _, record := range records {
+			if existingKeys[auctionResultKey(record.Year, record.Month, record.Region, record.Technology)] {
+				counts.Updated++
+			} else {
+				counts.Inserted++
+			}
+		}
+
+		return createAuctionResultRecords(tx, results, records)
+	}); err != nil {
+		failMsg := fmt.Sprintf("store data rows=%d source_file=%s: %v", len(records), results.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionDataStore, LogOutcomeFail, &failMsg)
+		return UpsertCounts{}, fmt.Errorf("store auction results: %w", err)
+	}
+
+	successMsg := fmt.Sprintf("stored rows=%d inserted=%d updated=%d source_file=%s", len(records), counts.Inserted, counts.Updated, results.SourceFile)
+	_ = s.logService.CreateLog(ctx, eventID, LogActionDataStore, LogOutcomeSuccess, &successMsg)
+
+	return counts, nil
+}
+
+func (s *DataService) ReplaceAuctionResults(ctx context.Context, results AuctionResults, details []AuctionDetailResults, eventID *string) (int, error) {
 	if s == nil {
 		return 0, errors.New("data service is nil")
 	}
@@ -48,29 +105,84 @@ func (s *DataService) StoreAuctionResults(ctx context.Context, results AuctionRe
 	if s.logService == nil {
 		return 0, errors.New("log service is nil")
 	}
+
+	records, err := buildAuctionResultRecords(results)
+	if err != nil {
+		return 0, err
+	}
+
+	var removedRows int64
+	var removedDetails int64
+	var detailRows int
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Where("source_file = ?", results.SourceFile).Delete(&models.AuctionResult{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		removedRows = deleted.RowsAffected
+
+		deleted = tx.Where("source_file = ?", results.SourceFile).Delete(&models.AuctionDetail{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		removedDetails = deleted.RowsAffected
+
+		if err := createAuctionResultRecords(tx, results, records); err != nil {
+			return err
+		}
+
+		for _, detail := range details {
+			if len(detail.Rows) == 0 {
+				continue
+			}
+			if detail.SourceFile != results.SourceFile {
+				return fmt.Errorf("detail source file %s does not match %s", detail.SourceFile, results.SourceFile)
+			}
+			detailRecords := buildAuctionDetailRecords(detail)
+			if err := createAuctionDetailRecords(tx, detail, detailRecords); err != nil {
+				return fmt.Errorf("store details sheet=%s: %w", detail.Sheet, err)
+			}
+			detailRows += len(detailRecords)
+		}
+		return nil
+	}); err != nil {
+		failMsg := fmt.Sprintf("replace data rows=%d source_file=%s: %v", len(records), results.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionDataRevision, LogOutcomeFail, &failMsg)
+		return 0, fmt.Errorf("replace auction results: %w", err)
+	}
+
+	successMsg := fmt.Sprintf("replaced rows=%d removed_rows=%d detail_rows=%d removed_detail_rows=%d source_file=%s", len(records), removedRows, detailRows, removedDetails, results.SourceFile)
+	_ = s.logService.CreateLog(ctx, eventID, LogActionDataRevision, LogOutcomeSuccess, &successMsg)
+
+	return len(records), nil
+}
+
+func buildAuctionResultRecords(results AuctionResults) ([]models.AuctionResult, error) {
 	if results.SourceFile == "" {
-		return 0, errors.New("source file is empty")
+		return nil, errors.New("source file is empty")
 	}
 	if results.Participants <= 0 {
-		return 0, errors.New("participants must be positive")
+		return nil, errors.New("participants must be positive")
 	}
 	if len(results.Rows) == 0 {
-		return 0, errors.New("rows are empty")
+		return nil, errors.New("rows are empty")
 	}
 
 	records := make([]models.AuctionResult, 0, len(results.Rows))
+	positions := make(map[string]int, len(results.Rows))
 	for _, row := range results.Rows {
 		if math.Trunc(row.Year) != row.Year {
-			return 0, fmt.Errorf("year is not an integer: %v", row.Year)
+			return nil, fmt.Errorf("year is not an integer: %v", row.Year)
 		}
 		if math.Trunc(row.Month) != row.Month {
-			return 0, fmt.Errorf("month is not an integer: %v", row.Month)
+			return nil, fmt.Errorf("month is not an integer: %v", row.Month)
 		}
 
 		year := int(row.Year)
 		month := int(row.Month)
+		price := row.WeightedAvgPriceEurPerMwh
 
-		records = append(records, models.AuctionResult{
+		record := models.AuctionResult{
 			SourceFile:                  results.SourceFile,
 			Participants:                results.Participants,
 			Year:                        year,
@@ -79,26 +191,282 @@ func (s *DataService) StoreAuctionResults(ctx context.Context, results AuctionRe
 			Technology:                  row.Technology,
 			TotalVolumeAuctioned:        row.TotalVolumeAuctioned,
 			TotalVolumeSold:             row.TotalVolumeSold,
-			WeightedAvgPriceEurPerMwh:   row.WeightedAvgPriceEurPerMwh,
+			WeightedAvgPriceEurPerMwh:   &price,
 			MyTotalVolume:               row.MyTotalVolume,
 			MyWeightedAvgPriceEurPerMwh: row.MyWeightedAvgPriceEurPerMwh,
 			NumberOfWinners:             row.NumberOfWinners,
-		})
+		}
+		if results.PromptName != "" {
+			record.PromptName = &results.PromptName
+			record.PromptVersion = &results.PromptVersion
+		}
+
+		key := auctionResultKey(year, month, row.Region, row.Technology)
+		if position, ok := positions[key]; ok {
+			records[position] = record
+			continue
+		}
+		positions[key] = len(records)
+		records = append(records, record)
 	}
 
-	if err := s.db.WithContext(ctx).Create(&records).Error; err != nil {
-		failMsg := fmt.Sprintf("store data rows=%d source_file=%s: %v", len(records), results.SourceFile, err)
+	return records, nil
+}
+
+func buildAuctionDetailRecords(results AuctionDetailResults) []models.AuctionDetail {
+	records := make([]models.AuctionDetail, 0, len(results.Rows))
+	positions := make(map[int]int, len(results.Rows))
+	for _, row := range results.Rows {
+		record := models.AuctionDetail{
+			SourceFile: results.SourceFile,
+			Sheet:      results.Sheet,
+			SourceRow:  row.SourceRow,
+			Year:       row.Year,
+			Month:      row.Month,
+			Region:     row.Region,
+			Technology: row.Technology,
+			BidVolume:  row.BidVolume,
+			BidPrice:   row.BidPrice,
+			Awarded:    row.Awarded,
+		}
+
+		if position, ok := positions[row.SourceRow]; ok {
+			records[position] = record
+			continue
+		}
+		positions[row.SourceRow] = len(records)
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func createAuctionDetailRecords(tx *gorm.DB, results AuctionDetailResults, records []models.AuctionDetail) error {
+	auction, err := ensureAuction(tx, results.SourceFile, results.ZipName, 0, records[0].Year, records[0].Month)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		records[i].AuctionID = &auction.ID
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "source_file"}, {Name: "sheet"}, {Name: "source_row"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"auction_id",
+			"year",
+			"month",
+			"region",
+			"technology",
+			"bid_volume",
+			"bid_price",
+			"awarded",
+		}),
+	}).Create(&records).Error
+}
+
+func auctionResultKey(year int, month int, region string, technology string) string {
+	return fmt.Sprintf("%d|%d|%s|%s", year, month, region, technology)
+}
+
+func createAuctionResultRecords(tx *gorm.DB, results AuctionResults, records []models.AuctionResult) error {
+	auction, err := ensureAuction(tx, results.SourceFile, results.ZipName, results.Participants, records[0].Year, records[0].Month)
+	if err != nil {
+		return err
+	}
+	for i := range records {
+		records[i].AuctionID = &auction.ID
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "year"}, {Name: "month"}, {Name: "region"}, {Name: "technology"}, {Name: "source_file"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"auction_id",
+			"participants",
+			"total_volume_auctioned",
+			"total_volume_sold",
+			"weighted_avg_price_eur_per_mwh",
+			"my_total_volume",
+			"my_weighted_avg_price_eur_per_mwh",
+			"number_of_winners",
+			"prompt_name",
+			"prompt_version",
+		}),
+	}).Create(&records).Error
+}
+
+func (s *DataService) StoreAuctionDetails(ctx context.Context, results AuctionDetailResults, eventID *string) (int, error) {
+	if s == nil {
+		return 0, errors.New("data service is nil")
+	}
+	if s.db == nil {
+		return 0, errors.New("db is nil")
+	}
+	if s.logService == nil {
+		return 0, errors.New("log service is nil")
+	}
+	if results.SourceFile == "" {
+		return 0, errors.New("source file is empty")
+	}
+	if len(results.Rows) == 0 {
+		return 0, errors.New("rows are empty")
+	}
+
+	records := buildAuctionDetailRecords(results)
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createAuctionDetailRecords(tx, results, records)
+	}); err != nil {
+		failMsg := fmt.Sprintf("store detail rows=%d source_file=%s: %v", len(records), results.SourceFile, err)
 		_ = s.logService.CreateLog(ctx, eventID, LogActionDataStore, LogOutcomeFail, &failMsg)
-		return 0, fmt.Errorf("store auction results: %w", err)
+		return 0, fmt.Errorf("store auction details: %w", err)
 	}
 
-	successMsg := fmt.Sprintf("stored rows=%d source_file=%s", len(records), results.SourceFile)
+	successMsg := fmt.Sprintf("stored detail rows=%d source_file=%s", len(records), results.SourceFile)
 	_ = s.logService.CreateLog(ctx, eventID, LogActionDataStore, LogOutcomeSuccess, &successMsg)
 
 	return len(records), nil
 }
 
-func (s *DataService) GetData(ctx context.Context, period string, technology string, groupPeriod string, sumTech bool, from string, to string, techIn string, sort string, limit string) ([]models.AuctionResult, error) {
+func (s *DataService) BackfillAuctions(ctx context.Context) (int, error) {
+	if s == nil {
+		return 0, errors.New("data service is nil")
+	}
+	if s.db == nil {
+		return 0, errors.New("db is nil")
+	}
+
+	var orphans []struct {
+		SourceFile   string
+		Year         int
+		Month        int
+		Participants int
+	}
+	if err := s.db.WithContext(ctx).Model(&models.AuctionResult{}).
+		Select("source_file, MIN(year) AS year, MIN(month) AS month, MAX(participants) AS participants").
+		Where("auction_id IS NULL").
+		Group("source_file").
+		Scan(&orphans).Error; err != nil {
+		return 0, fmt.Errorf("find results without auction: %w", err)
+	}
+
+	for _, orphan := range orphans {
+		if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			auction, err := ensureAuction(tx, orphan.SourceFile, "", orphan.Participants, orphan.Year, orphan.Month)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&models.AuctionResult{}).Where("source_file = ? AND auction_id IS NULL", orphan.SourceFile).Update("auction_id", auction.ID).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.AuctionDetail{}).Where("source_file = ? AND auction_id IS NULL", orphan.SourceFile).Update("auction_id", auction.ID).Error
+		}); err != nil {
+			return 0, fmt.Errorf("backfill auction source_file=%s: %w", orphan.SourceFile, err)
+		}
+	}
+
+	return len(orphans), nil
+}
+
+func ensureAuction(tx *gorm.DB, sourceFile string, zipName string, participants int, year int, month int) (models.Auction, error) {
+	var auction models.Auction
+	err := tx.Where("source_file = ?", sourceFile).First(&auction).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Auction{}, fmt.Errorf("find auction: %w", err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		auction = models.Auction{
+			SourceFile:   sourceFile,
+			Year:         year,
+			Month:        month,
+			Participants: participants,
+		}
+		if info, err := parseAuctionFileInfo(sourceFile); err == nil {
+			auction.AuctionDate = info.AuctionDate
+			auction.AuctionNumber = info.AuctionNumber
+		}
+		if zipName != "" {
+			auction.ZipName = &zipName
+		}
+		if err := tx.Create(&auction).Error; err != nil {
+			return models.Auction{}, fmt.Errorf("create auction: %w", err)
+		}
+		return auction, nil
+	}
+
+	updates := map[string]interface{}{}
+	if participants > 0 && participants != auction.Participants {
+		updates["participants"] = participants
+		auction.Participants = participants
+	}
+	if zipName != "" && (auction.ZipName == nil || *auction.ZipName != zipName) {
+		updates["zip_name"] = zipName
+		auction.ZipName = &zipName
+	}
+	if len(updates) > 0 {
+		if err := tx.Model(&models.Auction{}).Where("id = ?", auction.ID).Updates(updates).Error; err != nil {
+			return models.Auction{}, fmt.Errorf("update auction: %w", err)
+		}
+	}
+
+	return auction, nil
+}
+
+func (s *DataService) GetDetails(ctx context.Context, sourceFile string, technology string, region string, from string, to string, limit string) ([]models.AuctionDetail, error) {
+	if s == nil {
+		return nil, errors.New("data service is nil")
+	}
+	if s.db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	limitValue, err := parseLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	fromYear, fromMonth, hasFrom, toYear, toMonth, hasTo, err := parseMonthRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.AuctionDetail{})
+
+	sourceFile = strings.TrimSpace(sourceFile)
+	if sourceFile != "" {
+		query = query.Where("source_file = ?", sourceFile)
+	}
+
+	technology = strings.TrimSpace(technology)
+	if technology != "" {
+		query = query.Where("lower(technology) = lower(?)", technology)
+	}
+
+	region = strings.TrimSpace(region)
+	if region != "" {
+		query = query.Where("lower(region) = lower(?)", region)
+	}
+
+	if hasFrom {
+		query = query.Where("(year > ?) OR (year = ? AND month >= ?)", fromYear, fromYear, fromMonth)
+	}
+	if hasTo {
+		query = query.Where("(year < ?) OR (year = ? AND month <= ?)", toYear, toYear, toMonth)
+	}
+
+	query = query.Order("year, month, region, technology, bid_price")
+	if limitValue > 0 {
+		query = query.Limit(limitValue)
+	}
+
+	var details []models.AuctionDetail
+	if err := query.Find(&details).Error; err != nil {
+		return nil, fmt.Errorf("get details: %w", err)
+	}
+
+	return details, nil
+}
+
+func (s *DataService) GetData(ctx context.Context, period string, technology string, region string, groupPeriod string, sumTech bool, sumRegion bool, from string, to string, techIn string, regionIn string, sort string, limit string) ([]models.AuctionResult, error) {
 	if s == nil {
 		return nil, errors.New("data service is nil")
 	}
@@ -110,7 +478,7 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 	if err != nil {
 		return nil, err
 	}
-	if groupPeriod == "" && sumTech {
+	if groupPeriod == "" && (sumTech || !sumRegion) {
 		groupPeriod = "month"
 	}
 
@@ -140,11 +508,21 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 		query = query.Where("lower(technology) = lower(?)", technology)
 	}
 
-	techList := parseTechIn(techIn)
+	techList := parseValueList(techIn)
 	if len(techList) > 0 {
 		query = query.Where("lower(technology) IN ?", techList)
 	}
 
+	region = strings.TrimSpace(region)
+	if region != "" {
+		query = query.Where("lower(region) = lower(?)", region)
+	}
+
+	regionList := parseValueList(regionIn)
+	if len(regionList) > 0 {
+		query = query.Where("lower(region) IN ?", regionList)
+	}
+
 	if hasFrom {
 		query = query.Where("(year > ?) OR (year = ? AND month >= ?)", fromYear, fromYear, fromMonth)
 	}
@@ -166,6 +544,11 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 			groupFields = append(groupFields, "month")
 			orderFields = append(orderFields, "month")
 		}
+		if !sumRegion {
+			selectFields = append(selectFields, "region")
+			groupFields = append(groupFields, "region")
+			orderFields = append(orderFields, "region")
+		}
 		if !sumTech {
 			selectFields = append(selectFields, "technology")
 			groupFields = append(groupFields, "technology")
@@ -176,7 +559,8 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 			selectFields,
 			"SUM(total_volume_auctioned) AS total_volume_auctioned",
 			"SUM(total_volume_sold) AS total_volume_sold",
-			"AVG(weighted_avg_price_eur_per_mwh) AS weighted_avg_price_eur_per_mwh",
+			"SUM(weighted_avg_price_eur_per_mwh * total_volume_sold) / NULLIF(SUM(total_volume_sold), 0) AS weighted_avg_price_eur_per_mwh",
+			"AVG(weighted_avg_price_eur_per_mwh) AS mean_price_eur_per_mwh",
 		)
 		query = query.Select(strings.Join(selectFields, ", ")).Group(strings.Join(groupFields, ", "))
 		if len(sortParts) > 0 {
@@ -184,6 +568,9 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 			if groupPeriod == "month" {
 				allowed["month"] = true
 			}
+			if !sumRegion {
+				allowed["region"] = true
+			}
 			if !sumTech {
 				allowed["technology"] = true
 			}
@@ -197,7 +584,7 @@ func (s *DataService) GetData(ctx context.Context, period string, technology str
 		}
 	} else {
 		if len(sortParts) > 0 {
-			allowed := map[string]bool{"year": true, "month": true, "technology": true}
+			allowed := map[string]bool{"year": true, "month": true, "region": true, "technology": true}
 			orderClause, err := buildOrderClause(sortParts, allowed)
 			if err != nil {
 				return nil, err
@@ -232,6 +619,7 @@ func (s *DataService) DeleteData(ctx context.Context) (int, error) {
 	}
 
 	var deletedResults int64
+	var deletedDetails int64
 	var deletedFiles int64
 	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		results := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.AuctionResult{})
@@ -240,6 +628,16 @@ func (s *DataService) DeleteData(ctx context.Context) (int, error) {
 		}
 		deletedResults = results.RowsAffected
 
+		details := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.AuctionDetail{})
+		if details.Error != nil {
+			return details.Error
+		}
+		deletedDetails = details.RowsAffected
+
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Auction{}).Error; err != nil {
+			return err
+		}
+
 		files := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.ProcessedFile{})
 		if files.Error != nil {
 			return files.Error
@@ -253,7 +651,7 @@ func (s *DataService) DeleteData(ctx context.Context) (int, error) {
 	}
 
 	count := int(deletedResults)
-	successMsg := fmt.Sprintf("deleted rows=%d processed_files=%d", deletedResults, deletedFiles)
+	successMsg := fmt.Sprintf("deleted rows=%d detail_rows=%d processed_files=%d", deletedResults, deletedDetails, deletedFiles)
 	_ = s.logService.CreateLog(ctx, nil, LogActionDataStore, LogOutcomeSuccess, &successMsg)
 
 	return count, nil
@@ -348,21 +746,21 @@ func parseYearMonth(value string) (int, int, error) {
 	return year, month, nil
 }
 
-func parseTechIn(value string) []string {
+func parseValueList(value string) []string {
 	if strings.TrimSpace(value) == "" {
 		return nil
 	}
 
 	parts := strings.Split(value, ",")
-	techList := make([]string, 0, len(parts))
+	values := make([]string, 0, len(parts))
 	for _, part := range parts {
 		trimmed := strings.ToLower(strings.TrimSpace(part))
 		if trimmed != "" {
-			techList = append(techList, trimmed)
+			values = append(values, trimmed)
 		}
 	}
 
-	return techList
+	return values
 }
 
 func parseSortParts(value string) ([]sortPart, error) {
@@ -389,7 +787,7 @@ func parseSortParts(value string) ([]sortPart, error) {
 		if field == "" || direction == "" {
 			return nil, ErrInvalidSort
 		}
-		if field != "year" && field != "month" && field != "technology" {
+		if field != "year" && field != "month" && field != "region" && field != "technology" {
 			return nil, ErrInvalidSort
 		}
 		if direction != "asc" && direction != "desc" {
@@ -17,6 +17,7 @@ func createAuctionResultsTable(t *testing.T, db *gorm.DB) {
 
 	query := `CREATE TABLE auction_results (
 		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		auction_id TEXT,
 		source_file TEXT NOT NULL,
 		participants INTEGER NOT NULL,
 		year INTEGER NOT NULL,
@@ -28,17 +29,63 @@ func createAuctionResultsTable(t *testing.T, db *gorm.DB) {
 		weighted_avg_price_eur_per_mwh REAL NOT NULL,
 		my_total_volume REAL,
 		my_weighted_avg_price_eur_per_mwh REAL,
-		number_of_winners INTEGER NOT NULL
+		number_of_winners INTEGER NOT NULL,
+		prompt_name TEXT,
+		prompt_version TEXT,
+		UNIQUE (year, month, region, technology, source_file)
 	);`
 	if err := db.Exec(query).Error; err != nil {
 		t.Fatalf("create auction_results table: %v", err)
 	}
 }
 
+func createAuctionsTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := `CREATE TABLE auctions (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		auction_number INTEGER,
+		auction_date DATE,
+		year INTEGER NOT NULL,
+		month INTEGER NOT NULL,
+		participants INTEGER NOT NULL,
+		source_file TEXT NOT NULL UNIQUE,
+		zip_name TEXT
+	);`
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create auctions table: %v", err)
+	}
+}
+
+func createAuctionDetailsTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := `CREATE TABLE auction_details (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+		auction_id TEXT,
+		source_file TEXT NOT NULL,
+		sheet TEXT NOT NULL,
+		source_row INTEGER NOT NULL,
+		year INTEGER NOT NULL,
+		month INTEGER NOT NULL,
+		region TEXT NOT NULL,
+		technology TEXT NOT NULL,
+		bid_volume REAL NOT NULL,
+		bid_price REAL NOT NULL,
+		awarded BOOLEAN NOT NULL DEFAULT false
+	);`
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create auction_details table: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX idx_auction_details_natural_key ON auction_details (source_file, sheet, source_row)").Error; err != nil {
+		t.Fatalf("create auction_details natural key: %v", err)
+	}
+}
+
 func createProcessedFilesTableForData(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	query := "CREATE TABLE processed_files (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), zip_filename TEXT NOT NULL UNIQUE, processed_at DATETIME NOT NULL)"
+	query := "CREATE TABLE processed_files (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), zip_filename TEXT NOT NULL, workbook_filename TEXT NOT NULL DEFAULT '', content_hash TEXT NOT NULL DEFAULT '', processed_at DATETIME NOT NULL, UNIQUE (zip_filename, workbook_filename))"
 	if err := db.Exec(query).Error; err != nil {
 		t.Fatalf("create processed_files table: %v", err)
 	}
@@ -46,6 +93,7 @@ func createProcessedFilesTableForData(t *testing.T, db *gorm.DB) {
 
 func TestDataServiceStoreAuctionResults(t *testing.T) {
 	db := openTestDB(t)
+	createAuctionsTable(t, db)
 	createAuctionResultsTable(t, db)
 
 	logWriter := &stubLogWriter{}
@@ -55,8 +103,9 @@ func TestDataServiceStoreAuctionResults(t *testing.T) {
 	}
 
 	results := AuctionResults{
-		SourceFile:   "file.xlsx",
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
 		Participants: 34,
+		ZipName:      "GO_2024_2025_GLOBAL_Results.zip",
 		Rows: []AuctionRow{
 			{
 				Year:                      2025,
@@ -69,14 +118,16 @@ func TestDataServiceStoreAuctionResults(t *testing.T) {
 				NumberOfWinners:           1,
 			},
 		},
+		PromptName:    PromptAuctionResults,
+		PromptVersion: "v1",
 	}
 
-	count, err := service.StoreAuctionResults(context.Background(), results, nil)
+	counts, err := service.StoreAuctionResults(context.Background(), results, nil)
 	if err != nil {
 		t.Fatalf("StoreAuctionResults: %v", err)
 	}
-	if count != 1 {
-		t.Fatalf("count = %d, want 1", count)
+	if counts.Inserted != 1 || counts.Updated != 0 {
+		t.Fatalf("counts = %+v, want 1 inserted", counts)
 	}
 
 	var stored []models.AuctionResult
@@ -86,12 +137,280 @@ func TestDataServiceStoreAuctionResults(t *testing.T) {
 	if len(stored) != 1 {
 		t.Fatalf("stored rows = %d, want 1", len(stored))
 	}
-	if stored[0].SourceFile != "file.xlsx" {
-		t.Fatalf("source_file = %q, want %q", stored[0].SourceFile, "file.xlsx")
+	if stored[0].SourceFile != results.SourceFile {
+		t.Fatalf("source_file = %q, want %q", stored[0].SourceFile, results.SourceFile)
+	}
+	if stored[0].PromptName == nil || *stored[0].PromptName != PromptAuctionResults || stored[0].PromptVersion == nil || *stored[0].PromptVersion != "v1" {
+		t.Fatalf("prompt = %v@%v, want %s@v1", stored[0].PromptName, stored[0].PromptVersion, PromptAuctionResults)
 	}
 	if len(logWriter.entries) == 0 {
 		t.Fatalf("expected log entries")
 	}
+
+	var auctions []models.Auction
+	if err := db.Find(&auctions).Error; err != nil {
+		t.Fatalf("select auctions: %v", err)
+	}
+	if len(auctions) != 1 {
+		t.Fatalf("auctions = %d, want 1", len(auctions))
+	}
+	auction := auctions[0]
+	if stored[0].AuctionID == nil || *stored[0].AuctionID != auction.ID {
+		t.Fatalf("auction_id = %v, want %q", stored[0].AuctionID, auction.ID)
+	}
+	if auction.AuctionNumber == nil || *auction.AuctionNumber != 83 {
+		t.Fatalf("auction_number = %v, want 83", auction.AuctionNumber)
+	}
+	if auction.AuctionDate == nil || auction.AuctionDate.Format("2006-01-02") != "2025-11-19" {
+		t.Fatalf("auction_date = %v, want 2025-11-19", auction.AuctionDate)
+	}
+	if auction.Year != 2025 || auction.Month != 8 || auction.Participants != 34 {
+		t.Fatalf("auction = %+v", auction)
+	}
+	if auction.ZipName == nil || *auction.ZipName != results.ZipName {
+		t.Fatalf("zip_name = %v, want %q", auction.ZipName, results.ZipName)
+	}
+
+	results.Rows[0].WeightedAvgPriceEurPerMwh = 0.35
+	results.Rows = append(results.Rows, AuctionRow{Year: 2025, Month: 8, Region: "Region2", Technology: "Tech1", TotalVolumeAuctioned: 2, TotalVolumeSold: 2, WeightedAvgPriceEurPerMwh: 0.4})
+	counts, err = service.StoreAuctionResults(context.Background(), results, nil)
+	if err != nil {
+		t.Fatalf("StoreAuctionResults second: %v", err)
+	}
+	if counts.Inserted != 1 || counts.Updated != 1 {
+		t.Fatalf("counts = %+v, want 1 inserted and 1 updated", counts)
+	}
+	lastMsg := logWriter.entries[len(logWriter.entries)-1].message
+	if lastMsg == nil || *lastMsg != "stored rows=2 inserted=1 updated=1 source_file="+results.SourceFile {
+		t.Fatalf("log message = %v", lastMsg)
+	}
+
+	stored = nil
+	if err := db.Order("region").Find(&stored).Error; err != nil {
+		t.Fatalf("select auction results after upsert: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("stored rows after upsert = %d, want 2", len(stored))
+	}
+	if stored[0].WeightedAvgPriceEurPerMwh == nil || *stored[0].WeightedAvgPriceEurPerMwh != 0.35 {
+		t.Fatalf("updated price = %v, want 0.35", stored[0].WeightedAvgPriceEurPerMwh)
+	}
+
+	var auctionCount int64
+	if err := db.Model(&models.Auction{}).Count(&auctionCount).Error; err != nil {
+		t.Fatalf("count auctions: %v", err)
+	}
+	if auctionCount != 1 {
+		t.Fatalf("auctions after second store = %d, want 1", auctionCount)
+	}
+}
+
+func TestDataServiceBackfillAuctions(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionsTable(t, db)
+	createAuctionResultsTable(t, db)
+	createAuctionDetailsTable(t, db)
+
+	rows := []models.AuctionResult{
+		{ID: "row-1", SourceFile: "20250520_February_2025_77_GLOBAL_Results_detailedresults.xlsx", Participants: 32, Year: 2025, Month: 2, Region: "R1", Technology: "Solaire", WeightedAvgPriceEurPerMwh: float64Ptr(0.3)},
+		{ID: "row-2", SourceFile: "20250520_February_2025_77_GLOBAL_Results_detailedresults.xlsx", Participants: 32, Year: 2025, Month: 2, Region: "R2", Technology: "Solaire", WeightedAvgPriceEurPerMwh: float64Ptr(0.3)},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+
+	service, err := NewDataService(db, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	count, err := service.BackfillAuctions(context.Background())
+	if err != nil {
+		t.Fatalf("BackfillAuctions: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("backfilled = %d, want 1", count)
+	}
+
+	var orphans int64
+	if err := db.Model(&models.AuctionResult{}).Where("auction_id IS NULL").Count(&orphans).Error; err != nil {
+		t.Fatalf("count orphans: %v", err)
+	}
+	if orphans != 0 {
+		t.Fatalf("orphans = %d, want 0", orphans)
+	}
+
+	var auction models.Auction
+	if err := db.First(&auction).Error; err != nil {
+		t.Fatalf("select auction: %v", err)
+	}
+	if auction.AuctionNumber == nil || *auction.AuctionNumber != 77 || auction.Participants != 32 {
+		t.Fatalf("auction = %+v", auction)
+	}
+}
+
+func TestDataServiceStoreAndGetAuctionDetails(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionsTable(t, db)
+	createAuctionDetailsTable(t, db)
+
+	logWriter := &stubLogWriter{}
+	service, err := NewDataService(db, logWriter)
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	results := AuctionDetailResults{
+		SourceFile: "file.xlsx",
+		Sheet:      "Detailed Results",
+		Rows: []AuctionDetailRow{
+			{SourceRow: 1, Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 100, BidPrice: 0.4, Awarded: true},
+			{SourceRow: 2, Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 50, BidPrice: 0.2, Awarded: false},
+			{SourceRow: 3, Year: 2025, Month: 8, Region: "Normandie", Technology: "Hydraulique", BidVolume: 10, BidPrice: 0.3, Awarded: true},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		count, err := service.StoreAuctionDetails(context.Background(), results, nil)
+		if err != nil {
+			t.Fatalf("StoreAuctionDetails %d: %v", i, err)
+		}
+		if count != 3 {
+			t.Fatalf("count = %d, want 3", count)
+		}
+	}
+	var stored int64
+	if err := db.Model(&models.AuctionDetail{}).Count(&stored).Error; err != nil {
+		t.Fatalf("count details: %v", err)
+	}
+	if stored != 3 {
+		t.Fatalf("stored details = %d, want 3 after storing twice", stored)
+	}
+	if len(logWriter.entries) == 0 {
+		t.Fatalf("expected log entries")
+	}
+
+	details, err := service.GetDetails(context.Background(), "file.xlsx", "solaire", "Bretagne", "2025-08", "2025-08", "")
+	if err != nil {
+		t.Fatalf("GetDetails: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("details = %d, want 2", len(details))
+	}
+	if details[0].BidPrice != 0.2 || details[0].Awarded {
+		t.Fatalf("first detail = %+v, want lowest bid not awarded", details[0])
+	}
+
+	if _, err := service.GetDetails(context.Background(), "", "", "", "2025-13", "", ""); !errors.Is(err, ErrInvalidMonthRange) {
+		t.Fatalf("expected ErrInvalidMonthRange, got %v", err)
+	}
+}
+
+func TestDataServiceReplaceAuctionResults(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionsTable(t, db)
+	createAuctionResultsTable(t, db)
+	createAuctionDetailsTable(t, db)
+
+	logWriter := &stubLogWriter{}
+	service, err := NewDataService(db, logWriter)
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	original := AuctionResults{
+		SourceFile:   "file.xlsx",
+		Participants: 10,
+		Rows: []AuctionRow{
+			{Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", TotalVolumeAuctioned: 10, TotalVolumeSold: 5, WeightedAvgPriceEurPerMwh: 0.4},
+			{Year: 2025, Month: 8, Region: "Normandie", Technology: "Solaire", TotalVolumeAuctioned: 10, TotalVolumeSold: 5, WeightedAvgPriceEurPerMwh: 0.5},
+		},
+	}
+	if _, err := service.StoreAuctionResults(context.Background(), original, nil); err != nil {
+		t.Fatalf("StoreAuctionResults: %v", err)
+	}
+	other := AuctionResults{
+		SourceFile:   "other.xlsx",
+		Participants: 10,
+		Rows:         []AuctionRow{{Year: 2025, Month: 9, Region: "Bretagne", Technology: "Solaire", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 0.1}},
+	}
+	if _, err := service.StoreAuctionResults(context.Background(), other, nil); err != nil {
+		t.Fatalf("StoreAuctionResults other: %v", err)
+	}
+	details := AuctionDetailResults{
+		SourceFile: "file.xlsx",
+		Rows:       []AuctionDetailRow{{Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 5, BidPrice: 0.4, Awarded: true}},
+	}
+	if _, err := service.StoreAuctionDetails(context.Background(), details, nil); err != nil {
+		t.Fatalf("StoreAuctionDetails: %v", err)
+	}
+
+	revised := AuctionResults{
+		SourceFile:   "file.xlsx",
+		Participants: 11,
+		Rows: []AuctionRow{
+			{Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", TotalVolumeAuctioned: 10, TotalVolumeSold: 6, WeightedAvgPriceEurPerMwh: 0.45},
+		},
+	}
+	mismatched := AuctionDetailResults{
+		SourceFile: "other.xlsx",
+		Rows:       []AuctionDetailRow{{SourceRow: 1, Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 6, BidPrice: 0.45}},
+	}
+	if _, err := service.ReplaceAuctionResults(context.Background(), revised, []AuctionDetailResults{mismatched}, nil); err == nil {
+		t.Fatalf("ReplaceAuctionResults with mismatched details: expected error")
+	}
+	var kept int64
+	if err := db.Model(&models.AuctionResult{}).Where("source_file = ?", "file.xlsx").Count(&kept).Error; err != nil {
+		t.Fatalf("count kept results: %v", err)
+	}
+	if kept != 2 {
+		t.Fatalf("kept rows = %d, want 2 after failed replace", kept)
+	}
+
+	revisedDetails := AuctionDetailResults{
+		SourceFile: "file.xlsx",
+		Sheet:      "Detailed Results",
+		Rows: []AuctionDetailRow{
+			{SourceRow: 1, Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 6, BidPrice: 0.45, Awarded: true},
+			{SourceRow: 2, Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solaire", BidVolume: 2, BidPrice: 0.6},
+		},
+	}
+	count, err := service.ReplaceAuctionResults(context.Background(), revised, []AuctionDetailResults{revisedDetails}, nil)
+	if err != nil {
+		t.Fatalf("ReplaceAuctionResults: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("count = %d, want 1", count)
+	}
+
+	var stored []models.AuctionResult
+	if err := db.Where("source_file = ?", "file.xlsx").Find(&stored).Error; err != nil {
+		t.Fatalf("find results: %v", err)
+	}
+	if len(stored) != 1 || stored[0].WeightedAvgPriceEurPerMwh == nil || *stored[0].WeightedAvgPriceEurPerMwh != 0.45 || stored[0].Participants != 11 {
+		t.Fatalf("stored = %+v, want single revised row", stored)
+	}
+
+	var otherCount int64
+	if err := db.Model(&models.AuctionResult{}).Where("source_file = ?", "other.xlsx").Count(&otherCount).Error; err != nil {
+		t.Fatalf("count other results: %v", err)
+	}
+	if otherCount != 1 {
+		t.Fatalf("other rows = %d, want 1", otherCount)
+	}
+
+	var detailCount int64
+	if err := db.Model(&models.AuctionDetail{}).Where("source_file = ?", "file.xlsx").Count(&detailCount).Error; err != nil {
+		t.Fatalf("count details: %v", err)
+	}
+	if detailCount != 2 {
+		t.Fatalf("detail rows = %d, want 2 revised details", detailCount)
+	}
+
+	last := logWriter.entries[len(logWriter.entries)-1]
+	if last.action != LogActionDataRevision || last.outcome != LogOutcomeSuccess {
+		t.Fatalf("last log = %s/%s, want %s/%s", last.action, last.outcome, LogActionDataRevision, LogOutcomeSuccess)
+	}
 }
 
 func TestDataServiceGetDataFilters(t *testing.T) {
@@ -109,7 +428,7 @@ func TestDataServiceGetDataFilters(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.3,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.3),
 			NumberOfWinners:           1,
 		},
 		{
@@ -122,7 +441,7 @@ func TestDataServiceGetDataFilters(t *testing.T) {
 			Technology:                "Wind",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 0.4,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.4),
 			NumberOfWinners:           1,
 		},
 	}
@@ -135,7 +454,7 @@ func TestDataServiceGetDataFilters(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "2024-2025", "Solar", "", false, "", "", "", "", "")
+	results, err := service.GetData(context.Background(), "2024-2025", "Solar", "", "", false, true, "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -156,7 +475,7 @@ func TestDataServiceGetDataInvalidPeriod(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	if _, err := service.GetData(context.Background(), "2024", "", "", false, "", "", "", "", ""); !errors.Is(err, ErrInvalidPeriod) {
+	if _, err := service.GetData(context.Background(), "2024", "", "", "", false, true, "", "", "", "", "", ""); !errors.Is(err, ErrInvalidPeriod) {
 		t.Fatalf("expected ErrInvalidPeriod, got %v", err)
 	}
 }
@@ -170,7 +489,7 @@ func TestDataServiceGetDataInvalidGroupPeriod(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	if _, err := service.GetData(context.Background(), "", "", "quarter", false, "", "", "", "", ""); !errors.Is(err, ErrInvalidGroupPeriod) {
+	if _, err := service.GetData(context.Background(), "", "", "", "quarter", false, true, "", "", "", "", "", ""); !errors.Is(err, ErrInvalidGroupPeriod) {
 		t.Fatalf("expected ErrInvalidGroupPeriod, got %v", err)
 	}
 }
@@ -184,10 +503,10 @@ func TestDataServiceGetDataInvalidMonthRange(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	if _, err := service.GetData(context.Background(), "", "", "", false, "2024-13", "", "", "", ""); !errors.Is(err, ErrInvalidMonthRange) {
+	if _, err := service.GetData(context.Background(), "", "", "", "", false, true, "2024-13", "", "", "", "", ""); !errors.Is(err, ErrInvalidMonthRange) {
 		t.Fatalf("expected ErrInvalidMonthRange, got %v", err)
 	}
-	if _, err := service.GetData(context.Background(), "", "", "", false, "2025-02", "2025-01", "", "", ""); !errors.Is(err, ErrInvalidMonthRange) {
+	if _, err := service.GetData(context.Background(), "", "", "", "", false, true, "2025-02", "2025-01", "", "", "", ""); !errors.Is(err, ErrInvalidMonthRange) {
 		t.Fatalf("expected ErrInvalidMonthRange, got %v", err)
 	}
 }
@@ -207,7 +526,7 @@ func TestDataServiceGetDataMonthRangeFilter(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.3,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.3),
 			NumberOfWinners:           1,
 		},
 		{
@@ -220,7 +539,7 @@ func TestDataServiceGetDataMonthRangeFilter(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 0.4,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.4),
 			NumberOfWinners:           1,
 		},
 		{
@@ -233,7 +552,7 @@ func TestDataServiceGetDataMonthRangeFilter(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      3,
 			TotalVolumeSold:           3,
-			WeightedAvgPriceEurPerMwh: 0.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.5),
 			NumberOfWinners:           1,
 		},
 	}
@@ -246,7 +565,7 @@ func TestDataServiceGetDataMonthRangeFilter(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "", false, "2024-05", "2024-12", "", "", "")
+	results, err := service.GetData(context.Background(), "", "", "", "", false, true, "2024-05", "2024-12", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -273,7 +592,7 @@ func TestDataServiceGetDataTechInFilter(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.3,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.3),
 			NumberOfWinners:           1,
 		},
 		{
@@ -286,7 +605,7 @@ func TestDataServiceGetDataTechInFilter(t *testing.T) {
 			Technology:                "Hydro",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 0.4,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.4),
 			NumberOfWinners:           1,
 		},
 		{
@@ -299,7 +618,7 @@ func TestDataServiceGetDataTechInFilter(t *testing.T) {
 			Technology:                "Wind",
 			TotalVolumeAuctioned:      3,
 			TotalVolumeSold:           3,
-			WeightedAvgPriceEurPerMwh: 0.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.5),
 			NumberOfWinners:           1,
 		},
 	}
@@ -312,7 +631,7 @@ func TestDataServiceGetDataTechInFilter(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "", false, "", "", "Solar, HYDRO", "", "")
+	results, err := service.GetData(context.Background(), "", "", "", "", false, true, "", "", "Solar, HYDRO", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -336,7 +655,7 @@ func TestDataServiceGetDataSortAndLimit(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.3,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.3),
 			NumberOfWinners:           1,
 		},
 		{
@@ -349,7 +668,7 @@ func TestDataServiceGetDataSortAndLimit(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 0.4,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.4),
 			NumberOfWinners:           1,
 		},
 	}
@@ -362,7 +681,7 @@ func TestDataServiceGetDataSortAndLimit(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "", false, "", "", "", "year_desc,month_desc", "1")
+	results, err := service.GetData(context.Background(), "", "", "", "", false, true, "", "", "", "", "year_desc,month_desc", "1")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -383,10 +702,10 @@ func TestDataServiceGetDataInvalidLimit(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	if _, err := service.GetData(context.Background(), "", "", "", false, "", "", "", "", "0"); !errors.Is(err, ErrInvalidLimit) {
+	if _, err := service.GetData(context.Background(), "", "", "", "", false, true, "", "", "", "", "", "0"); !errors.Is(err, ErrInvalidLimit) {
 		t.Fatalf("expected ErrInvalidLimit, got %v", err)
 	}
-	if _, err := service.GetData(context.Background(), "", "", "", false, "", "", "", "", "nope"); !errors.Is(err, ErrInvalidLimit) {
+	if _, err := service.GetData(context.Background(), "", "", "", "", false, true, "", "", "", "", "", "nope"); !errors.Is(err, ErrInvalidLimit) {
 		t.Fatalf("expected ErrInvalidLimit, got %v", err)
 	}
 }
@@ -400,9 +719,107 @@ func TestDataServiceGetDataInvalidSort(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	if _, err := service.GetData(context.Background(), "", "", "", false, "", "", "", "region_desc", ""); !errors.Is(err, ErrInvalidSort) {
+	if _, err := service.GetData(context.Background(), "", "", "", "", false, true, "", "", "", "", "price_desc", ""); !errors.Is(err, ErrInvalidSort) {
 		t.Fatalf("expected ErrInvalidSort, got %v", err)
 	}
+	if _, err := service.GetData(context.Background(), "", "", "", "year", false, true, "", "", "", "", "region_desc", ""); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected ErrInvalidSort for region with sum_region, got %v", err)
+	}
+}
+
+func TestDataServiceGetDataRegionFilters(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionResultsTable(t, db)
+
+	rows := []models.AuctionResult{
+		{ID: "row-1", SourceFile: "file.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "France", Technology: "Solar", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: float64Ptr(0.3), NumberOfWinners: 1},
+		{ID: "row-2", SourceFile: "file.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "Germany", Technology: "Solar", TotalVolumeAuctioned: 2, TotalVolumeSold: 2, WeightedAvgPriceEurPerMwh: float64Ptr(0.4), NumberOfWinners: 1},
+		{ID: "row-3", SourceFile: "file.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "Spain", Technology: "Wind", TotalVolumeAuctioned: 3, TotalVolumeSold: 3, WeightedAvgPriceEurPerMwh: float64Ptr(0.5), NumberOfWinners: 1},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+
+	service, err := NewDataService(db, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	results, err := service.GetData(context.Background(), "", "", "france", "", false, true, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("GetData region: %v", err)
+	}
+	if len(results) != 1 || results[0].Region != "France" {
+		t.Fatalf("results = %+v, want France only", results)
+	}
+
+	results, err = service.GetData(context.Background(), "", "", "", "", false, true, "", "", "", "Spain, GERMANY", "region_desc", "")
+	if err != nil {
+		t.Fatalf("GetData region_in: %v", err)
+	}
+	if len(results) != 2 || results[0].Region != "Spain" || results[1].Region != "Germany" {
+		t.Fatalf("results = %+v, want Spain then Germany", results)
+	}
+}
+
+func TestDataServiceGetDataGroupedByRegion(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionResultsTable(t, db)
+
+	rows := []models.AuctionResult{
+		{ID: "row-1", SourceFile: "jan.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "France", Technology: "Solar", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: float64Ptr(0.2), NumberOfWinners: 1},
+		{ID: "row-2", SourceFile: "feb.xlsx", Participants: 10, Year: 2024, Month: 2, Region: "France", Technology: "Solar", TotalVolumeAuctioned: 3, TotalVolumeSold: 3, WeightedAvgPriceEurPerMwh: float64Ptr(0.6), NumberOfWinners: 1},
+		{ID: "row-3", SourceFile: "jan.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "France", Technology: "Wind", TotalVolumeAuctioned: 2, TotalVolumeSold: 2, WeightedAvgPriceEurPerMwh: float64Ptr(0.4), NumberOfWinners: 1},
+		{ID: "row-4", SourceFile: "jan.xlsx", Participants: 10, Year: 2024, Month: 1, Region: "Spain", Technology: "Solar", TotalVolumeAuctioned: 5, TotalVolumeSold: 4, WeightedAvgPriceEurPerMwh: float64Ptr(0.5), NumberOfWinners: 1},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+
+	service, err := NewDataService(db, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	results, err := service.GetData(context.Background(), "", "", "", "year", false, false, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	first := results[0]
+	if first.Region != "France" || first.Technology != "Solar" {
+		t.Fatalf("first = %s/%s, want France/Solar", first.Region, first.Technology)
+	}
+	if first.TotalVolumeSold != 4 || first.WeightedAvgPriceEurPerMwh == nil || math.Abs(*first.WeightedAvgPriceEurPerMwh-0.5) > 1e-9 {
+		t.Fatalf("first totals = %v/%v, want 4/0.5", first.TotalVolumeSold, first.WeightedAvgPriceEurPerMwh)
+	}
+
+	results, err = service.GetData(context.Background(), "", "", "", "", true, false, "", "", "", "", "region_desc", "")
+	if err != nil {
+		t.Fatalf("GetData sum_tech: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	if results[0].Region != "Spain" || results[0].Technology != "" {
+		t.Fatalf("first = %s/%s, want Spain with technologies summed", results[0].Region, results[0].Technology)
+	}
+	if results[0].Month == 0 {
+		t.Fatalf("expected region grouping to default to month")
+	}
+
+	results, err = service.GetData(context.Background(), "", "", "", "month", false, true, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("GetData sum_region: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+	if results[0].Month != 1 || results[0].Region != "" || results[0].Technology != "Solar" || results[0].TotalVolumeSold != 5 {
+		t.Fatalf("first = %d/%s/%s/%v, want month 1 Solar with regions summed to 5", results[0].Month, results[0].Region, results[0].Technology, results[0].TotalVolumeSold)
+	}
 }
 
 func TestDataServiceGetDataGroupedByYear(t *testing.T) {
@@ -420,7 +837,7 @@ func TestDataServiceGetDataGroupedByYear(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.5),
 			NumberOfWinners:           1,
 		},
 		{
@@ -433,7 +850,7 @@ func TestDataServiceGetDataGroupedByYear(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 1.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(1.5),
 			NumberOfWinners:           1,
 		},
 		{
@@ -446,7 +863,7 @@ func TestDataServiceGetDataGroupedByYear(t *testing.T) {
 			Technology:                "Wind",
 			TotalVolumeAuctioned:      3,
 			TotalVolumeSold:           3,
-			WeightedAvgPriceEurPerMwh: 2.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(2.5),
 			NumberOfWinners:           1,
 		},
 	}
@@ -459,7 +876,7 @@ func TestDataServiceGetDataGroupedByYear(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "year", false, "", "", "", "", "")
+	results, err := service.GetData(context.Background(), "", "", "", "year", false, true, "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -484,13 +901,16 @@ func TestDataServiceGetDataGroupedByYear(t *testing.T) {
 	if solar.TotalVolumeAuctioned != 3 || solar.TotalVolumeSold != 3 {
 		t.Fatalf("solar totals = %v/%v, want 3/3", solar.TotalVolumeAuctioned, solar.TotalVolumeSold)
 	}
-	if math.Abs(solar.WeightedAvgPriceEurPerMwh-1.0) > 1e-9 {
-		t.Fatalf("solar avg price = %v, want 1.0", solar.WeightedAvgPriceEurPerMwh)
+	if solar.WeightedAvgPriceEurPerMwh == nil || math.Abs(*solar.WeightedAvgPriceEurPerMwh-3.5/3) > 1e-9 {
+		t.Fatalf("solar weighted price = %v, want %v", solar.WeightedAvgPriceEurPerMwh, 3.5/3)
+	}
+	if solar.MeanPriceEurPerMwh == nil || math.Abs(*solar.MeanPriceEurPerMwh-1.0) > 1e-9 {
+		t.Fatalf("solar mean price = %v, want 1.0", solar.MeanPriceEurPerMwh)
 	}
 	if wind.TotalVolumeAuctioned != 3 || wind.TotalVolumeSold != 3 {
 		t.Fatalf("wind totals = %v/%v, want 3/3", wind.TotalVolumeAuctioned, wind.TotalVolumeSold)
 	}
-	if math.Abs(wind.WeightedAvgPriceEurPerMwh-2.5) > 1e-9 {
+	if wind.WeightedAvgPriceEurPerMwh == nil || math.Abs(*wind.WeightedAvgPriceEurPerMwh-2.5) > 1e-9 {
 		t.Fatalf("wind avg price = %v, want 2.5", wind.WeightedAvgPriceEurPerMwh)
 	}
 }
@@ -510,7 +930,7 @@ func TestDataServiceGetDataGroupedByMonthSumTech(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      1,
 			TotalVolumeSold:           1,
-			WeightedAvgPriceEurPerMwh: 0.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.5),
 			NumberOfWinners:           1,
 		},
 		{
@@ -523,7 +943,7 @@ func TestDataServiceGetDataGroupedByMonthSumTech(t *testing.T) {
 			Technology:                "Wind",
 			TotalVolumeAuctioned:      3,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 1.5,
+			WeightedAvgPriceEurPerMwh: float64Ptr(1.5),
 			NumberOfWinners:           1,
 		},
 	}
@@ -536,7 +956,7 @@ func TestDataServiceGetDataGroupedByMonthSumTech(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "month", true, "", "", "", "", "")
+	results, err := service.GetData(context.Background(), "", "", "", "month", true, true, "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -550,8 +970,11 @@ func TestDataServiceGetDataGroupedByMonthSumTech(t *testing.T) {
 	if row.TotalVolumeAuctioned != 4 || row.TotalVolumeSold != 3 {
 		t.Fatalf("totals = %v/%v, want 4/3", row.TotalVolumeAuctioned, row.TotalVolumeSold)
 	}
-	if math.Abs(row.WeightedAvgPriceEurPerMwh-1.0) > 1e-9 {
-		t.Fatalf("avg price = %v, want 1.0", row.WeightedAvgPriceEurPerMwh)
+	if row.WeightedAvgPriceEurPerMwh == nil || math.Abs(*row.WeightedAvgPriceEurPerMwh-3.5/3) > 1e-9 {
+		t.Fatalf("weighted price = %v, want %v", row.WeightedAvgPriceEurPerMwh, 3.5/3)
+	}
+	if row.MeanPriceEurPerMwh == nil || math.Abs(*row.MeanPriceEurPerMwh-1.0) > 1e-9 {
+		t.Fatalf("mean price = %v, want 1.0", row.MeanPriceEurPerMwh)
 	}
 	if row.Technology != "" {
 		t.Fatalf("technology = %q, want empty", row.Technology)
@@ -573,7 +996,7 @@ func TestDataServiceGetDataSumTechDefaultsToMonth(t *testing.T) {
 			Technology:                "Solar",
 			TotalVolumeAuctioned:      2,
 			TotalVolumeSold:           2,
-			WeightedAvgPriceEurPerMwh: 0.8,
+			WeightedAvgPriceEurPerMwh: float64Ptr(0.8),
 			NumberOfWinners:           1,
 		},
 		{
@@ -586,7 +1009,7 @@ func TestDataServiceGetDataSumTechDefaultsToMonth(t *testing.T) {
 			Technology:                "Wind",
 			TotalVolumeAuctioned:      4,
 			TotalVolumeSold:           3,
-			WeightedAvgPriceEurPerMwh: 1.2,
+			WeightedAvgPriceEurPerMwh: float64Ptr(1.2),
 			NumberOfWinners:           1,
 		},
 	}
@@ -599,7 +1022,7 @@ func TestDataServiceGetDataSumTechDefaultsToMonth(t *testing.T) {
 		t.Fatalf("NewDataService: %v", err)
 	}
 
-	results, err := service.GetData(context.Background(), "", "", "", true, "", "", "", "", "")
+	results, err := service.GetData(context.Background(), "", "", "", "", true, true, "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetData: %v", err)
 	}
@@ -619,14 +1042,51 @@ func TestDataServiceGetDataSumTechDefaultsToMonth(t *testing.T) {
 	if row.TotalVolumeAuctioned != 6 || row.TotalVolumeSold != 5 {
 		t.Fatalf("totals = %v/%v, want 6/5", row.TotalVolumeAuctioned, row.TotalVolumeSold)
 	}
-	if math.Abs(row.WeightedAvgPriceEurPerMwh-1.0) > 1e-9 {
-		t.Fatalf("avg price = %v, want 1.0", row.WeightedAvgPriceEurPerMwh)
+	if row.WeightedAvgPriceEurPerMwh == nil || math.Abs(*row.WeightedAvgPriceEurPerMwh-1.04) > 1e-9 {
+		t.Fatalf("weighted price = %v, want 1.04", row.WeightedAvgPriceEurPerMwh)
+	}
+	if row.MeanPriceEurPerMwh == nil || math.Abs(*row.MeanPriceEurPerMwh-1.0) > 1e-9 {
+		t.Fatalf("mean price = %v, want 1.0", row.MeanPriceEurPerMwh)
+	}
+}
+
+func TestDataServiceGetDataGroupedZeroVolume(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionResultsTable(t, db)
+
+	rows := []models.AuctionResult{
+		{ID: "row-1", SourceFile: "file.xlsx", Participants: 10, Year: 2025, Month: 3, Region: "North", Technology: "Solar", TotalVolumeAuctioned: 2, WeightedAvgPriceEurPerMwh: float64Ptr(0.8)},
+		{ID: "row-2", SourceFile: "file.xlsx", Participants: 10, Year: 2025, Month: 3, Region: "South", Technology: "Solar", TotalVolumeAuctioned: 4, WeightedAvgPriceEurPerMwh: float64Ptr(1.2)},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("insert rows: %v", err)
+	}
+
+	service, err := NewDataService(db, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewDataService: %v", err)
+	}
+
+	results, err := service.GetData(context.Background(), "", "", "", "month", false, true, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("results = %d, want 1", len(results))
+	}
+	if results[0].WeightedAvgPriceEurPerMwh != nil {
+		t.Fatalf("weighted price = %v, want null for a group with no volume sold", *results[0].WeightedAvgPriceEurPerMwh)
+	}
+	if results[0].MeanPriceEurPerMwh == nil || math.Abs(*results[0].MeanPriceEurPerMwh-1.0) > 1e-9 {
+		t.Fatalf("mean price = %v, want 1.0", results[0].MeanPriceEurPerMwh)
 	}
 }
 
 func TestDataServiceDeleteData(t *testing.T) {
 	db := openTestDB(t)
+	createAuctionsTable(t, db)
 	createAuctionResultsTable(t, db)
+	createAuctionDetailsTable(t, db)
 	createProcessedFilesTableForData(t, db)
 
 	row := models.AuctionResult{
@@ -639,7 +1099,7 @@ func TestDataServiceDeleteData(t *testing.T) {
 		Technology:                "Solar",
 		TotalVolumeAuctioned:      1,
 		TotalVolumeSold:           1,
-		WeightedAvgPriceEurPerMwh: 0.3,
+		WeightedAvgPriceEurPerMwh: float64Ptr(0.3),
 		NumberOfWinners:           1,
 	}
 	if err := db.Create(&row).Error; err != nil {
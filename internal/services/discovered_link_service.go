@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DiscoveredLinkService struct {
+	db *gorm.DB
+}
+
+func NewDiscoveredLinkService(db *gorm.DB) (*DiscoveredLinkService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	return &DiscoveredLinkService{db: db}, nil
+}
+
+func (s *DiscoveredLinkService) RecordLinks(ctx context.Context, sourceURL string, candidates []OpenAiLinkCandidate) ([]models.DiscoveredLink, error) {
+	if s == nil {
+		return nil, errors.New("discovered link service is nil")
+	}
+	if s.db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if sourceURL == "" {
+		return nil, errors.New("source url is empty")
+	}
+
+	now := time.Now().UTC()
+	links := make([]models.DiscoveredLink, 0, len(candidates))
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, candidate := range candidates {
+			if candidate.Link == "" {
+				return errors.New("link is empty")
+			}
+
+			entry := models.DiscoveredLink{
+				SourceURL:   sourceURL,
+				Link:        candidate.Link,
+				Period:      candidate.Period,
+				Description: candidate.Description,
+				Evidence:    optionalString(candidate.Evidence),
+				FirstSeenAt: now,
+				LastSeenAt:  now,
+			}
+			if err := tx.Where("link = ?", candidate.Link).
+				Assign(models.DiscoveredLink{
+					SourceURL:   sourceURL,
+					Period:      candidate.Period,
+					Description: candidate.Description,
+					Evidence:    optionalString(candidate.Evidence),
+					LastSeenAt:  now,
+				}).
+				FirstOrCreate(&entry).Error; err != nil {
+				return fmt.Errorf("record link %s: %w", candidate.Link, err)
+			}
+			links = append(links, entry)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (s *DiscoveredLinkService) MarkIngested(ctx context.Context, link string) error {
+	if s == nil {
+		return errors.New("discovered link service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if link == "" {
+		return errors.New("link is empty")
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.DiscoveredLink{}).Where("link = ?", link).Update("ingested_at", time.Now().UTC()).Error; err != nil {
+		return fmt.Errorf("mark link ingested: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func createDiscoveredLinksTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := "CREATE TABLE discovered_links (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), source_url TEXT NOT NULL, link TEXT NOT NULL UNIQUE, period TEXT NOT NULL, description TEXT NOT NULL, evidence TEXT, first_seen_at DATETIME NOT NULL, last_seen_at DATETIME NOT NULL, ingested_at DATETIME)"
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create discovered_links table: %v", err)
+	}
+}
+
+func TestDiscoveredLinkServiceRecordAndMarkIngested(t *testing.T) {
+	db := openTestDB(t)
+	createDiscoveredLinksTable(t, db)
+
+	service, err := NewDiscoveredLinkService(db)
+	if err != nil {
+		t.Fatalf("NewDiscoveredLinkService: %v", err)
+	}
+
+	candidates := []OpenAiLinkCandidate{
+		{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/2025.zip", Evidence: "Download GO 2024-2025"},
+		{Period: "2023-2024", Description: "GO 2023-2024 results", Link: "https://example.com/2024.zip"},
+	}
+	links, err := service.RecordLinks(context.Background(), "https://example.com/page", candidates)
+	if err != nil {
+		t.Fatalf("RecordLinks: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("links = %d, want 2", len(links))
+	}
+	if links[0].Evidence == nil || *links[0].Evidence != "Download GO 2024-2025" || links[1].Evidence != nil {
+		t.Fatalf("evidence = %v, %v, want anchor text on the first link only", links[0].Evidence, links[1].Evidence)
+	}
+	if links[0].IngestedAt != nil {
+		t.Fatalf("expected new link not ingested")
+	}
+
+	if err := service.MarkIngested(context.Background(), "https://example.com/2024.zip"); err != nil {
+		t.Fatalf("MarkIngested: %v", err)
+	}
+
+	candidates[0].Description = "GO 2024-2025 results (updated)"
+	links, err = service.RecordLinks(context.Background(), "https://example.com/page", candidates)
+	if err != nil {
+		t.Fatalf("RecordLinks second: %v", err)
+	}
+	if links[0].Description != "GO 2024-2025 results (updated)" {
+		t.Fatalf("description = %q, want updated", links[0].Description)
+	}
+	if links[1].IngestedAt == nil {
+		t.Fatalf("expected ingested link to keep ingested_at")
+	}
+
+	var count int64
+	if err := db.Model(&models.DiscoveredLink{}).Count(&count).Error; err != nil {
+		t.Fatalf("count discovered links: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+const maxEgressRedirects = 10
+
+var ErrEgressBlocked = errors.New("egress blocked")
+
+type egressPolicyKey struct{}
+
+type EgressPolicy struct {
+	allowed []string
+}
+
+func NewEgressPolicy(sourceURL string, allowedHosts []string) (*EgressPolicy, error) {
+	policy := &EgressPolicy{}
+	for _, host := range allowedHosts {
+		normalized := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(host)), "*"), ".")
+		if normalized != "" {
+			policy.allowed = append(policy.allowed, normalized)
+		}
+	}
+	if len(policy.allowed) > 0 {
+		return policy, nil
+	}
+
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse source url: %w", err)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return nil, fmt.Errorf("source url %q has no host", sourceURL)
+	}
+	policy.allowed = []string{sourceDomain(host)}
+	return policy, nil
+}
+
+func (p *EgressPolicy) AllowedHosts() []string {
+	if p == nil {
+		return nil
+	}
+	return append([]string(nil), p.allowed...)
+}
+
+func (p *EgressPolicy) Check(rawURL string) error {
+	if p == nil {
+		return errors.New("egress policy is nil")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: parse url: %v", ErrEgressBlocked, err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return fmt.Errorf("%w: scheme %q is not allowed", ErrEgressBlocked, parsed.Scheme)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: url %q has no host", ErrEgressBlocked, rawURL)
+	}
+	for _, allowed := range p.allowed {
+		if host == allowed || (net.ParseIP(allowed) == nil && strings.HasSuffix(host, "."+allowed)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %s is not in allowlist %s", ErrEgressBlocked, host, strings.Join(p.allowed, ","))
+}
+
+func WithEgressPolicy(ctx context.Context, policy *EgressPolicy) context.Context {
+	return context.WithValue(ctx, egressPolicyKey{}, policy)
+}
+
+func egressPolicyFrom(ctx context.Context) *EgressPolicy {
+	policy, _ := ctx.Value(egressPolicyKey{}).(*EgressPolicy)
+	return policy
+}
+
+func egressClient(client *http.Client, policy *EgressPolicy) *http.Client {
+	if policy == nil {
+		return client
+	}
+
+	guarded := *client
+	next := client.CheckRedirect
+	guarded.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxEgressRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrEgressBlocked, len(via))
+		}
+		if via[len(via)-1].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: redirect downgrades to %s", ErrEgressBlocked, req.URL.Scheme)
+		}
+		if err := policy.Check(req.URL.String()); err != nil {
+			return fmt.Errorf("redirect to %s: %w", req.URL.Redacted(), err)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+	return &guarded
+}
+
+func sourceDomain(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"198.18.0.0/15",
+	"240.0.0.0/4",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func isBlockedIP(ip net.IP) bool {
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
+
+// Allowed addresses are host:port pairs exactly as dialed, e.g. the address of
+// a trusted local endpoint; everything else must resolve to public addresses.
+func publicOnlyDialer(dialer *net.Dialer, allowed []string) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if slices.Contains(allowed, address) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, fmt.Errorf("split address: %w", err)
+		}
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if isBlockedIP(addr.IP) {
+				return nil, fmt.Errorf("%w: %s resolves to non-public address %s", ErrEgressBlocked, host, addr.IP)
+			}
+		}
+
+		var lastErr error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no addresses for %s", host)
+		}
+		return nil, lastErr
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEgressPolicyCheck(t *testing.T) {
+	policy, err := NewEgressPolicy("https://www.eex.com/en/markets", nil)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	if hosts := policy.AllowedHosts(); len(hosts) != 1 || hosts[0] != "eex.com" {
+		t.Fatalf("allowed hosts = %v, want [eex.com]", hosts)
+	}
+
+	allowed := []string{
+		"https://www.eex.com/file.zip",
+		"https://download.EEX.com/results/file.zip",
+		"https://eex.com/file.zip",
+	}
+	for _, link := range allowed {
+		if err := policy.Check(link); err != nil {
+			t.Fatalf("Check(%s): %v", link, err)
+		}
+	}
+
+	blocked := []string{
+		"https://evil.com/file.zip",
+		"https://eex.com.evil.com/file.zip",
+		"https://notreex.com/file.zip",
+		"https://169.254.169.254/latest/meta-data.zip",
+		"file:///etc/passwd.zip",
+		"/relative.zip",
+	}
+	for _, link := range blocked {
+		if err := policy.Check(link); !errors.Is(err, ErrEgressBlocked) {
+			t.Fatalf("Check(%s) err = %v, want ErrEgressBlocked", link, err)
+		}
+	}
+
+	explicit, err := NewEgressPolicy("https://www.eex.com/en", []string{" *.cdn.example.net", "", "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("NewEgressPolicy explicit: %v", err)
+	}
+	if err := explicit.Check("https://files.cdn.example.net/a.zip"); err != nil {
+		t.Fatalf("Check cdn: %v", err)
+	}
+	if err := explicit.Check("https://127.0.0.1:8089/a.zip"); err != nil {
+		t.Fatalf("Check ip: %v", err)
+	}
+	if err := explicit.Check("https://www.eex.com/a.zip"); !errors.Is(err, ErrEgressBlocked) {
+		t.Fatalf("Check source host with explicit list err = %v, want ErrEgressBlocked", err)
+	}
+
+	if _, err := NewEgressPolicy("not a url", nil); err == nil {
+		t.Fatalf("expected error for source without host")
+	}
+}
+
+func TestEgressClientRechecksRedirects(t *testing.T) {
+	var target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same-host":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case "/other-host":
+			http.Redirect(w, r, target, http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	target = strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/final"
+
+	policy, err := NewEgressPolicy(server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	client := egressClient(server.Client(), policy)
+
+	resp, err := client.Get(server.URL + "/same-host")
+	if err != nil {
+		t.Fatalf("Get same host: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.Get(server.URL + "/other-host"); !errors.Is(err, ErrEgressBlocked) {
+		t.Fatalf("Get other host err = %v, want ErrEgressBlocked", err)
+	}
+	if _, err := client.Get(server.URL + "/loop"); !errors.Is(err, ErrEgressBlocked) {
+		t.Fatalf("Get loop err = %v, want ErrEgressBlocked", err)
+	}
+	if egressPolicyFrom(WithEgressPolicy(context.Background(), policy)) != policy {
+		t.Fatalf("expected policy from context")
+	}
+}
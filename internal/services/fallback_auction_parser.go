@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type FallbackAuctionParser struct {
+	primary    AuctionParser
+	fallback   AuctionParser
+	logService LogWriter
+}
+
+func NewFallbackAuctionParser(primary AuctionParser, fallback AuctionParser, logService LogWriter) (*FallbackAuctionParser, error) {
+	if primary == nil {
+		return nil, errors.New("primary parser is nil")
+	}
+	if fallback == nil {
+		return nil, errors.New("fallback parser is nil")
+	}
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &FallbackAuctionParser{
+		primary:    primary,
+		fallback:   fallback,
+		logService: logService,
+	}, nil
+}
+
+func (s *FallbackAuctionParser) ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error) {
+	if s == nil {
+		return AuctionResults{}, errors.New("fallback auction parser is nil")
+	}
+	if s.primary == nil {
+		return AuctionResults{}, errors.New("primary parser is nil")
+	}
+	if s.fallback == nil {
+		return AuctionResults{}, errors.New("fallback parser is nil")
+	}
+	if s.logService == nil {
+		return AuctionResults{}, errors.New("log service is nil")
+	}
+
+	result, err := s.primary.ParseAuctionResults(ctx, payload, eventID)
+	if err == nil {
+		return result, nil
+	}
+
+	msg := fmt.Sprintf("source_file=%s primary parser failed, falling back: %v", payload.SourceFile, err)
+	_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeFail, &msg)
+
+	return s.fallback.ParseAuctionResults(ctx, payload, eventID)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type countingAuctionParser struct {
+	result AuctionResults
+	err    error
+	calls  int
+}
+
+func (s *countingAuctionParser) ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error) {
+	s.calls++
+	return s.result, s.err
+}
+
+func TestFallbackAuctionParserUsesPrimary(t *testing.T) {
+	primary := &countingAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Rows: []AuctionRow{{Region: "R", Technology: "T"}}}}
+	fallback := &countingAuctionParser{}
+
+	parser, err := NewFallbackAuctionParser(primary, fallback, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewFallbackAuctionParser: %v", err)
+	}
+
+	result, err := parser.ParseAuctionResults(context.Background(), AuctionPayload{SourceFile: "file.xlsx"}, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(result.Rows))
+	}
+	if fallback.calls != 0 {
+		t.Fatalf("fallback calls = %d, want 0", fallback.calls)
+	}
+}
+
+func TestFallbackAuctionParserFallsBack(t *testing.T) {
+	primary := &countingAuctionParser{err: errors.New("unknown layout")}
+	fallback := &countingAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Rows: []AuctionRow{{Region: "R", Technology: "T"}}}}
+	logWriter := &stubLogWriter{}
+
+	parser, err := NewFallbackAuctionParser(primary, fallback, logWriter)
+	if err != nil {
+		t.Fatalf("NewFallbackAuctionParser: %v", err)
+	}
+
+	result, err := parser.ParseAuctionResults(context.Background(), AuctionPayload{SourceFile: "file.xlsx"}, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(result.Rows))
+	}
+	if fallback.calls != 1 {
+		t.Fatalf("fallback calls = %d, want 1", fallback.calls)
+	}
+	if len(logWriter.entries) != 1 || logWriter.entries[0].outcome != LogOutcomeFail {
+		t.Fatalf("expected one fail log entry, got %d", len(logWriter.entries))
+	}
+}
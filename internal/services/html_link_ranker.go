@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	zipLinkConfidentScore = 3
+	maxAnchorContextRunes = 300
+)
+
+var linkPeriodPattern = regexp.MustCompile(`(?:^|[^0-9])(\d{4})[-_ –](\d{4})(?:[^0-9]|$)`)
+
+type RankedZipLink struct {
+	Link        string
+	Period      string
+	Description string
+	Score       int
+}
+
+type HeuristicLinkExtractor struct {
+	fallback   OpenAiExtractor
+	logService LogWriter
+}
+
+func NewHeuristicLinkExtractor(fallback OpenAiExtractor, logService LogWriter) (*HeuristicLinkExtractor, error) {
+	if fallback == nil {
+		return nil, errors.New("fallback extractor is nil")
+	}
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &HeuristicLinkExtractor{
+		fallback:   fallback,
+		logService: logService,
+	}, nil
+}
+
+func (s *HeuristicLinkExtractor) ExtractZipLinks(ctx context.Context, rawHTML string, eventID *string) (OpenAiResult, error) {
+	if s == nil {
+		return OpenAiResult{}, errors.New("heuristic link extractor is nil")
+	}
+	if s.fallback == nil {
+		return OpenAiResult{}, errors.New("fallback extractor is nil")
+	}
+	if s.logService == nil {
+		return OpenAiResult{}, errors.New("log service is nil")
+	}
+
+	ranked, err := RankZipLinks(rawHTML)
+	if err != nil {
+		msg := fmt.Sprintf("method=llm reason=rank links: %v", err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionHeuristicHTMLExtract, LogOutcomeFail, &msg)
+		return s.fallback.ExtractZipLinks(ctx, rawHTML, eventID)
+	}
+
+	result, reason := confidentLinks(ranked)
+	if reason != "" {
+		msg := fmt.Sprintf("method=llm reason=%s candidates=%d", reason, len(ranked))
+		_ = s.logService.CreateLog(ctx, eventID, LogActionHeuristicHTMLExtract, LogOutcomeSuccess, &msg)
+		return s.fallback.ExtractZipLinks(ctx, rawHTML, eventID)
+	}
+
+	periods := make([]string, 0, len(result.Links))
+	for _, candidate := range result.Links {
+		periods = append(periods, candidate.Period)
+	}
+	msg := fmt.Sprintf("method=heuristic links=%d periods=%s candidates=%d", len(result.Links), strings.Join(periods, ","), len(ranked))
+	_ = s.logService.CreateLog(ctx, eventID, LogActionHeuristicHTMLExtract, LogOutcomeSuccess, &msg)
+
+	return result, nil
+}
+
+func confidentLinks(ranked []RankedZipLink) (OpenAiResult, string) {
+	if len(ranked) == 0 {
+		return OpenAiResult{}, "no zip links"
+	}
+
+	result := OpenAiResult{}
+	periods := map[string]bool{}
+	for _, candidate := range ranked {
+		if candidate.Score < zipLinkConfidentScore {
+			continue
+		}
+		if periods[candidate.Period] {
+			return OpenAiResult{}, fmt.Sprintf("duplicate period %s", candidate.Period)
+		}
+		periods[candidate.Period] = true
+		result.Links = append(result.Links, OpenAiLinkCandidate{
+			Period:      candidate.Period,
+			Description: candidate.Description,
+			Link:        candidate.Link,
+		})
+	}
+	if len(result.Links) == 0 {
+		return OpenAiResult{}, "no confident candidates"
+	}
+	if err := validateOpenAiResult(result); err != nil {
+		return OpenAiResult{}, fmt.Sprintf("invalid candidates: %v", err)
+	}
+	sortLinkCandidates(result.Links)
+
+	return result, ""
+}
+
+func RankZipLinks(rawHTML string) ([]RankedZipLink, error) {
+	if strings.TrimSpace(rawHTML) == "" {
+		return []RankedZipLink{}, nil
+	}
+
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	var ranked []RankedZipLink
+	seen := map[string]bool{}
+	var walkAnchors func(node *html.Node, row *html.Node)
+	walkAnchors = func(node *html.Node, row *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "tr" {
+			row = node
+		}
+		if node.Type == html.ElementNode && node.Data == "a" {
+			href := zipHref(node)
+			if href != "" && !seen[href] {
+				seen[href] = true
+				ranked = append(ranked, rankZipAnchor(node, row, href))
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walkAnchors(child, row)
+		}
+	}
+
+	var walkTables func(*html.Node)
+	walkTables = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "table" && tableHasZipLink(node) {
+			walkAnchors(node, nil)
+			return
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walkTables(child)
+		}
+	}
+	walkTables(doc)
+
+	return ranked, nil
+}
+
+func zipHref(node *html.Node) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, "href") && strings.Contains(strings.ToLower(attr.Val), ".zip") {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+func rankZipAnchor(anchor *html.Node, row *html.Node, href string) RankedZipLink {
+	anchorText := nodeText(anchor)
+	description := ""
+	if row != nil {
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") || containsNode(cell, anchor) {
+				continue
+			}
+			if text := nodeText(cell); len(text) > len(description) {
+				description = text
+			}
+		}
+	}
+	if description == "" {
+		description = anchorContext(anchor, anchorText)
+	}
+
+	filename := href
+	if parsed, err := url.Parse(href); err == nil {
+		filename = path.Base(parsed.Path)
+	}
+	filename = strings.NewReplacer("_", " ", "-", " ", ".", " ").Replace(filename)
+
+	text := strings.ToLower(strings.Join([]string{description, anchorText, filename}, " "))
+	ranked := RankedZipLink{Link: href, Description: description}
+	if hasGoMarker(text) {
+		ranked.Score++
+	}
+	if strings.Contains(text, "result") || strings.Contains(text, "résultat") || strings.Contains(text, "resultat") {
+		ranked.Score++
+	}
+	if period := findLinkPeriod(description + " " + path.Base(href)); period != "" {
+		ranked.Period = period
+		ranked.Score++
+	}
+
+	return ranked
+}
+
+func hasGoMarker(text string) bool {
+	for _, marker := range []string{"guarantee of origin", "guarantees of origin", "garanties d'origine", "garantie d'origine"} {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		if token == "go" {
+			return true
+		}
+	}
+	return false
+}
+
+func findLinkPeriod(text string) string {
+	for _, match := range linkPeriodPattern.FindAllStringSubmatch(text, -1) {
+		start, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		end, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		if start < 2000 || end <= start || end-start > 10 {
+			continue
+		}
+		period := fmt.Sprintf("%d-%d", start, end)
+		if periodPattern.MatchString(period) {
+			return period
+		}
+	}
+	return ""
+}
+
+func nodeText(node *html.Node) string {
+	var builder strings.Builder
+	var walk func(*html.Node)
+	walk = func(current *html.Node) {
+		if current.Type == html.TextNode {
+			builder.WriteString(current.Data)
+			builder.WriteString(" ")
+		}
+		for child := current.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(node)
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// anchorContext returns the text of the closest block around an anchor, such
+// as its table row, list item or paragraph, so a link is judged by the words
+// next to it and not only by its own label.
+func anchorContext(anchor *html.Node, anchorText string) string {
+	for node := anchor.Parent; node != nil; node = node.Parent {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		switch node.Data {
+		case "body", "html", "table", "tbody", "thead", "ul", "ol":
+			return anchorText
+		case "tr", "li", "p", "div", "dd", "dt", "section", "article", "figure", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6":
+			text := nodeText(node)
+			if text == anchorText {
+				continue
+			}
+			return trimAroundAnchor(text, anchorText)
+		}
+	}
+	return anchorText
+}
+
+func trimAroundAnchor(text string, anchorText string) string {
+	runes := []rune(text)
+	if len(runes) <= maxAnchorContextRunes {
+		return text
+	}
+	start := 0
+	if index := strings.Index(text, anchorText); index >= 0 {
+		start = len([]rune(text[:index])) - (maxAnchorContextRunes-len([]rune(anchorText)))/2
+	}
+	start = max(0, min(start, len(runes)-maxAnchorContextRunes))
+	return strings.TrimSpace(string(runes[start : start+maxAnchorContextRunes]))
+}
+
+func containsNode(parent *html.Node, target *html.Node) bool {
+	for node := target; node != nil; node = node.Parent {
+		if node == parent {
+			return true
+		}
+	}
+	return false
+}
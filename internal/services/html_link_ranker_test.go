@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type countingLinkExtractor struct {
+	calls  int
+	result OpenAiResult
+}
+
+func (s *countingLinkExtractor) ExtractZipLinks(ctx context.Context, html string, eventID *string) (OpenAiResult, error) {
+	s.calls++
+	return s.result, nil
+}
+
+func TestRankZipLinksFromExample(t *testing.T) {
+	path := filepath.Join("..", "..", "docs", "example.html")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read example.html: %v", err)
+	}
+
+	resolved, err := ResolveZipLinks("https://www.eex.com/en/markets/energy-certificates/french-auctions-power", string(data))
+	if err != nil {
+		t.Fatalf("ResolveZipLinks: %v", err)
+	}
+
+	ranked, err := RankZipLinks(resolved)
+	if err != nil {
+		t.Fatalf("RankZipLinks: %v", err)
+	}
+	if len(ranked) != 2 {
+		t.Fatalf("ranked = %d, want 2", len(ranked))
+	}
+
+	result, reason := confidentLinks(ranked)
+	if reason != "" {
+		t.Fatalf("expected confident result, got reason %q", reason)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("links = %d, want 2", len(result.Links))
+	}
+	if result.Links[0].Period != "2024-2025" || result.Links[0].Description != "GO 2024-2025 Global Results" {
+		t.Fatalf("first link = %+v", result.Links[0])
+	}
+	if result.Links[0].Link != "https://www.eex.com/fileadmin/EEX/Downloads/Registry_Services/French_Auctions_for_Guarantees_of_Origin/20251119_GO_2024_2025_GLOBAL_Results.zip" {
+		t.Fatalf("first link url = %q", result.Links[0].Link)
+	}
+	if result.Links[1].Period != "2019-2023" {
+		t.Fatalf("second period = %q, want 2019-2023", result.Links[1].Period)
+	}
+}
+
+func TestRankZipLinksScoresFilename(t *testing.T) {
+	html := `<table><tr><td><a href="https://example.com/GO_2023_2024_Results.zip">download</a></td></tr><tr><td>Brochure</td><td><a href="https://example.com/brochure.zip">zip</a></td></tr></table>`
+
+	ranked, err := RankZipLinks(html)
+	if err != nil {
+		t.Fatalf("RankZipLinks: %v", err)
+	}
+	if len(ranked) != 2 {
+		t.Fatalf("ranked = %d, want 2", len(ranked))
+	}
+	if ranked[0].Score != zipLinkConfidentScore || ranked[0].Period != "2023-2024" {
+		t.Fatalf("first = %+v, want confident 2023-2024", ranked[0])
+	}
+	if ranked[1].Score != 0 {
+		t.Fatalf("second score = %d, want 0", ranked[1].Score)
+	}
+}
+
+func TestHeuristicLinkExtractorSkipsLLMWhenConfident(t *testing.T) {
+	html := `<table><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/2025.zip">zip</a></td></tr></table>`
+
+	fallback := &countingLinkExtractor{}
+	logWriter := &stubLogWriter{}
+	extractor, err := NewHeuristicLinkExtractor(fallback, logWriter)
+	if err != nil {
+		t.Fatalf("NewHeuristicLinkExtractor: %v", err)
+	}
+
+	result, err := extractor.ExtractZipLinks(context.Background(), html, nil)
+	if err != nil {
+		t.Fatalf("ExtractZipLinks: %v", err)
+	}
+	if fallback.calls != 0 {
+		t.Fatalf("fallback calls = %d, want 0", fallback.calls)
+	}
+	if len(result.Links) != 1 || result.Links[0].Link != "https://example.com/2025.zip" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(logWriter.entries) != 1 || logWriter.entries[0].action != LogActionHeuristicHTMLExtract {
+		t.Fatalf("log entries = %+v, want one heuristic entry", logWriter.entries)
+	}
+}
+
+func TestHeuristicLinkExtractorFallsBackWhenAmbiguous(t *testing.T) {
+	html := `<table><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/a.zip">zip</a></td></tr><tr><td>GO 2024-2025 Global Results (corrected)</td><td><a href="https://example.com/b.zip">zip</a></td></tr></table>`
+
+	fallback := &countingLinkExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 Global Results (corrected)", Link: "https://example.com/b.zip"}}}}
+	logWriter := &stubLogWriter{}
+	extractor, err := NewHeuristicLinkExtractor(fallback, logWriter)
+	if err != nil {
+		t.Fatalf("NewHeuristicLinkExtractor: %v", err)
+	}
+
+	result, err := extractor.ExtractZipLinks(context.Background(), html, nil)
+	if err != nil {
+		t.Fatalf("ExtractZipLinks: %v", err)
+	}
+	if fallback.calls != 1 {
+		t.Fatalf("fallback calls = %d, want 1", fallback.calls)
+	}
+	if len(result.Links) != 1 || result.Links[0].Link != "https://example.com/b.zip" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(logWriter.entries) != 1 || logWriter.entries[0].message == nil || *logWriter.entries[0].message != "method=llm reason=duplicate period 2024-2025 candidates=2" {
+		t.Fatalf("log entries = %+v", logWriter.entries)
+	}
+}
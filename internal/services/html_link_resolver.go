@@ -9,6 +9,12 @@ import (
 	"golang.org/x/net/html"
 )
 
+type SourceAnchor struct {
+	Href    string
+	Text    string
+	Context string
+}
+
 func ResolveZipLinks(baseURL string, rawHTML string) (string, error) {
 	if strings.TrimSpace(rawHTML) == "" {
 		return "", errors.New("html is empty")
@@ -57,3 +63,85 @@ func ResolveZipLinks(baseURL string, rawHTML string) (string, error) {
 
 	return builder.String(), nil
 }
+
+func ExtractAnchors(baseURL string, rawHTML string) (map[string]SourceAnchor, error) {
+	if strings.TrimSpace(rawHTML) == "" {
+		return nil, errors.New("html is empty")
+	}
+	if baseURL == "" {
+		return nil, errors.New("base url is empty")
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base url: %w", err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		return nil, fmt.Errorf("parse html: %w", err)
+	}
+
+	anchors := map[string]SourceAnchor{}
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.ElementNode && node.Data == "a" {
+			var href, title string
+			for _, attr := range node.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "href":
+					href = attr.Val
+				case "title":
+					title = attr.Val
+				}
+			}
+			if parsed, err := url.Parse(strings.TrimSpace(href)); err == nil && href != "" {
+				resolved := base.ResolveReference(parsed).String()
+				key, err := normalizeLink(resolved)
+				if err == nil {
+					text := nodeText(node)
+					if text == "" {
+						text = strings.Join(strings.Fields(title), " ")
+					}
+					if existing, ok := anchors[key]; !ok || existing.Text == "" {
+						anchors[key] = SourceAnchor{Href: resolved, Text: text, Context: anchorContext(node, text)}
+					}
+				}
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	return anchors, nil
+}
+
+func normalizeLink(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("parse link: %w", err)
+	}
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return "", fmt.Errorf("link %q is not absolute", raw)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	if (parsed.Scheme == "https" && port == "443") || (parsed.Scheme == "http" && port == "80") {
+		port = ""
+	}
+	parsed.Host = host
+	if port != "" {
+		parsed.Host = host + ":" + port
+	}
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String(), nil
+}
@@ -24,3 +24,63 @@ func TestResolveZipLinks(t *testing.T) {
 		t.Fatalf("expected resolved zip links to be absolute")
 	}
 }
+
+func TestExtractAnchors(t *testing.T) {
+	rawHTML := `<html><body>
+<a href="/files/2025.zip">GO <b>2024-2025</b>
+  results</a>
+<a href="HTTPS://Example.COM:443/files/2024.zip#top" title="Season 2023-2024"></a>
+<a href="../other.zip">Other</a>
+<a>No href</a>
+</body></html>`
+
+	anchors, err := ExtractAnchors("https://example.com/en/page", rawHTML)
+	if err != nil {
+		t.Fatalf("ExtractAnchors: %v", err)
+	}
+
+	cases := map[string]string{
+		"https://example.com/files/2025.zip": "GO 2024-2025 results",
+		"https://example.com/files/2024.zip": "Season 2023-2024",
+		"https://example.com/other.zip":      "Other",
+	}
+	if len(anchors) != len(cases) {
+		t.Fatalf("anchors = %v, want %d entries", anchors, len(cases))
+	}
+	for link, text := range cases {
+		key, err := normalizeLink(link)
+		if err != nil {
+			t.Fatalf("normalizeLink(%s): %v", link, err)
+		}
+		if anchors[key].Text != text {
+			t.Fatalf("anchor %s text = %q, want %q", link, anchors[key].Text, text)
+		}
+	}
+
+	contextHTML := `<html><body><table><tr><td>Guarantees of Origin 2024-2025</td><td><a href="/go.zip">Download</a></td></tr></table>
+<div><p>Auction calendar: <a href="/calendar.zip">here</a> for all sessions.</p></div>
+<div><p><a href="/alone.zip">Alone</a></p></div></body></html>`
+	anchors, err = ExtractAnchors("https://example.com", contextHTML)
+	if err != nil {
+		t.Fatalf("ExtractAnchors context: %v", err)
+	}
+	contexts := map[string]string{
+		"https://example.com/go.zip":       "Guarantees of Origin 2024-2025 Download",
+		"https://example.com/calendar.zip": "Auction calendar: here for all sessions.",
+		"https://example.com/alone.zip":    "Alone",
+	}
+	for link, context := range contexts {
+		if anchors[link].Context != context {
+			t.Fatalf("anchor %s context = %q, want %q", link, anchors[link].Context, context)
+		}
+	}
+
+	long := strings.Repeat("lorem ", 100) + "GO 2024-2025" + strings.Repeat(" ipsum", 100)
+	if trimmed := trimAroundAnchor(long, "GO 2024-2025"); len([]rune(trimmed)) > maxAnchorContextRunes || !strings.Contains(trimmed, "GO 2024-2025") {
+		t.Fatalf("trimmed context = %q, want the anchor within %d runes", trimmed, maxAnchorContextRunes)
+	}
+
+	if _, err := ExtractAnchors("https://example.com", " "); err == nil {
+		t.Fatalf("expected error for empty html")
+	}
+}
@@ -2,59 +2,130 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+
+	"solback/internal/config"
 )
 
 type HtmlResult struct {
-	URL        string
-	StatusCode int
-	Body       string
+	URL          string
+	StatusCode   int
+	Body         string
+	ETag         string
+	LastModified string
+	ContentHash  string
+}
+
+type HtmlValidators struct {
+	ETag         string
+	LastModified string
+	ContentHash  string
 }
 
 type HtmlService struct {
-	client *http.Client
+	client   *http.Client
+	retrier  *Retrier
+	maxBytes int64
 }
 
-func NewHtmlService(client *http.Client) (*HtmlService, error) {
+func NewHtmlService(client *http.Client, retrier *Retrier, maxBytes int64) (*HtmlService, error) {
 	if client == nil {
 		client = http.DefaultClient
 	}
+	if retrier == nil {
+		retrier = singleAttemptRetrier()
+	}
+	if maxBytes < 0 {
+		return nil, errors.New("max html bytes must not be negative")
+	}
+	if maxBytes == 0 {
+		maxBytes = config.DefaultHTTPMaxHTMLBytes
+	}
 
-	return &HtmlService{client: client}, nil
+	return &HtmlService{client: client, retrier: retrier, maxBytes: maxBytes}, nil
 }
 
-func (s *HtmlService) Fetch(ctx context.Context, url string) (HtmlResult, error) {
+func (s *HtmlService) Fetch(ctx context.Context, url string, validators HtmlValidators, eventID *string) (HtmlResult, error) {
 	if s == nil {
 		return HtmlResult{}, errors.New("html service is nil")
 	}
 	if s.client == nil {
 		return HtmlResult{}, errors.New("http client is nil")
 	}
+	if s.retrier == nil {
+		return HtmlResult{}, errors.New("retrier is nil")
+	}
 	if url == "" {
 		return HtmlResult{}, errors.New("url is empty")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return HtmlResult{URL: url}, fmt.Errorf("build request: %w", err)
+	client := s.client
+	if policy := egressPolicyFrom(ctx); policy != nil {
+		if err := policy.Check(url); err != nil {
+			return HtmlResult{URL: url}, err
+		}
+		client = egressClient(s.client, policy)
 	}
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return HtmlResult{URL: url}, fmt.Errorf("do request: %w", err)
-	}
+	result := HtmlResult{URL: url}
+	err := s.retrier.Do(ctx, upstreamHost(url), LogActionDataRetrieval, eventID, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("build request: %w", err)
+		}
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
 
-	body, readErr := io.ReadAll(resp.Body)
-	closeErr := resp.Body.Close()
-	if readErr != nil {
-		return HtmlResult{URL: url, StatusCode: resp.StatusCode}, fmt.Errorf("read response: %w", readErr)
-	}
-	if closeErr != nil {
-		return HtmlResult{URL: url, StatusCode: resp.StatusCode, Body: string(body)}, fmt.Errorf("close response: %w", closeErr)
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("do request: %w", err)
+		}
+
+		body, readErr := readLimitedBody(resp, s.maxBytes)
+		closeErr := resp.Body.Close()
+		result = HtmlResult{
+			URL:          url,
+			StatusCode:   resp.StatusCode,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if readErr != nil {
+			return fmt.Errorf("read html response: %w", readErr)
+		}
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			if err := checkHTMLContent(resp.Header.Get("Content-Type"), body); err != nil {
+				return fmt.Errorf("html response: %w", err)
+			}
+		}
+		result.Body = string(body)
+		if resp.StatusCode == http.StatusNotModified {
+			result.ContentHash = validators.ContentHash
+		} else {
+			hash := sha256.Sum256(body)
+			result.ContentHash = hex.EncodeToString(hash[:])
+		}
+		if closeErr != nil {
+			return fmt.Errorf("close response: %w", closeErr)
+		}
+
+		if isRetryableStatus(resp.StatusCode) {
+			return newHTTPStatusError("html", resp, nil)
+		}
+		return nil
+	})
+
+	var statusErr *HTTPStatusError
+	if err != nil && !(errors.As(err, &statusErr) && result.StatusCode == statusErr.StatusCode) {
+		return result, err
 	}
 
-	return HtmlResult{URL: url, StatusCode: resp.StatusCode, Body: string(body)}, nil
+	return result, nil
 }
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -12,17 +14,18 @@ func TestHtmlServiceFetchSuccess(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		hits++
+		w.Header().Set("Content-Type", "text/html")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	}))
 	defer server.Close()
 
-	service, err := NewHtmlService(server.Client())
+	service, err := NewHtmlService(server.Client(), nil, 0)
 	if err != nil {
 		t.Fatalf("NewHtmlService: %v", err)
 	}
 
-	result, err := service.Fetch(context.Background(), server.URL)
+	result, err := service.Fetch(context.Background(), server.URL, HtmlValidators{}, nil)
 	if err != nil {
 		t.Fatalf("Fetch: %v", err)
 	}
@@ -44,12 +47,12 @@ func TestHtmlServiceFetchNonOK(t *testing.T) {
 	}))
 	defer server.Close()
 
-	service, err := NewHtmlService(server.Client())
+	service, err := NewHtmlService(server.Client(), nil, 0)
 	if err != nil {
 		t.Fatalf("NewHtmlService: %v", err)
 	}
 
-	result, err := service.Fetch(context.Background(), server.URL)
+	result, err := service.Fetch(context.Background(), server.URL, HtmlValidators{}, nil)
 	if err != nil {
 		t.Fatalf("Fetch: %v", err)
 	}
@@ -62,12 +65,115 @@ func TestHtmlServiceFetchNonOK(t *testing.T) {
 }
 
 func TestHtmlServiceFetchEmptyURL(t *testing.T) {
-	service, err := NewHtmlService(http.DefaultClient)
+	service, err := NewHtmlService(http.DefaultClient, nil, 0)
 	if err != nil {
 		t.Fatalf("NewHtmlService: %v", err)
 	}
 
-	if _, err := service.Fetch(context.Background(), ""); err == nil {
+	if _, err := service.Fetch(context.Background(), "", HtmlValidators{}, nil); err == nil {
 		t.Fatalf("Fetch empty url: expected error")
 	}
 }
+
+func TestHtmlServiceFetchRetriesServerErrors(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	retrier, _ := newTestRetrier(t, 3, nil, &stubLogWriter{})
+	service, err := NewHtmlService(server.Client(), retrier, 0)
+	if err != nil {
+		t.Fatalf("NewHtmlService: %v", err)
+	}
+
+	result, err := service.Fetch(context.Background(), server.URL, HtmlValidators{}, nil)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if hits != 3 {
+		t.Fatalf("hits = %d, want 3", hits)
+	}
+	if result.StatusCode != http.StatusOK || result.Body != "ok" {
+		t.Fatalf("result = %+v, want 200 ok", result)
+	}
+
+	hits = -10
+	result, err = service.Fetch(context.Background(), server.URL, HtmlValidators{}, nil)
+	if err != nil {
+		t.Fatalf("Fetch exhausted: %v", err)
+	}
+	if result.StatusCode != http.StatusBadGateway {
+		t.Fatalf("StatusCode = %d, want %d after retries are exhausted", result.StatusCode, http.StatusBadGateway)
+	}
+}
+
+func TestHtmlServiceFetchConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Wed, 19 Nov 2025 10:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Wed, 19 Nov 2025 10:00:00 GMT")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("page"))
+	}))
+	defer server.Close()
+
+	service, err := NewHtmlService(server.Client(), nil, 0)
+	if err != nil {
+		t.Fatalf("NewHtmlService: %v", err)
+	}
+
+	first, err := service.Fetch(context.Background(), server.URL, HtmlValidators{}, nil)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	wantHash := "3660315a9af3df255d8f19ab077e4797822b41488a0e2a04bc6af71213c23274"
+	if first.StatusCode != http.StatusOK || first.ETag != `"v1"` || first.LastModified == "" || first.ContentHash != wantHash {
+		t.Fatalf("first = %+v", first)
+	}
+
+	second, err := service.Fetch(context.Background(), server.URL, HtmlValidators{ETag: first.ETag, LastModified: first.LastModified, ContentHash: first.ContentHash}, nil)
+	if err != nil {
+		t.Fatalf("Fetch conditional: %v", err)
+	}
+	if second.StatusCode != http.StatusNotModified || second.Body != "" {
+		t.Fatalf("second = %+v, want 304 without body", second)
+	}
+	if second.ContentHash != first.ContentHash {
+		t.Fatalf("ContentHash = %q, want stored hash %q", second.ContentHash, first.ContentHash)
+	}
+}
+
+func TestHtmlServiceFetchRejectsOversizedAndBinaryBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large" {
+			_, _ = w.Write([]byte("<html>" + strings.Repeat("x", 2048) + "</html>"))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("PK\x03\x04\x14\x00\x00\x00"))
+	}))
+	defer server.Close()
+
+	service, err := NewHtmlService(server.Client(), nil, 1024)
+	if err != nil {
+		t.Fatalf("NewHtmlService: %v", err)
+	}
+
+	if _, err := service.Fetch(context.Background(), server.URL+"/large", HtmlValidators{}, nil); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("large err = %v, want ErrBodyTooLarge", err)
+	}
+	if _, err := service.Fetch(context.Background(), server.URL+"/binary", HtmlValidators{}, nil); !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("binary err = %v, want ErrUnexpectedContentType", err)
+	}
+}
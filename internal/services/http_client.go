@@ -0,0 +1,113 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+type HTTPClientOptions struct {
+	ConnectTimeout      time.Duration
+	TLSHandshakeTimeout time.Duration
+	Timeout             time.Duration
+	IdleConnTimeout     time.Duration
+	MaxIdleConns        int
+	BlockPrivate        bool
+	AllowedAddrs        []string
+	UserAgent           string
+	ProxyURL            string
+	CABundle            string
+	RootCAs             *x509.CertPool
+}
+
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func NewHTTPClient(options HTTPClientOptions) (*http.Client, error) {
+	if options.ConnectTimeout < 0 || options.TLSHandshakeTimeout < 0 || options.Timeout < 0 || options.IdleConnTimeout < 0 {
+		return nil, errors.New("http timeouts must not be negative")
+	}
+	if options.MaxIdleConns < 0 {
+		return nil, errors.New("max idle connections must not be negative")
+	}
+
+	// A proxy resolves the target itself, so the dialer would only ever see the
+	// proxy address. Private networks are therefore blocked on direct
+	// connections only, and environment proxies are ignored.
+	proxy := http.ProxyFromEnvironment
+	if options.BlockPrivate {
+		if options.ProxyURL != "" {
+			return nil, errors.New("proxy url cannot be combined with blocking private networks")
+		}
+		proxy = nil
+	}
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url: %w", err)
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy url %q must be an absolute http or https url", options.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	rootCAs := options.RootCAs
+	if options.CABundle != "" {
+		pem, err := os.ReadFile(options.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("read ca bundle: %w", err)
+		}
+		if rootCAs == nil {
+			rootCAs, err = x509.SystemCertPool()
+			if err != nil {
+				rootCAs = x509.NewCertPool()
+			}
+		}
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca bundle %s has no certificates", options.CABundle)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: options.ConnectTimeout, KeepAlive: 30 * time.Second}
+	dialContext := dialer.DialContext
+	if options.BlockPrivate {
+		dialContext = publicOnlyDialer(dialer, options.AllowedAddrs)
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialContext,
+		TLSHandshakeTimeout:   options.TLSHandshakeTimeout,
+		TLSClientConfig:       &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+		MaxIdleConns:          options.MaxIdleConns,
+		MaxIdleConnsPerHost:   options.MaxIdleConns,
+		IdleConnTimeout:       options.IdleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if options.UserAgent != "" {
+		roundTripper = &userAgentTransport{base: transport, userAgent: options.UserAgent}
+	}
+
+	return &http.Client{Transport: roundTripper, Timeout: options.Timeout}, nil
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(clone)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientUserAgentAndTimeout(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(HTTPClientOptions{
+		ConnectTimeout: time.Second,
+		Timeout:        50 * time.Millisecond,
+		MaxIdleConns:   2,
+		UserAgent:      "solback-test",
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("User-Agent", "custom")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(userAgents) != 2 || userAgents[0] != "solback-test" || userAgents[1] != "custom" {
+		t.Fatalf("user agents = %v, want configured then explicit", userAgents)
+	}
+
+	if _, err := client.Get(server.URL + "/slow"); err == nil {
+		t.Fatalf("Get slow: expected timeout")
+	}
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, _ = w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(HTTPClientOptions{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+
+	resp, err := client.Get("http://eex.example/page.html")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if proxied != "http://eex.example/page.html" {
+		t.Fatalf("proxied = %q, want request routed through proxy", proxied)
+	}
+}
+
+func TestNewHTTPClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	untrusted, err := NewHTTPClient(HTTPClientOptions{})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if _, err := untrusted.Get(server.URL); err == nil {
+		t.Fatalf("Get without bundle: expected certificate error")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o644); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	trusted, err := NewHTTPClient(HTTPClientOptions{CABundle: bundle})
+	if err != nil {
+		t.Fatalf("NewHTTPClient with bundle: %v", err)
+	}
+	resp, err := trusted.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get with bundle: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClientErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+
+	cases := map[string]HTTPClientOptions{
+		"negative timeout": {Timeout: -time.Second},
+		"negative idle":    {MaxIdleConns: -1},
+		"relative proxy":   {ProxyURL: "proxy:3128"},
+		"missing bundle":   {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"empty bundle":     {CABundle: empty},
+	}
+	for name, options := range cases {
+		if _, err := NewHTTPClient(options); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestNewHTTPClientBlockPrivate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(HTTPClientOptions{ConnectTimeout: time.Second, BlockPrivate: true})
+	if err != nil {
+		t.Fatalf("NewHTTPClient: %v", err)
+	}
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrEgressBlocked) {
+		t.Fatalf("Get loopback err = %v, want ErrEgressBlocked", err)
+	}
+
+	allowed, err := NewHTTPClient(HTTPClientOptions{ConnectTimeout: time.Second, BlockPrivate: true, AllowedAddrs: []string{server.Listener.Addr().String()}})
+	if err != nil {
+		t.Fatalf("NewHTTPClient allowed: %v", err)
+	}
+	resp, err := allowed.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get allowed loopback: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := NewHTTPClient(HTTPClientOptions{BlockPrivate: true, ProxyURL: server.URL}); err == nil {
+		t.Fatalf("NewHTTPClient proxy with BlockPrivate: expected error")
+	}
+
+	t.Setenv("HTTP_PROXY", server.URL)
+	envProxied, err := NewHTTPClient(HTTPClientOptions{ConnectTimeout: time.Second, BlockPrivate: true})
+	if err != nil {
+		t.Fatalf("NewHTTPClient env proxy: %v", err)
+	}
+	if proxy := envProxied.Transport.(*http.Transport).Proxy; proxy != nil {
+		t.Fatalf("expected environment proxy to be ignored when blocking private networks")
+	}
+
+	for _, ip := range []string{"100.64.0.1", "0.0.0.1", "10.0.0.1", "169.254.169.254", "::1", "::ffff:127.0.0.1"} {
+		if !isBlockedIP(net.ParseIP(ip)) {
+			t.Fatalf("isBlockedIP(%s) = false, want true", ip)
+		}
+	}
+	if isBlockedIP(net.ParseIP("93.184.216.34")) {
+		t.Fatalf("isBlockedIP(93.184.216.34) = true, want false")
+	}
+}
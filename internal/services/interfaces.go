@@ -8,6 +8,7 @@ import (
 
 type SourceProvider interface {
 	GetSources(ctx context.Context) ([]models.Source, error)
+	UpdateValidators(ctx context.Context, sourceID string, validators HtmlValidators) error
 }
 
 type LogWriter interface {
@@ -15,11 +16,29 @@ type LogWriter interface {
 }
 
 type HtmlFetcher interface {
-	Fetch(ctx context.Context, url string) (HtmlResult, error)
+	Fetch(ctx context.Context, url string, validators HtmlValidators, eventID *string) (HtmlResult, error)
+}
+
+type LLMClient interface {
+	Complete(ctx context.Context, request LLMRequest) (LLMResponse, error)
+}
+
+type LLMCache interface {
+	Lookup(ctx context.Context, key string) (string, bool, error)
+	Store(ctx context.Context, key string, model string, response string) error
+}
+
+type UsageRecorder interface {
+	RecordUsage(ctx context.Context, request LLMRequest, usage LLMUsage) error
 }
 
 type OpenAiExtractor interface {
-	ExtractZipLink(ctx context.Context, html string, eventID *string) (OpenAiResult, error)
+	ExtractZipLinks(ctx context.Context, html string, eventID *string) (OpenAiResult, error)
+}
+
+type LinkRecorder interface {
+	RecordLinks(ctx context.Context, sourceURL string, candidates []OpenAiLinkCandidate) ([]models.DiscoveredLink, error)
+	MarkIngested(ctx context.Context, link string) error
 }
 
 type ZipDownloader interface {
@@ -31,14 +50,30 @@ type ZipProcessor interface {
 }
 
 type ProcessedFileTracker interface {
-	IsProcessed(ctx context.Context, filename string) (bool, error)
-	MarkProcessed(ctx context.Context, filename string) error
+	ZipStatus(ctx context.Context, filename string, contentHash string) (FileStatus, error)
+	MarkProcessed(ctx context.Context, filename string, contentHash string) error
+	WorkbookStatus(ctx context.Context, workbookFilename string, contentHash string) (FileStatus, error)
+	MarkWorkbookProcessed(ctx context.Context, zipFilename string, workbookFilename string, contentHash string) error
 }
 
 type AuctionParser interface {
 	ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error)
 }
 
+type PromptedAuctionParser interface {
+	ParseAuctionResultsWithPrompt(ctx context.Context, payload AuctionPayload, promptVersion string, eventID *string) (AuctionResults, error)
+}
+
+type PayloadRecorder interface {
+	SavePayload(ctx context.Context, payload AuctionPayload, zipName string) error
+}
+
+type PayloadLoader interface {
+	GetPayload(ctx context.Context, sourceFile string) (AuctionPayload, error)
+}
+
 type DataStorer interface {
-	StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (int, error)
+	StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (UpsertCounts, error)
+	ReplaceAuctionResults(ctx context.Context, results AuctionResults, details []AuctionDetailResults, eventID *string) (int, error)
+	StoreAuctionDetails(ctx context.Context, results AuctionDetailResults, eventID *string) (int, error)
 }
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+type jsonSchema struct {
+	types                []string
+	properties           map[string]*jsonSchema
+	required             []string
+	additionalProperties bool
+	items                *jsonSchema
+	enum                 []any
+}
+
+func compileJSONSchema(definition string) (*jsonSchema, error) {
+	var root map[string]any
+	if err := json.Unmarshal([]byte(definition), &root); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+
+	if inner, ok := root["schema"].(map[string]any); ok {
+		if _, named := root["name"]; named {
+			root = inner
+		}
+	}
+
+	return compileSchemaNode(root, "$")
+}
+
+func compileSchemaNode(node map[string]any, path string) (*jsonSchema, error) {
+	schema := &jsonSchema{additionalProperties: true}
+
+	keywords := make([]string, 0, len(node))
+	for keyword := range node {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := node[keyword]
+		switch keyword {
+		case "description", "title":
+		case "type":
+			types, err := schemaTypes(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			schema.types = types
+		case "properties":
+			properties, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: properties must be an object", path)
+			}
+			schema.properties = make(map[string]*jsonSchema, len(properties))
+			for name, raw := range properties {
+				child, ok := raw.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("%s.%s: schema must be an object", path, name)
+				}
+				compiled, err := compileSchemaNode(child, path+"."+name)
+				if err != nil {
+					return nil, err
+				}
+				schema.properties[name] = compiled
+			}
+		case "required":
+			required, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: required must be an array", path)
+			}
+			for _, raw := range required {
+				name, ok := raw.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s: required entries must be strings", path)
+				}
+				schema.required = append(schema.required, name)
+			}
+		case "additionalProperties":
+			allowed, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: additionalProperties must be a boolean", path)
+			}
+			schema.additionalProperties = allowed
+		case "items":
+			child, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: items must be an object", path)
+			}
+			compiled, err := compileSchemaNode(child, path+"[]")
+			if err != nil {
+				return nil, err
+			}
+			schema.items = compiled
+		case "enum":
+			values, ok := value.([]any)
+			if !ok {
+				return nil, fmt.Errorf("%s: enum must be an array", path)
+			}
+			schema.enum = values
+		default:
+			return nil, fmt.Errorf("%s: unsupported schema keyword %q", path, keyword)
+		}
+	}
+
+	for _, name := range schema.required {
+		if _, ok := schema.properties[name]; !ok {
+			return nil, fmt.Errorf("%s: required property %q is not defined", path, name)
+		}
+	}
+
+	return schema, nil
+}
+
+func schemaTypes(value any) ([]string, error) {
+	var types []string
+	switch typed := value.(type) {
+	case string:
+		types = []string{typed}
+	case []any:
+		for _, raw := range typed {
+			name, ok := raw.(string)
+			if !ok {
+				return nil, errors.New("type entries must be strings")
+			}
+			types = append(types, name)
+		}
+	default:
+		return nil, errors.New("type must be a string or an array")
+	}
+
+	for _, name := range types {
+		switch name {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return nil, fmt.Errorf("unknown type %q", name)
+		}
+	}
+	return types, nil
+}
+
+func (s *jsonSchema) Validate(data []byte) error {
+	if s == nil {
+		return errors.New("schema is nil")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("parse json: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("parse json: trailing data after value")
+	}
+
+	return s.validate(value, "$")
+}
+
+func (s *jsonSchema) validate(value any, path string) error {
+	if len(s.types) > 0 {
+		actual := jsonTypeOf(value)
+		if !s.allowsType(actual, value) {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(s.types, " or "), actual)
+		}
+	}
+
+	if len(s.enum) > 0 && !enumContains(s.enum, value) {
+		return fmt.Errorf("%s: value %v is not one of the allowed values", path, value)
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := typed[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+
+		names := make([]string, 0, len(typed))
+		for name := range typed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child, ok := s.properties[name]
+			if !ok {
+				if !s.additionalProperties {
+					return fmt.Errorf("%s: unknown property %q", path, name)
+				}
+				continue
+			}
+			if err := child.validate(typed[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.items == nil {
+			return nil
+		}
+		for index, item := range typed {
+			if err := s.items.validate(item, fmt.Sprintf("%s[%d]", path, index)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *jsonSchema) allowsType(actual string, value any) bool {
+	for _, expected := range s.types {
+		if expected == actual {
+			return true
+		}
+		if expected == "number" && actual == "integer" {
+			return true
+		}
+		if expected == "integer" && actual == "number" && isIntegral(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeOf(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := typed.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func isIntegral(value any) bool {
+	number, ok := value.(json.Number)
+	if !ok {
+		return false
+	}
+	parsed, ok := new(big.Float).SetString(number.String())
+	return ok && parsed.IsInt()
+}
+
+func enumContains(values []any, value any) bool {
+	for _, allowed := range values {
+		switch typed := allowed.(type) {
+		case float64:
+			number, ok := value.(json.Number)
+			if !ok {
+				continue
+			}
+			if parsed, err := number.Float64(); err == nil && parsed == typed {
+				return true
+			}
+		case string, bool, nil:
+			if allowed == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileJSONSchemaRejectsUnknownKeywords(t *testing.T) {
+	if auctionResultsJSONSchemaErr != nil {
+		t.Fatalf("compile auction results schema: %v", auctionResultsJSONSchemaErr)
+	}
+	if zipLinksJSONSchemaErr != nil {
+		t.Fatalf("compile zip links schema: %v", zipLinksJSONSchemaErr)
+	}
+
+	_, err := compileJSONSchema(`{"name":"x","strict":true,"schema":{"type":"object","properties":{"source_file":{"type":"string","year":"part of the filename"}}}}`)
+	if err == nil || !strings.Contains(err.Error(), `$.source_file: unsupported schema keyword "year"`) {
+		t.Fatalf("compile err = %v, want unsupported keyword", err)
+	}
+
+	_, err = compileJSONSchema(`{"type":"object","properties":{},"required":["rows"]}`)
+	if err == nil || !strings.Contains(err.Error(), `required property "rows" is not defined`) {
+		t.Fatalf("compile err = %v, want undefined required property", err)
+	}
+}
+
+func TestJSONSchemaValidateAuctionResults(t *testing.T) {
+	valid := `{"source_file":"file.xlsx","participants":3,"rows":[{"region":"A","technology":"B","total_volume_auctioned":1,"total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3}]}`
+	if err := auctionResultsJSONSchema.Validate([]byte(valid)); err != nil {
+		t.Fatalf("Validate valid: %v", err)
+	}
+
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"unknown_field": {
+			content: `{"source_file":"file.xlsx","participants":3,"rows":[],"year":2025}`,
+			want:    `$: unknown property "year"`,
+		},
+		"missing_required": {
+			content: `{"source_file":"file.xlsx","rows":[]}`,
+			want:    `$: missing required property "participants"`,
+		},
+		"wrong_type": {
+			content: `{"source_file":"file.xlsx","participants":3,"rows":[{"region":"A","technology":"B","total_volume_auctioned":"1","total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3}]}`,
+			want:    `$.rows[0].total_volume_auctioned: expected number, got string`,
+		},
+		"null_measure": {
+			content: `{"source_file":"file.xlsx","participants":3,"rows":[{"region":"A","technology":"B","total_volume_auctioned":1,"total_volume_sold":null,"weighted_avg_price_eur_per_mwh":0.3}]}`,
+			want:    `$.rows[0].total_volume_sold: expected number, got null`,
+		},
+		"fractional_integer": {
+			content: `{"source_file":"file.xlsx","participants":3.5,"rows":[]}`,
+			want:    `$.participants: expected integer, got number`,
+		},
+		"nested_unknown": {
+			content: `{"source_file":"file.xlsx","participants":3,"rows":[{"region":"A","technology":"B","total_volume_auctioned":1,"total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3,"year":2025}]}`,
+			want:    `$.rows[0]: unknown property "year"`,
+		},
+		"trailing": {
+			content: `{"source_file":"file.xlsx","participants":3,"rows":[]} {}`,
+			want:    "trailing data",
+		},
+	}
+	for name, tc := range cases {
+		err := auctionResultsJSONSchema.Validate([]byte(tc.content))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+
+	if err := auctionResultsJSONSchema.Validate([]byte(`{"source_file":"file.xlsx","participants":3.0,"rows":[]}`)); err != nil {
+		t.Fatalf("Validate integral float: %v", err)
+	}
+}
+
+func TestJSONSchemaValidateEnum(t *testing.T) {
+	schema, err := compileJSONSchema(`{"type":"object","properties":{"error":{"type":"string","enum":["","NO_RESULTS"]}},"additionalProperties":false}`)
+	if err != nil {
+		t.Fatalf("compileJSONSchema: %v", err)
+	}
+	if err := schema.Validate([]byte(`{"error":"NO_RESULTS"}`)); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := schema.Validate([]byte(`{"error":"boom"}`)); err == nil || !strings.Contains(err.Error(), "$.error") {
+		t.Fatalf("Validate err = %v, want enum error at $.error", err)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LLMCacheService struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+func NewLLMCacheService(db *gorm.DB, ttl time.Duration) (*LLMCacheService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("cache ttl must be positive")
+	}
+
+	return &LLMCacheService{db: db, ttl: ttl}, nil
+}
+
+func (s *LLMCacheService) Lookup(ctx context.Context, key string) (string, bool, error) {
+	if s == nil {
+		return "", false, errors.New("llm cache service is nil")
+	}
+	if s.db == nil {
+		return "", false, errors.New("db is nil")
+	}
+	if key == "" {
+		return "", false, errors.New("cache key is empty")
+	}
+
+	var entries []models.LLMCacheEntry
+	if err := s.db.WithContext(ctx).Where("key = ? AND expires_at > ?", key, time.Now().UTC()).Limit(1).Find(&entries).Error; err != nil {
+		return "", false, fmt.Errorf("lookup llm cache: %w", err)
+	}
+	if len(entries) == 0 {
+		return "", false, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(&models.LLMCacheEntry{}).Where("key = ?", key).Update("hits", gorm.Expr("hits + 1")).Error; err != nil {
+		return "", false, fmt.Errorf("count llm cache hit: %w", err)
+	}
+
+	return entries[0].Response, true, nil
+}
+
+func (s *LLMCacheService) Store(ctx context.Context, key string, model string, response string) error {
+	if s == nil {
+		return errors.New("llm cache service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if key == "" {
+		return errors.New("cache key is empty")
+	}
+
+	now := time.Now().UTC()
+	entry := models.LLMCacheEntry{
+		Key:       key,
+		Model:     model,
+		Response:  response,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.db.WithContext(ctx).Where("key = ?", key).
+		Assign(models.LLMCacheEntry{
+			Model:     model,
+			Response:  response,
+			CreatedAt: now,
+			ExpiresAt: now.Add(s.ttl),
+		}).
+		FirstOrCreate(&entry).Error; err != nil {
+		return fmt.Errorf("store llm cache: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LLMCacheService) GetCacheEntries(ctx context.Context, limit int) ([]models.LLMCacheEntry, error) {
+	if s == nil {
+		return nil, errors.New("llm cache service is nil")
+	}
+	if s.db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+
+	var entries []models.LLMCacheEntry
+	if err := s.db.WithContext(ctx).Order("created_at desc").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("get llm cache entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (s *LLMCacheService) PurgeCacheEntries(ctx context.Context, expiredOnly bool) (int, error) {
+	if s == nil {
+		return 0, errors.New("llm cache service is nil")
+	}
+	if s.db == nil {
+		return 0, errors.New("db is nil")
+	}
+
+	query := s.db.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true})
+	if expiredOnly {
+		query = query.Where("expires_at <= ?", time.Now().UTC())
+	}
+
+	result := query.Delete(&models.LLMCacheEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("purge llm cache: %w", result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+type storeOnlyCache struct {
+	cache LLMCache
+}
+
+func (c storeOnlyCache) Lookup(ctx context.Context, key string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (c storeOnlyCache) Store(ctx context.Context, key string, model string, response string) error {
+	return c.cache.Store(ctx, key, model, response)
+}
+
+func llmEndpointOf(llm LLMClient) LLMEndpoint {
+	described, ok := llm.(interface{ Endpoint() LLMEndpoint })
+	if !ok {
+		return LLMEndpoint{}
+	}
+	return described.Endpoint()
+}
+
+func llmCacheKey(request LLMRequest, endpoint LLMEndpoint) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Model))
+	hash.Write([]byte{0})
+	hash.Write([]byte(endpoint.BaseURL))
+	hash.Write([]byte{0})
+	hash.Write([]byte(strconv.FormatFloat(float64(endpoint.Temperature), 'g', -1, 32)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(request.Prompt))
+	hash.Write([]byte{0})
+	hash.Write(request.Schema)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func completeWithCache(ctx context.Context, llm LLMClient, cache LLMCache, logService LogWriter, request LLMRequest, accept func(content string) error) (LLMUsage, error) {
+	if llm == nil {
+		return LLMUsage{}, errors.New("llm client is nil")
+	}
+	if cache == nil {
+		return LLMUsage{}, errors.New("llm cache is nil")
+	}
+	if logService == nil {
+		return LLMUsage{}, errors.New("log service is nil")
+	}
+	if accept == nil {
+		return LLMUsage{}, errors.New("accept func is nil")
+	}
+
+	key := llmCacheKey(request, llmEndpointOf(llm))
+	cached, ok, err := cache.Lookup(ctx, key)
+	if err != nil {
+		msg := fmt.Sprintf("lookup key=%s model=%s: %v", key, request.Model, err)
+		_ = logService.CreateLog(ctx, request.EventID, LogActionLLMCache, LogOutcomeFail, &msg)
+	}
+	if ok {
+		acceptErr := accept(cached)
+		if acceptErr == nil {
+			msg := fmt.Sprintf("hit key=%s model=%s", key, request.Model)
+			_ = logService.CreateLog(ctx, request.EventID, LogActionLLMCache, LogOutcomeSuccess, &msg)
+			return LLMUsage{}, nil
+		}
+		msg := fmt.Sprintf("rejected key=%s model=%s: %v", key, request.Model, acceptErr)
+		_ = logService.CreateLog(ctx, request.EventID, LogActionLLMCache, LogOutcomeFail, &msg)
+	}
+
+	msg := fmt.Sprintf("miss key=%s model=%s", key, request.Model)
+	_ = logService.CreateLog(ctx, request.EventID, LogActionLLMCache, LogOutcomeSuccess, &msg)
+
+	response, err := llm.Complete(ctx, request)
+	if err != nil {
+		return LLMUsage{}, err
+	}
+	if err := accept(response.Content); err != nil {
+		return response.Usage, err
+	}
+
+	if err := cache.Store(ctx, key, request.Model, response.Content); err != nil {
+		msg := fmt.Sprintf("store key=%s model=%s: %v", key, request.Model, err)
+		_ = logService.CreateLog(ctx, request.EventID, LogActionLLMCache, LogOutcomeFail, &msg)
+	}
+
+	return response.Usage, nil
+}
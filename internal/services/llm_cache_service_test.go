@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func createLLMCacheTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := "CREATE TABLE llm_cache (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), key TEXT NOT NULL UNIQUE, model TEXT NOT NULL, response TEXT NOT NULL, hits INTEGER NOT NULL DEFAULT 0, created_at DATETIME NOT NULL, expires_at DATETIME NOT NULL)"
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create llm_cache table: %v", err)
+	}
+}
+
+func TestLLMCacheServiceLookupAndStore(t *testing.T) {
+	db := openTestDB(t)
+	createLLMCacheTable(t, db)
+
+	service, err := NewLLMCacheService(db, time.Hour)
+	if err != nil {
+		t.Fatalf("NewLLMCacheService: %v", err)
+	}
+
+	ctx := context.Background()
+	key := llmCacheKey(LLMRequest{Model: "gpt-4o-mini", Prompt: "prompt"}, LLMEndpoint{})
+	if _, ok, err := service.Lookup(ctx, key); err != nil || ok {
+		t.Fatalf("Lookup empty = %t, %v; want miss", ok, err)
+	}
+
+	if err := service.Store(ctx, key, "gpt-4o-mini", `{"error":""}`); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if err := service.Store(ctx, key, "gpt-4o-mini", `{"error":"NO_RESULTS"}`); err != nil {
+		t.Fatalf("Store again: %v", err)
+	}
+
+	response, ok, err := service.Lookup(ctx, key)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if !ok || response != `{"error":"NO_RESULTS"}` {
+		t.Fatalf("Lookup = %q, %t; want latest response", response, ok)
+	}
+
+	entries, err := service.GetCacheEntries(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetCacheEntries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	if entries[0].Hits != 1 {
+		t.Fatalf("hits = %d, want 1", entries[0].Hits)
+	}
+
+	if err := db.Model(&models.LLMCacheEntry{}).Where("key = ?", key).Update("expires_at", time.Now().UTC().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire entry: %v", err)
+	}
+	if _, ok, err := service.Lookup(ctx, key); err != nil || ok {
+		t.Fatalf("Lookup expired = %t, %v; want miss", ok, err)
+	}
+}
+
+func TestLLMCacheServicePurge(t *testing.T) {
+	db := openTestDB(t)
+	createLLMCacheTable(t, db)
+
+	service, err := NewLLMCacheService(db, time.Hour)
+	if err != nil {
+		t.Fatalf("NewLLMCacheService: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if err := service.Store(ctx, key, "gpt-4o-mini", "{}"); err != nil {
+			t.Fatalf("Store %s: %v", key, err)
+		}
+	}
+	if err := db.Model(&models.LLMCacheEntry{}).Where("key = ?", "a").Update("expires_at", time.Now().UTC().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("expire entry: %v", err)
+	}
+
+	deleted, err := service.PurgeCacheEntries(ctx, true)
+	if err != nil {
+		t.Fatalf("PurgeCacheEntries expired: %v", err)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted = %d, want 1", deleted)
+	}
+
+	deleted, err = service.PurgeCacheEntries(ctx, false)
+	if err != nil {
+		t.Fatalf("PurgeCacheEntries: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted = %d, want 2", deleted)
+	}
+}
+
+func TestLLMCacheKey(t *testing.T) {
+	base := LLMRequest{Model: "gpt-4o-mini", Prompt: "prompt", Schema: []byte(`{"name":"a"}`)}
+	endpoint := LLMEndpoint{BaseURL: "https://api.openai.com", Temperature: 0}
+	key := llmCacheKey(base, endpoint)
+	if len(key) != 64 {
+		t.Fatalf("key length = %d, want 64", len(key))
+	}
+
+	variants := []struct {
+		request  LLMRequest
+		endpoint LLMEndpoint
+	}{
+		{LLMRequest{Model: "gpt-4o", Prompt: base.Prompt, Schema: base.Schema}, endpoint},
+		{LLMRequest{Model: base.Model, Prompt: "other", Schema: base.Schema}, endpoint},
+		{LLMRequest{Model: base.Model, Prompt: base.Prompt, Schema: []byte(`{"name":"b"}`)}, endpoint},
+		{base, LLMEndpoint{BaseURL: "http://localhost:11434", Temperature: 0}},
+		{base, LLMEndpoint{BaseURL: endpoint.BaseURL, Temperature: 0.7}},
+	}
+	for _, variant := range variants {
+		if llmCacheKey(variant.request, variant.endpoint) == key {
+			t.Fatalf("key for %+v %+v matches base key", variant.request, variant.endpoint)
+		}
+	}
+
+	client, err := NewOpenAiCompatibleClient("", endpoint.BaseURL+"/", 0.7, http.DefaultClient, nil, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+	recorder, err := NewLLMUsageService(openTestDB(t), nil)
+	if err != nil {
+		t.Fatalf("NewLLMUsageService: %v", err)
+	}
+	tracked, err := NewUsageTrackingLLMClient(client, recorder, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewUsageTrackingLLMClient: %v", err)
+	}
+	if got := llmEndpointOf(tracked); got != (LLMEndpoint{BaseURL: endpoint.BaseURL, Temperature: 0.7}) {
+		t.Fatalf("endpoint = %+v, want base url and temperature forwarded", got)
+	}
+}
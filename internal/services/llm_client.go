@@ -0,0 +1,211 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const openAiDefaultBaseURL = "https://api.openai.com"
+
+type LLMRequest struct {
+	Model      string
+	Prompt     string
+	Schema     json.RawMessage
+	EventID    *string
+	Action     string
+	SourceFile string
+}
+
+type LLMEndpoint struct {
+	BaseURL     string
+	Temperature float32
+}
+
+type LLMUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+type LLMResponse struct {
+	Content string
+	Usage   LLMUsage
+}
+
+type OpenAiCompatibleClient struct {
+	apiKey      string
+	baseURL     string
+	temperature float32
+	client      *http.Client
+	retrier     *Retrier
+	limiter     *RateLimiter
+}
+
+func NewOpenAiCompatibleClient(apiKey string, baseURL string, temperature float32, client *http.Client, retrier *Retrier, limiter *RateLimiter) (*OpenAiCompatibleClient, error) {
+	if temperature < 0 {
+		return nil, errors.New("temperature must not be negative")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = openAiDefaultBaseURL
+	}
+	if retrier == nil {
+		retrier = singleAttemptRetrier()
+	}
+
+	return &OpenAiCompatibleClient{
+		apiKey:      apiKey,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		temperature: temperature,
+		client:      client,
+		retrier:     retrier,
+		limiter:     limiter,
+	}, nil
+}
+
+func (c *OpenAiCompatibleClient) Endpoint() LLMEndpoint {
+	if c == nil {
+		return LLMEndpoint{}
+	}
+	return LLMEndpoint{BaseURL: c.baseURL, Temperature: c.temperature}
+}
+
+func (c *OpenAiCompatibleClient) Complete(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	if c == nil {
+		return LLMResponse{}, errors.New("llm client is nil")
+	}
+	if c.client == nil {
+		return LLMResponse{}, errors.New("http client is nil")
+	}
+	if c.retrier == nil {
+		return LLMResponse{}, errors.New("retrier is nil")
+	}
+	if request.Model == "" {
+		return LLMResponse{}, errors.New("model is empty")
+	}
+	if strings.TrimSpace(request.Prompt) == "" {
+		return LLMResponse{}, errors.New("prompt is empty")
+	}
+
+	requestBody := openAiStructuredRequest{
+		Model:       request.Model,
+		Temperature: c.temperature,
+		Messages: []openAiMessage{
+			{Role: "user", Content: request.Prompt},
+		},
+	}
+	if len(request.Schema) > 0 {
+		requestBody.ResponseFormat = &openAiResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: request.Schema,
+		}
+	}
+
+	payload, err := json.Marshal(requestBody)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("encode request: %w", err)
+	}
+
+	var body []byte
+	err = c.retrier.Do(ctx, upstreamHost(c.baseURL), request.Action, request.EventID, func(ctx context.Context) error {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/chat/completions", bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("send request: %w", err)
+		}
+
+		data, readErr := io.ReadAll(resp.Body)
+		closeErr := resp.Body.Close()
+		if readErr != nil {
+			return fmt.Errorf("read response: %w", readErr)
+		}
+		if closeErr != nil {
+			return fmt.Errorf("close response: %w", closeErr)
+		}
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return newHTTPStatusError("llm", resp, data)
+		}
+
+		body = data
+		return nil
+	})
+	if err != nil {
+		return LLMResponse{}, err
+	}
+
+	var response openAiChatResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return LLMResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+	usage := LLMUsage{
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}
+	if len(response.Choices) == 0 {
+		return LLMResponse{Usage: usage}, errors.New("llm response has no choices")
+	}
+
+	content := strings.TrimSpace(response.Choices[0].Message.Content)
+	if content == "" {
+		return LLMResponse{Usage: usage}, errors.New("llm response content is empty")
+	}
+
+	return LLMResponse{Content: content, Usage: usage}, nil
+}
+
+type openAiStructuredRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAiMessage       `json:"messages"`
+	Temperature    float32               `json:"temperature"`
+	ResponseFormat *openAiResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAiResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema json.RawMessage `json:"json_schema"`
+}
+
+type openAiMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAiChatResponse struct {
+	Choices []openAiChoice `json:"choices"`
+	Usage   openAiUsage    `json:"usage"`
+}
+
+type openAiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAiChoice struct {
+	Message openAiResponseMessage `json:"message"`
+}
+
+type openAiResponseMessage struct {
+	Content string `json:"content"`
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"solback/internal/llmfake"
+	"solback/internal/models"
+)
+
+func TestOpenAiCompatibleClientComplete(t *testing.T) {
+	fake, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "text", " world \n")})
+
+	client, err := NewOpenAiCompatibleClient("", server.URL+"/", 0.5, server.Client(), nil, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+
+	response, err := client.Complete(context.Background(), LLMRequest{Model: "llama3", Prompt: "hello"})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if response.Content != "world" {
+		t.Fatalf("content = %q, want %q", response.Content, "world")
+	}
+	if response.Usage.PromptTokens != 2 || response.Usage.CompletionTokens != 2 || response.Usage.TotalTokens != 4 {
+		t.Fatalf("usage = %+v, want 2/2/4", response.Usage)
+	}
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Model != "llama3" || calls[0].Temperature != 0.5 || calls[0].Schema != "text" || calls[0].Prompt != "hello" || calls[0].APIKey != "" {
+		t.Fatalf("calls = %+v, want one keyless text call for llama3", calls)
+	}
+}
+
+func TestOpenAiCompatibleClientErrors(t *testing.T) {
+	_, server := newFakeLLMServer(t, llmfake.Options{Faults: []llmfake.Fault{{Status: http.StatusTooManyRequests}}})
+
+	client := newTestLLMClient(t, server)
+
+	if _, err := client.Complete(context.Background(), LLMRequest{Prompt: "hello"}); err == nil {
+		t.Fatalf("Complete without model: expected error")
+	}
+	_, err := client.Complete(context.Background(), LLMRequest{Model: "gpt-4o-mini", Prompt: "hello"})
+	if err == nil {
+		t.Fatalf("Complete: expected error")
+	}
+	if !strings.Contains(err.Error(), "llm status 429") {
+		t.Fatalf("error = %v, want status 429", err)
+	}
+}
+
+func TestOpenAiCompatibleClientReturnsUsageForEmptyContent(t *testing.T) {
+	_, server := newFakeLLMServer(t, llmfake.Options{Faults: []llmfake.Fault{{Content: "  "}}})
+
+	db := openTestDB(t)
+	createLLMUsageTable(t, db)
+	recorder, err := NewLLMUsageService(db, nil)
+	if err != nil {
+		t.Fatalf("NewLLMUsageService: %v", err)
+	}
+	client, err := NewUsageTrackingLLMClient(newTestLLMClient(t, server), recorder, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewUsageTrackingLLMClient: %v", err)
+	}
+
+	_, err = client.Complete(context.Background(), LLMRequest{Model: "gpt-4o-mini", Prompt: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "content is empty") {
+		t.Fatalf("err = %v, want empty content error", err)
+	}
+
+	var entries []models.LLMUsage
+	if err := db.Find(&entries).Error; err != nil {
+		t.Fatalf("load usage: %v", err)
+	}
+	if len(entries) != 1 || entries[0].PromptTokens == 0 {
+		t.Fatalf("usage entries = %+v, want the billed prompt recorded", entries)
+	}
+}
+
+func TestOpenAiCompatibleClientRateLimitsRetries(t *testing.T) {
+	fake, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "text", "ok"), Faults: []llmfake.Fault{{Status: http.StatusServiceUnavailable, Times: 1}}})
+
+	retrier, _ := newTestRetrier(t, 3, nil, &stubLogWriter{})
+	limiter, err := NewRateLimiter(1)
+	if err != nil {
+		t.Fatalf("NewRateLimiter: %v", err)
+	}
+	client, err := NewOpenAiCompatibleClient("test-key", server.URL, 0, server.Client(), retrier, limiter)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Complete(ctx, LLMRequest{Model: "gpt-4o-mini", Prompt: "hello"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want the retry to wait on the rate limiter", err)
+	}
+	if fake.Requests() != 1 {
+		t.Fatalf("requests = %d, want 1 before the limiter blocks the retry", fake.Requests())
+	}
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const usageDayLayout = "2006-01-02"
+
+var ErrInvalidUsageRange = errors.New("invalid usage range")
+
+type LLMPrice struct {
+	PromptPerMillion     float64
+	CompletionPerMillion float64
+}
+
+type UsageTotals struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+	UnpricedCalls    int     `json:"unpriced_calls"`
+}
+
+type UsageDay struct {
+	Day string `json:"day"`
+	UsageTotals
+}
+
+type UsageRun struct {
+	EventID   string    `json:"event_id"`
+	StartedAt time.Time `json:"started_at"`
+	UsageTotals
+}
+
+type UsageReport struct {
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Totals UsageTotals `json:"totals"`
+	Days   []UsageDay  `json:"days"`
+	Runs   []UsageRun  `json:"runs"`
+}
+
+type LLMUsageService struct {
+	db     *gorm.DB
+	prices map[string]LLMPrice
+}
+
+func NewLLMUsageService(db *gorm.DB, prices map[string]LLMPrice) (*LLMUsageService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	copied := make(map[string]LLMPrice, len(prices))
+	for model, price := range prices {
+		if price.PromptPerMillion < 0 || price.CompletionPerMillion < 0 {
+			return nil, fmt.Errorf("price for model %s is negative", model)
+		}
+		copied[model] = price
+	}
+
+	return &LLMUsageService{db: db, prices: copied}, nil
+}
+
+func (s *LLMUsageService) RecordUsage(ctx context.Context, request LLMRequest, usage LLMUsage) error {
+	if s == nil {
+		return errors.New("llm usage service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if request.Model == "" {
+		return errors.New("model is empty")
+	}
+
+	totalTokens := usage.TotalTokens
+	if totalTokens == 0 {
+		totalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	entry := models.LLMUsage{
+		EventID:          request.EventID,
+		Action:           request.Action,
+		Model:            request.Model,
+		SourceFile:       request.SourceFile,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      totalTokens,
+		CreatedAt:        time.Now().UTC(),
+	}
+	if price, ok := s.prices[request.Model]; ok {
+		cost := (float64(usage.PromptTokens)*price.PromptPerMillion + float64(usage.CompletionTokens)*price.CompletionPerMillion) / 1_000_000
+		entry.CostUSD = &cost
+	}
+
+	if err := s.db.WithContext(ctx).Create(&entry).Error; err != nil {
+		return fmt.Errorf("record llm usage: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LLMUsageService) GetUsage(ctx context.Context, from string, to string) (UsageReport, error) {
+	if s == nil {
+		return UsageReport{}, errors.New("llm usage service is nil")
+	}
+	if s.db == nil {
+		return UsageReport{}, errors.New("db is nil")
+	}
+
+	start, end, err := parseUsageRange(from, to, time.Now().UTC())
+	if err != nil {
+		return UsageReport{}, err
+	}
+
+	var entries []models.LLMUsage
+	if err := s.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", start, end.AddDate(0, 0, 1)).
+		Order("created_at asc").
+		Find(&entries).Error; err != nil {
+		return UsageReport{}, fmt.Errorf("get llm usage: %w", err)
+	}
+
+	report := UsageReport{
+		From: start.Format(usageDayLayout),
+		To:   end.Format(usageDayLayout),
+		Days: []UsageDay{},
+		Runs: []UsageRun{},
+	}
+	dayIndex := make(map[string]int)
+	runIndex := make(map[string]int)
+	for _, entry := range entries {
+		addUsage(&report.Totals, entry)
+
+		day := entry.CreatedAt.UTC().Format(usageDayLayout)
+		index, ok := dayIndex[day]
+		if !ok {
+			index = len(report.Days)
+			dayIndex[day] = index
+			report.Days = append(report.Days, UsageDay{Day: day})
+		}
+		addUsage(&report.Days[index].UsageTotals, entry)
+
+		eventID := ""
+		if entry.EventID != nil {
+			eventID = *entry.EventID
+		}
+		index, ok = runIndex[eventID]
+		if !ok {
+			index = len(report.Runs)
+			runIndex[eventID] = index
+			report.Runs = append(report.Runs, UsageRun{EventID: eventID, StartedAt: entry.CreatedAt})
+		}
+		addUsage(&report.Runs[index].UsageTotals, entry)
+	}
+
+	return report, nil
+}
+
+func addUsage(totals *UsageTotals, entry models.LLMUsage) {
+	totals.Calls++
+	totals.PromptTokens += entry.PromptTokens
+	totals.CompletionTokens += entry.CompletionTokens
+	totals.TotalTokens += entry.TotalTokens
+	if entry.CostUSD == nil {
+		totals.UnpricedCalls++
+		return
+	}
+	totals.CostUSD += *entry.CostUSD
+}
+
+func parseUsageRange(from string, to string, now time.Time) (time.Time, time.Time, error) {
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		parsed, err := time.Parse(usageDayLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to %q", ErrInvalidUsageRange, to)
+		}
+		end = parsed
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		parsed, err := time.Parse(usageDayLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from %q", ErrInvalidUsageRange, from)
+		}
+		start = parsed
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from is after to", ErrInvalidUsageRange)
+	}
+
+	return start, end, nil
+}
+
+type UsageTrackingLLMClient struct {
+	next       LLMClient
+	recorder   UsageRecorder
+	logService LogWriter
+}
+
+func NewUsageTrackingLLMClient(next LLMClient, recorder UsageRecorder, logService LogWriter) (*UsageTrackingLLMClient, error) {
+	if next == nil {
+		return nil, errors.New("llm client is nil")
+	}
+	if recorder == nil {
+		return nil, errors.New("usage recorder is nil")
+	}
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &UsageTrackingLLMClient{next: next, recorder: recorder, logService: logService}, nil
+}
+
+func (c *UsageTrackingLLMClient) Endpoint() LLMEndpoint {
+	if c == nil {
+		return LLMEndpoint{}
+	}
+	return llmEndpointOf(c.next)
+}
+
+func (c *UsageTrackingLLMClient) Complete(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	if c == nil {
+		return LLMResponse{}, errors.New("usage tracking llm client is nil")
+	}
+	if c.next == nil {
+		return LLMResponse{}, errors.New("llm client is nil")
+	}
+
+	response, err := c.next.Complete(ctx, request)
+	if err != nil && response.Usage == (LLMUsage{}) {
+		return LLMResponse{}, err
+	}
+
+	if c.recorder != nil {
+		if err := c.recorder.RecordUsage(ctx, request, response.Usage); err != nil && c.logService != nil {
+			msg := fmt.Sprintf("model=%s action=%s source_file=%s record usage: %v", request.Model, request.Action, request.SourceFile, err)
+			_ = c.logService.CreateLog(ctx, request.EventID, LogActionLLMUsage, LogOutcomeFail, &msg)
+		}
+	}
+	if err != nil {
+		return LLMResponse{}, err
+	}
+
+	return response, nil
+}
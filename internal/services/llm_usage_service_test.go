@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"solback/internal/models"
+
+	"gorm.io/gorm"
+)
+
+func createLLMUsageTable(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	query := "CREATE TABLE llm_usage (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), event_id TEXT, action TEXT NOT NULL, model TEXT NOT NULL, source_file TEXT NOT NULL DEFAULT '', prompt_tokens INTEGER NOT NULL, completion_tokens INTEGER NOT NULL, total_tokens INTEGER NOT NULL, cost_usd REAL, created_at DATETIME NOT NULL)"
+	if err := db.Exec(query).Error; err != nil {
+		t.Fatalf("create llm_usage table: %v", err)
+	}
+}
+
+type stubLLMClient struct {
+	response LLMResponse
+	err      error
+	requests []LLMRequest
+}
+
+func (s *stubLLMClient) Complete(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	s.requests = append(s.requests, request)
+	if s.err != nil {
+		return LLMResponse{}, s.err
+	}
+	return s.response, nil
+}
+
+func TestLLMUsageServiceRecordAndReport(t *testing.T) {
+	db := openTestDB(t)
+	createLLMUsageTable(t, db)
+
+	service, err := NewLLMUsageService(db, map[string]LLMPrice{
+		"gpt-4o-mini": {PromptPerMillion: 0.15, CompletionPerMillion: 0.6},
+	})
+	if err != nil {
+		t.Fatalf("NewLLMUsageService: %v", err)
+	}
+
+	ctx := context.Background()
+	eventID := "event-1"
+	if err := service.RecordUsage(ctx, LLMRequest{Model: "gpt-4o-mini", EventID: &eventID, Action: LogActionOpenAICSVParse, SourceFile: "a.xlsx"}, LLMUsage{PromptTokens: 1000000, CompletionTokens: 1000000}); err != nil {
+		t.Fatalf("RecordUsage priced: %v", err)
+	}
+	if err := service.RecordUsage(ctx, LLMRequest{Model: "local-model", EventID: &eventID, Action: LogActionOpenAIHTMLExtract}, LLMUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}); err != nil {
+		t.Fatalf("RecordUsage unpriced: %v", err)
+	}
+	if err := service.RecordUsage(ctx, LLMRequest{Model: "gpt-4o-mini", Action: LogActionOpenAIHTMLExtract}, LLMUsage{PromptTokens: 100, CompletionTokens: 0}); err != nil {
+		t.Fatalf("RecordUsage no event: %v", err)
+	}
+
+	var stored models.LLMUsage
+	if err := db.Where("source_file = ?", "a.xlsx").First(&stored).Error; err != nil {
+		t.Fatalf("load usage: %v", err)
+	}
+	if stored.TotalTokens != 2000000 {
+		t.Fatalf("total tokens = %d, want 2000000", stored.TotalTokens)
+	}
+	if stored.CostUSD == nil || math.Abs(*stored.CostUSD-0.75) > 1e-9 {
+		t.Fatalf("cost = %v, want 0.75", stored.CostUSD)
+	}
+
+	today := time.Now().UTC().Format(usageDayLayout)
+	report, err := service.GetUsage(ctx, "", "")
+	if err != nil {
+		t.Fatalf("GetUsage: %v", err)
+	}
+	if report.To != today {
+		t.Fatalf("to = %s, want %s", report.To, today)
+	}
+	if report.Totals.Calls != 3 || report.Totals.UnpricedCalls != 1 {
+		t.Fatalf("totals = %+v, want 3 calls with 1 unpriced", report.Totals)
+	}
+	if report.Totals.TotalTokens != 2000115 {
+		t.Fatalf("total tokens = %d, want 2000115", report.Totals.TotalTokens)
+	}
+	if len(report.Days) != 1 || report.Days[0].Day != today || report.Days[0].Calls != 3 {
+		t.Fatalf("days = %+v, want one day with 3 calls", report.Days)
+	}
+	if len(report.Runs) != 2 {
+		t.Fatalf("runs = %d, want 2", len(report.Runs))
+	}
+	if report.Runs[0].EventID != eventID || report.Runs[0].Calls != 2 {
+		t.Fatalf("first run = %+v, want %s with 2 calls", report.Runs[0], eventID)
+	}
+
+	report, err = service.GetUsage(ctx, "2000-01-01", "2000-01-31")
+	if err != nil {
+		t.Fatalf("GetUsage past range: %v", err)
+	}
+	if report.Totals.Calls != 0 || len(report.Days) != 0 {
+		t.Fatalf("past report = %+v, want empty", report)
+	}
+}
+
+func TestLLMUsageServiceInvalidRange(t *testing.T) {
+	db := openTestDB(t)
+	createLLMUsageTable(t, db)
+
+	service, err := NewLLMUsageService(db, nil)
+	if err != nil {
+		t.Fatalf("NewLLMUsageService: %v", err)
+	}
+
+	for _, tc := range [][2]string{{"2025-13-01", ""}, {"", "yesterday"}, {"2025-02-01", "2025-01-01"}} {
+		if _, err := service.GetUsage(context.Background(), tc[0], tc[1]); !errors.Is(err, ErrInvalidUsageRange) {
+			t.Fatalf("GetUsage(%q, %q) error = %v, want ErrInvalidUsageRange", tc[0], tc[1], err)
+		}
+	}
+}
+
+func TestUsageTrackingLLMClient(t *testing.T) {
+	db := openTestDB(t)
+	createLLMUsageTable(t, db)
+
+	recorder, err := NewLLMUsageService(db, nil)
+	if err != nil {
+		t.Fatalf("NewLLMUsageService: %v", err)
+	}
+	next := &stubLLMClient{response: LLMResponse{Content: "{}", Usage: LLMUsage{PromptTokens: 7, CompletionTokens: 3, TotalTokens: 10}}}
+	client, err := NewUsageTrackingLLMClient(next, recorder, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewUsageTrackingLLMClient: %v", err)
+	}
+
+	eventID := "event-2"
+	response, err := client.Complete(context.Background(), LLMRequest{Model: "gpt-4o-mini", Prompt: "p", EventID: &eventID, Action: LogActionOpenAICSVParse, SourceFile: "b.xlsx"})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if response.Content != "{}" {
+		t.Fatalf("content = %q, want %q", response.Content, "{}")
+	}
+
+	var entries []models.LLMUsage
+	if err := db.Find(&entries).Error; err != nil {
+		t.Fatalf("load usage: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("usage entries = %d, want 1", len(entries))
+	}
+	if entries[0].EventID == nil || *entries[0].EventID != eventID || entries[0].SourceFile != "b.xlsx" || entries[0].TotalTokens != 10 {
+		t.Fatalf("usage entry = %+v, want event-2 b.xlsx with 10 tokens", entries[0])
+	}
+
+	next.err = errors.New("boom")
+	if _, err := client.Complete(context.Background(), LLMRequest{Model: "gpt-4o-mini", Prompt: "p"}); err == nil {
+		t.Fatalf("Complete: expected error")
+	}
+	var count int64
+	if err := db.Model(&models.LLMUsage{}).Count(&count).Error; err != nil {
+		t.Fatalf("count usage: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("usage count = %d, want 1 after failed call", count)
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type auctionColumns struct {
+	region                      int
+	technology                  int
+	totalVolumeAuctioned        int
+	totalVolumeSold             int
+	weightedAvgPriceEurPerMwh   int
+	myTotalVolume               int
+	myWeightedAvgPriceEurPerMwh int
+	numberOfWinners             int
+}
+
+type LocalAuctionParser struct {
+	logService LogWriter
+}
+
+func NewLocalAuctionParser(logService LogWriter) (*LocalAuctionParser, error) {
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &LocalAuctionParser{logService: logService}, nil
+}
+
+func (s *LocalAuctionParser) ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error) {
+	if s == nil {
+		return AuctionResults{}, errors.New("local auction parser is nil")
+	}
+	if s.logService == nil {
+		return AuctionResults{}, errors.New("log service is nil")
+	}
+	if payload.SourceFile == "" {
+		return AuctionResults{}, errors.New("source file is empty")
+	}
+	if payload.Participants <= 0 {
+		return AuctionResults{}, errors.New("participants must be positive")
+	}
+	if len(payload.Headers) == 0 {
+		return AuctionResults{}, errors.New("headers are empty")
+	}
+	if len(payload.Rows) == 0 {
+		return AuctionResults{}, errors.New("rows are empty")
+	}
+
+	columns, err := mapAuctionColumns(payload.Headers)
+	if err != nil {
+		msg := fmt.Sprintf("source_file=%s map headers: %v", payload.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
+	}
+
+	year, month, err := parseYearMonthFromSourceFile(payload.SourceFile)
+	if err != nil {
+		msg := fmt.Sprintf("source_file=%s apply year/month: %v", payload.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
+	}
+
+	result := AuctionResults{
+		SourceFile:   payload.SourceFile,
+		Participants: payload.Participants,
+	}
+	var parseErr error
+	for index, cells := range payload.Rows {
+		row, err := parseAuctionRow(cells, columns)
+		if err != nil {
+			msg := fmt.Sprintf("source_file=%s row=%d: %v", payload.SourceFile, index, err)
+			_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeFail, &msg)
+			if parseErr == nil {
+				parseErr = fmt.Errorf("row %d: %w", index, err)
+			}
+			continue
+		}
+		row.Year = float64(year)
+		row.Month = float64(month)
+		result.Rows = append(result.Rows, row)
+	}
+
+	if len(result.Rows) == 0 {
+		if parseErr != nil {
+			return AuctionResults{}, parseErr
+		}
+		return AuctionResults{}, errors.New("local parser returned empty rows")
+	}
+	if err := validateAuctionResults(result); err != nil {
+		msg := fmt.Sprintf("source_file=%s validate local result: %v", payload.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
+	}
+
+	msg := fmt.Sprintf("source_file=%s rows=%d skipped=%d", payload.SourceFile, len(result.Rows), len(payload.Rows)-len(result.Rows))
+	_ = s.logService.CreateLog(ctx, eventID, LogActionLocalCSVParse, LogOutcomeSuccess, &msg)
+
+	if parseErr != nil {
+		return result, parseErr
+	}
+	return result, nil
+}
+
+func mapAuctionColumns(headers []string) (auctionColumns, error) {
+	columns := findAuctionColumns(headers)
+	if columns.region == -1 {
+		return auctionColumns{}, errors.New("region column not found")
+	}
+	if columns.technology == -1 {
+		return auctionColumns{}, errors.New("technology column not found")
+	}
+	if columns.totalVolumeAuctioned == -1 {
+		return auctionColumns{}, errors.New("total volume auctioned column not found")
+	}
+	if columns.totalVolumeSold == -1 {
+		return auctionColumns{}, errors.New("total volume sold column not found")
+	}
+	if columns.weightedAvgPriceEurPerMwh == -1 {
+		return auctionColumns{}, errors.New("weighted average price column not found")
+	}
+
+	return columns, nil
+}
+
+func findAuctionColumns(headers []string) auctionColumns {
+	columns := auctionColumns{
+		region:                      -1,
+		technology:                  -1,
+		totalVolumeAuctioned:        -1,
+		totalVolumeSold:             -1,
+		weightedAvgPriceEurPerMwh:   -1,
+		myTotalVolume:               -1,
+		myWeightedAvgPriceEurPerMwh: -1,
+		numberOfWinners:             -1,
+	}
+
+	for index, header := range headers {
+		normalized := strings.ToLower(strings.Join(strings.Fields(header), " "))
+		var target *int
+		switch {
+		case strings.Contains(normalized, "number of winners"):
+			target = &columns.numberOfWinners
+		case strings.Contains(normalized, "my total volume"):
+			target = &columns.myTotalVolume
+		case strings.Contains(normalized, "my weighted average price"):
+			target = &columns.myWeightedAvgPriceEurPerMwh
+		case strings.Contains(normalized, "total volume auction"):
+			target = &columns.totalVolumeAuctioned
+		case strings.Contains(normalized, "total volume sold"):
+			target = &columns.totalVolumeSold
+		case strings.Contains(normalized, "weighted average price"):
+			target = &columns.weightedAvgPriceEurPerMwh
+		case strings.Contains(normalized, "region"):
+			target = &columns.region
+		case strings.Contains(normalized, "technology"):
+			target = &columns.technology
+		}
+		if target != nil && *target == -1 {
+			*target = index
+		}
+	}
+
+	return columns
+}
+
+func parseAuctionRow(cells []string, columns auctionColumns) (AuctionRow, error) {
+	region := strings.TrimSpace(cellAt(cells, columns.region))
+	if region == "" {
+		return AuctionRow{}, errors.New("region is empty")
+	}
+	technology := strings.TrimSpace(cellAt(cells, columns.technology))
+	if technology == "" {
+		return AuctionRow{}, errors.New("technology is empty")
+	}
+
+	auctioned, err := parseCellNumber(cellAt(cells, columns.totalVolumeAuctioned))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("total volume auctioned: %w", err)
+	}
+	sold, err := parseCellNumber(cellAt(cells, columns.totalVolumeSold))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("total volume sold: %w", err)
+	}
+	price, err := parseCellNumber(cellAt(cells, columns.weightedAvgPriceEurPerMwh))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("weighted average price: %w", err)
+	}
+	myVolume, err := parseCellNumber(cellAt(cells, columns.myTotalVolume))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("my total volume: %w", err)
+	}
+	myPrice, err := parseCellNumber(cellAt(cells, columns.myWeightedAvgPriceEurPerMwh))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("my weighted average price: %w", err)
+	}
+	winners, err := parseCellNumber(cellAt(cells, columns.numberOfWinners))
+	if err != nil {
+		return AuctionRow{}, fmt.Errorf("number of winners: %w", err)
+	}
+
+	row := AuctionRow{
+		Region:                      region,
+		Technology:                  technology,
+		MyTotalVolume:               myVolume,
+		MyWeightedAvgPriceEurPerMwh: myPrice,
+	}
+	if auctioned != nil {
+		row.TotalVolumeAuctioned = *auctioned
+	}
+	if sold != nil {
+		row.TotalVolumeSold = *sold
+	}
+	if price != nil {
+		row.WeightedAvgPriceEurPerMwh = *price
+	}
+	if winners != nil {
+		row.NumberOfWinners = int(*winners)
+	}
+
+	return row, nil
+}
+
+func cellAt(cells []string, index int) string {
+	if index < 0 || index >= len(cells) {
+		return ""
+	}
+	return cells[index]
+}
+
+func parseCellNumber(value string) (*float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\t':
+			return -1
+		}
+		return r
+	}, value)
+	if cleaned == "" || cleaned == "-" {
+		return nil, nil
+	}
+
+	lastComma := strings.LastIndex(cleaned, ",")
+	lastDot := strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma != -1 && lastDot != -1 && lastComma > lastDot:
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case lastComma != -1 && lastDot != -1:
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	case lastComma != -1:
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	}
+
+	parsed, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse number %q: %w", value, err)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalAuctionParserParseAuctionResults(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers: []string{
+			"Région / Region",
+			"Technologie / Technology",
+			"Total Volume Auctionned",
+			"Total Volume Sold",
+			"Weighted Average Price (€ / MWh)",
+			"My Total Volume",
+			"My Weighted Average Price (€ / MWh)",
+			"Number of winners per couple region/technology",
+		},
+		Rows: [][]string{
+			{"Bretagne", "Solaire", "1 234", "1234", "0,37", "-", "", "4"},
+			{"Normandie", "Hydraulique", "1.234,5", "1,234.5", "0.3", "10", "0,5", "1"},
+		},
+	}
+
+	logWriter := &stubLogWriter{}
+	parser, err := NewLocalAuctionParser(logWriter)
+	if err != nil {
+		t.Fatalf("NewLocalAuctionParser: %v", err)
+	}
+
+	result, err := parser.ParseAuctionResults(context.Background(), payload, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != 2 {
+		t.Fatalf("rows = %d, want 2", len(result.Rows))
+	}
+
+	first := result.Rows[0]
+	if first.Year != 2025 || first.Month != 8 {
+		t.Fatalf("year/month = %v/%v, want 2025/8", first.Year, first.Month)
+	}
+	if first.Region != "Bretagne" || first.Technology != "Solaire" {
+		t.Fatalf("region/technology = %q/%q", first.Region, first.Technology)
+	}
+	if first.TotalVolumeAuctioned != 1234 || first.TotalVolumeSold != 1234 {
+		t.Fatalf("volumes = %v/%v, want 1234/1234", first.TotalVolumeAuctioned, first.TotalVolumeSold)
+	}
+	if first.WeightedAvgPriceEurPerMwh != 0.37 {
+		t.Fatalf("price = %v, want 0.37", first.WeightedAvgPriceEurPerMwh)
+	}
+	if first.MyTotalVolume != nil || first.MyWeightedAvgPriceEurPerMwh != nil {
+		t.Fatalf("expected nil my_* fields for dash and blank cells")
+	}
+	if first.NumberOfWinners != 4 {
+		t.Fatalf("number_of_winners = %d, want 4", first.NumberOfWinners)
+	}
+
+	second := result.Rows[1]
+	if second.TotalVolumeAuctioned != 1234.5 || second.TotalVolumeSold != 1234.5 {
+		t.Fatalf("volumes = %v/%v, want 1234.5/1234.5", second.TotalVolumeAuctioned, second.TotalVolumeSold)
+	}
+	if second.MyTotalVolume == nil || *second.MyTotalVolume != 10 {
+		t.Fatalf("my_total_volume = %v, want 10", second.MyTotalVolume)
+	}
+	if second.MyWeightedAvgPriceEurPerMwh == nil || *second.MyWeightedAvgPriceEurPerMwh != 0.5 {
+		t.Fatalf("my_weighted_avg_price = %v, want 0.5", second.MyWeightedAvgPriceEurPerMwh)
+	}
+	if len(logWriter.entries) == 0 || logWriter.entries[len(logWriter.entries)-1].action != LogActionLocalCSVParse {
+		t.Fatalf("expected %s log entry", LogActionLocalCSVParse)
+	}
+}
+
+func TestLocalAuctionParserPartialRows(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+		Rows: [][]string{
+			{"Bretagne", "Solaire", "10", "10", "0.3"},
+			{"Bretagne", "Eolien onshore", "abc", "10", "0.3"},
+		},
+	}
+
+	parser, err := NewLocalAuctionParser(&stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewLocalAuctionParser: %v", err)
+	}
+
+	result, err := parser.ParseAuctionResults(context.Background(), payload, nil)
+	if err == nil {
+		t.Fatalf("expected error for invalid row")
+	}
+	if len(result.Rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(result.Rows))
+	}
+}
+
+func TestLocalAuctionParserMissingColumns(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology"},
+		Rows:         [][]string{{"Bretagne", "Solaire"}},
+	}
+
+	parser, err := NewLocalAuctionParser(&stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewLocalAuctionParser: %v", err)
+	}
+
+	if _, err := parser.ParseAuctionResults(context.Background(), payload, nil); err == nil {
+		t.Fatalf("expected error for missing columns")
+	}
+}
+
+func TestLocalAuctionParserSampleZip(t *testing.T) {
+	zipBytes, err := os.ReadFile(filepath.Join("..", "..", "docs", "20251119_GO_2024_2025_GLOBAL_Results.zip"))
+	if err != nil {
+		t.Fatalf("read zip file: %v", err)
+	}
+
+	xlsxService, err := NewXlsxService()
+	if err != nil {
+		t.Fatalf("NewXlsxService: %v", err)
+	}
+	payloads, err := xlsxService.ExtractAuctionPayloads(context.Background(), zipBytes)
+	if err != nil {
+		t.Fatalf("ExtractAuctionPayloads: %v", err)
+	}
+
+	parser, err := NewLocalAuctionParser(&stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewLocalAuctionParser: %v", err)
+	}
+
+	for _, payload := range payloads {
+		result, err := parser.ParseAuctionResults(context.Background(), payload, nil)
+		if err != nil {
+			t.Fatalf("ParseAuctionResults %s: %v", payload.SourceFile, err)
+		}
+		if len(result.Rows) != len(payload.Rows) {
+			t.Fatalf("%s rows = %d, want %d", payload.SourceFile, len(result.Rows), len(payload.Rows))
+		}
+	}
+}
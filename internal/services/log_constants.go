@@ -1,12 +1,18 @@
 package services
 
 const (
-	LogActionDataRetrieval     = "DATA_RETRIVAL"
-	LogActionOpenAIHTMLExtract = "OPENAPI_CALL_HTML_EXTRACT"
-	LogActionOpenAICSVParse    = "OPENAPI_CALL_CSV_PARSE"
-	LogActionZipDownload       = "ZIP_DOWNLOAD"
-	LogActionZipProcess        = "ZIP_PROCESS"
-	LogActionDataStore         = "DATA_STORE"
-	LogOutcomeSuccess          = "SUCCESS"
-	LogOutcomeFail             = "FAIL"
+	LogActionDataRetrieval        = "DATA_RETRIVAL"
+	LogActionOpenAIHTMLExtract    = "OPENAPI_CALL_HTML_EXTRACT"
+	LogActionHeuristicHTMLExtract = "HEURISTIC_HTML_EXTRACT"
+	LogActionOpenAICSVParse       = "OPENAPI_CALL_CSV_PARSE"
+	LogActionLocalCSVParse        = "LOCAL_CSV_PARSE"
+	LogActionZipDownload          = "ZIP_DOWNLOAD"
+	LogActionZipProcess           = "ZIP_PROCESS"
+	LogActionDataStore            = "DATA_STORE"
+	LogActionDataRevision         = "DATA_REVISION"
+	LogActionLLMCache             = "LLM_CACHE"
+	LogActionLLMUsage             = "LLM_USAGE"
+	LogActionLinkNotInSource      = "LINK_NOT_IN_SOURCE"
+	LogOutcomeSuccess             = "SUCCESS"
+	LogOutcomeFail                = "FAIL"
 )
@@ -1,12 +1,10 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"strconv"
@@ -16,7 +14,7 @@ import (
 const (
 	csvMaxTokenEstimate  = 8000
 	csvMaxRowsPerRequest = 500
-	syntheticCellLength  = 8
+	charsPerToken        = 3
 )
 
 const auctionResultsSchema = `{
@@ -27,9 +25,7 @@ const auctionResultsSchema = `{
     "properties": {
       "source_file": {
         "type": "string",
-        "description": "Original XLSX file name",
-        "year": "part of the filename",
-        "month": "part of the filename"
+        "description": "Original XLSX file name; year and month are derived from it"
       },
       "participants": {
         "type": "integer",
@@ -72,48 +68,77 @@ const auctionResultsSchema = `{
   }
 }`
 
+var auctionResultsJSONSchema, auctionResultsJSONSchemaErr = compileJSONSchema(auctionResultsSchema)
+
+var errRowsQuarantined = errors.New("rows quarantined")
+
 type OpenAiCsvService struct {
-	apiKey     string
-	client     *http.Client
-	baseURL    string
+	llm        LLMClient
+	cache      LLMCache
+	prompts    *PromptRegistry
+	model      string
+	pool       *BatchPool
 	logService LogWriter
 }
 
-func NewOpenAiCsvService(apiKey string, logService LogWriter, client *http.Client, baseURL string) (*OpenAiCsvService, error) {
-	if apiKey == "" {
-		return nil, errors.New("openai api key is empty")
+func NewOpenAiCsvService(llm LLMClient, cache LLMCache, prompts *PromptRegistry, model string, pool *BatchPool, logService LogWriter) (*OpenAiCsvService, error) {
+	if llm == nil {
+		return nil, errors.New("llm client is nil")
+	}
+	if cache == nil {
+		return nil, errors.New("llm cache is nil")
+	}
+	if model == "" {
+		return nil, errors.New("model is empty")
 	}
 	if logService == nil {
 		return nil, errors.New("log service is nil")
 	}
-	if client == nil {
-		client = http.DefaultClient
+	if pool == nil {
+		sequential, err := NewBatchPool(1)
+		if err != nil {
+			return nil, err
+		}
+		pool = sequential
 	}
-	if baseURL == "" {
-		baseURL = openAiDefaultBaseURL
+	prompts, err := defaultPromptRegistry(prompts)
+	if err != nil {
+		return nil, err
 	}
 
 	return &OpenAiCsvService{
-		apiKey:     apiKey,
-		client:     client,
-		baseURL:    baseURL,
+		llm:        llm,
+		cache:      cache,
+		prompts:    prompts,
+		model:      model,
+		pool:       pool,
 		logService: logService,
 	}, nil
 }
 
 func (s *OpenAiCsvService) ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error) {
+	return s.ParseAuctionResultsWithPrompt(ctx, payload, "", eventID)
+}
+
+func (s *OpenAiCsvService) ParseAuctionResultsWithPrompt(ctx context.Context, payload AuctionPayload, promptVersion string, eventID *string) (AuctionResults, error) {
 	if s == nil {
 		return AuctionResults{}, errors.New("openai csv service is nil")
 	}
-	if s.client == nil {
-		return AuctionResults{}, errors.New("http client is nil")
+	if s.llm == nil {
+		return AuctionResults{}, errors.New("llm client is nil")
+	}
+	if s.cache == nil {
+		return AuctionResults{}, errors.New("llm cache is nil")
+	}
+	if s.pool == nil {
+		return AuctionResults{}, errors.New("batch pool is nil")
+	}
+	if s.prompts == nil {
+		return AuctionResults{}, errors.New("prompt registry is nil")
 	}
 	if s.logService == nil {
 		return AuctionResults{}, errors.New("log service is nil")
 	}
-	if s.apiKey == "" {
-		return AuctionResults{}, errors.New("openai api key is empty")
-	}
 	if payload.SourceFile == "" {
 		return AuctionResults{}, errors.New("source file is empty")
 	}
@@ -127,36 +152,45 @@ func (s *OpenAiCsvService) ParseAuctionResults(ctx context.Context, payload Auct
 		return AuctionResults{}, errors.New("rows are empty")
 	}
 
-	batches := splitRows(payload.Rows, csvMaxRowsPerRequest)
-	estimate := estimateTokens(len(batches[0]), len(payload.Headers))
-	ok := estimate <= csvMaxTokenEstimate
-	precheckMsg := fmt.Sprintf("source_file=%s rows=%d batches=%d estimate=%d max_tokens=%d max_rows=%d ok=%t", payload.SourceFile, len(payload.Rows), len(batches), estimate, csvMaxTokenEstimate, csvMaxRowsPerRequest, ok)
-	if !ok {
-		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &precheckMsg)
-		return AuctionResults{}, errors.New("payload too large for configured token budget")
+	template, err := s.prompts.Template(PromptAuctionResults, promptVersion)
+	if err != nil {
+		msg := fmt.Sprintf("source_file=%s load prompt: %v", payload.SourceFile, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
+	}
+
+	batches, estimate, err := planCsvBatches(template, payload, csvMaxRowsPerRequest, csvMaxTokenEstimate)
+	if err != nil {
+		msg := fmt.Sprintf("source_file=%s prompt=%s rows=%d max_tokens=%d max_rows=%d: %v", payload.SourceFile, template.Label(), len(payload.Rows), csvMaxTokenEstimate, csvMaxRowsPerRequest, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
 	}
+	precheckMsg := fmt.Sprintf("source_file=%s prompt=%s rows=%d batches=%d estimate=%d max_tokens=%d max_rows=%d", payload.SourceFile, template.Label(), len(payload.Rows), len(batches), estimate, csvMaxTokenEstimate, csvMaxRowsPerRequest)
 	_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeSuccess, &precheckMsg)
 
 	combined := AuctionResults{
-		SourceFile:   payload.SourceFile,
-		Participants: payload.Participants,
+		SourceFile:    payload.SourceFile,
+		Participants:  payload.Participants,
+		PromptName:    template.Name,
+		PromptVersion: template.Version,
 	}
-	var parseErr error
-
-	for batchIndex, batchRows := range batches {
+	results := make([]AuctionResults, len(batches))
+	errs := s.pool.Run(ctx, len(batches), func(ctx context.Context, index int) error {
 		batchPayload := AuctionPayload{
 			SourceFile:   payload.SourceFile,
 			Participants: payload.Participants,
 			Headers:      payload.Headers,
-			Rows:         batchRows,
+			Rows:         batches[index],
 		}
 
-		result, err := s.parseBatch(ctx, batchPayload, batchIndex+1, eventID)
-		if err != nil {
-			if parseErr == nil {
-				parseErr = fmt.Errorf("batch %d: %w", batchIndex+1, err)
-			}
-			continue
+		result, err := s.parseBatch(ctx, template, batchPayload, index+1, eventID)
+		if err != nil && !errors.Is(err, errRowsQuarantined) {
+			return err
+		}
+		quarantineErr := err
+		if len(result.Rows) == 0 && quarantineErr != nil {
+			results[index] = result
+			return quarantineErr
 		}
 
 		if result.SourceFile == "" {
@@ -167,27 +201,37 @@ func (s *OpenAiCsvService) ParseAuctionResults(ctx context.Context, payload Auct
 		}
 
 		if err := applyYearMonthFromSourceFile(&result); err != nil {
-			msg := fmt.Sprintf("source_file=%s batch=%d apply year/month: %v", result.SourceFile, batchIndex+1, err)
+			msg := fmt.Sprintf("source_file=%s batch=%d apply year/month: %v", result.SourceFile, index+1, err)
 			_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
-			if parseErr == nil {
-				parseErr = fmt.Errorf("batch %d: %w", batchIndex+1, err)
-			}
-			continue
+			return err
 		}
 
 		if err := validateAuctionResults(result); err != nil {
-			msg := fmt.Sprintf("source_file=%s batch=%d validate openai csv result: %v", payload.SourceFile, batchIndex+1, err)
+			msg := fmt.Sprintf("source_file=%s batch=%d validate openai csv result: %v", payload.SourceFile, index+1, err)
 			_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
-			if parseErr == nil {
-				parseErr = fmt.Errorf("batch %d: %w", batchIndex+1, err)
-			}
-			continue
+			return err
 		}
 
-		combined.Rows = append(combined.Rows, result.Rows...)
+		results[index] = result
+		return quarantineErr
+	})
+
+	var parseErr error
+	for index, err := range errs {
+		if err != nil && parseErr == nil {
+			parseErr = fmt.Errorf("batch %d: %w", index+1, err)
+		}
+		combined.Rows = append(combined.Rows, results[index].Rows...)
+		combined.Quarantined = append(combined.Quarantined, results[index].Quarantined...)
+	}
+	if len(combined.Rows) > 0 || len(combined.Quarantined) > 0 {
+		s.logVerification(ctx, payload, combined, eventID)
 	}
 
 	if len(combined.Rows) == 0 {
+		if len(combined.Quarantined) > 0 {
+			return combined, errors.New("no openai rows matched the source cells")
+		}
 		if parseErr != nil {
 			return AuctionResults{}, parseErr
 		}
@@ -200,86 +244,129 @@ func (s *OpenAiCsvService) ParseAuctionResults(ctx context.Context, payload Auct
 	return combined, nil
 }
 
-func (s *OpenAiCsvService) parseBatch(ctx context.Context, payload AuctionPayload, batchIndex int, eventID *string) (AuctionResults, error) {
-	prompt := buildCsvPrompt(payload, batchIndex)
-	result, err := s.callOpenAiStructured(ctx, prompt)
-	if err != nil {
-		msg := fmt.Sprintf("source_file=%s batch=%d attempt=1: %v", payload.SourceFile, batchIndex, err)
-		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
-		return AuctionResults{}, err
+func (s *OpenAiCsvService) logVerification(ctx context.Context, payload AuctionPayload, result AuctionResults, eventID *string) {
+	outcome := LogOutcomeSuccess
+	if len(result.Quarantined) > 0 {
+		outcome = LogOutcomeFail
 	}
+	msg := fmt.Sprintf("source_file=%s verify sent=%d returned=%d verified=%d quarantined=%d", payload.SourceFile, len(payload.Rows), len(result.Rows)+len(result.Quarantined), len(result.Rows), len(result.Quarantined))
+	_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, outcome, &msg)
+}
 
-	msg := fmt.Sprintf("source_file=%s batch=%d rows=%d", payload.SourceFile, batchIndex, len(result.Rows))
-	_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeSuccess, &msg)
-	return result, nil
+func (s *OpenAiCsvService) parseBatch(ctx context.Context, template *PromptTemplate, payload AuctionPayload, batchIndex int, eventID *string) (AuctionResults, error) {
+	return s.parseRows(ctx, template, payload, batchIndex, strconv.Itoa(batchIndex), eventID)
 }
 
-func (s *OpenAiCsvService) callOpenAiStructured(ctx context.Context, prompt string) (AuctionResults, error) {
-	requestBody := openAiStructuredRequest{
-		Model:       openAiDefaultModel,
-		Temperature: 0,
-		Messages: []openAiMessage{
-			{Role: "user", Content: prompt},
-		},
-		ResponseFormat: openAiResponseFormat{
-			Type:       "json_schema",
-			JSONSchema: json.RawMessage(auctionResultsSchema),
-		},
+func (s *OpenAiCsvService) parseRows(ctx context.Context, template *PromptTemplate, payload AuctionPayload, batchIndex int, label string, eventID *string) (AuctionResults, error) {
+	prompt, err := buildCsvPrompt(template, payload, batchIndex)
+	if err != nil {
+		msg := fmt.Sprintf("source_file=%s batch=%s prompt=%s: %v", payload.SourceFile, label, template.Label(), err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
 	}
+	request := s.auctionResultsRequest(payload.SourceFile, prompt, eventID)
+	estimate := estimateRequestTokens(request)
 
-	var buf bytes.Buffer
-	if err := json.NewEncoder(&buf).Encode(requestBody); err != nil {
-		return AuctionResults{}, fmt.Errorf("encode request: %w", err)
+	result, usage, err := s.requestAuctionResults(ctx, request, payload)
+	if errors.Is(err, errRowsQuarantined) {
+		for _, entry := range result.Quarantined {
+			msg := fmt.Sprintf("source_file=%s batch=%s row=%d source_row=%d region=%s technology=%s quarantined: %s", payload.SourceFile, label, entry.Index, entry.SourceRow, entry.Row.Region, entry.Row.Technology, entry.Reason)
+			_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+		}
+		return result, err
 	}
-
-	endpoint := strings.TrimRight(s.baseURL, "/") + "/v1/chat/completions"
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
 	if err != nil {
-		return AuctionResults{}, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+s.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+		if isContextLengthError(err) && len(payload.Rows) > 1 {
+			msg := fmt.Sprintf("source_file=%s batch=%s prompt=%s rows=%d estimate=%d context length exceeded, splitting: %v", payload.SourceFile, label, template.Label(), len(payload.Rows), estimate, err)
+			_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+			return s.splitAndParse(ctx, template, payload, batchIndex, label, eventID)
+		}
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return AuctionResults{}, fmt.Errorf("send request: %w", err)
+		msg := fmt.Sprintf("source_file=%s batch=%s prompt=%s rows=%d estimate=%d: %v", payload.SourceFile, label, template.Label(), len(payload.Rows), estimate, err)
+		_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeFail, &msg)
+		return AuctionResults{}, err
 	}
 
-	body, readErr := io.ReadAll(resp.Body)
-	closeErr := resp.Body.Close()
-	if readErr != nil {
-		return AuctionResults{}, fmt.Errorf("read response: %w", readErr)
-	}
-	if closeErr != nil {
-		return AuctionResults{}, fmt.Errorf("close response: %w", closeErr)
+	msg := fmt.Sprintf("source_file=%s batch=%s prompt=%s rows=%d estimate=%d", payload.SourceFile, label, template.Label(), len(result.Rows), estimate)
+	if usage.PromptTokens > 0 {
+		msg += fmt.Sprintf(" prompt_tokens=%d ratio=%.2f", usage.PromptTokens, float64(usage.PromptTokens)/float64(estimate))
 	}
+	_ = s.logService.CreateLog(ctx, eventID, LogActionOpenAICSVParse, LogOutcomeSuccess, &msg)
+	return result, nil
+}
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return AuctionResults{}, fmt.Errorf("openai status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+func (s *OpenAiCsvService) splitAndParse(ctx context.Context, template *PromptTemplate, payload AuctionPayload, batchIndex int, label string, eventID *string) (AuctionResults, error) {
+	middle := len(payload.Rows) / 2
+	combined := AuctionResults{
+		SourceFile:   payload.SourceFile,
+		Participants: payload.Participants,
 	}
 
-	var response openAiChatResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return AuctionResults{}, fmt.Errorf("decode response: %w", err)
-	}
-	if len(response.Choices) == 0 {
-		return AuctionResults{}, errors.New("openai response has no choices")
+	var quarantineErr error
+	for part, rows := range [][][]string{payload.Rows[:middle], payload.Rows[middle:]} {
+		half := payload
+		half.Rows = rows
+		result, err := s.parseRows(ctx, template, half, batchIndex, fmt.Sprintf("%s.%d", label, part+1), eventID)
+		if err != nil && !errors.Is(err, errRowsQuarantined) {
+			return AuctionResults{}, err
+		}
+		if err != nil && quarantineErr == nil {
+			quarantineErr = err
+		}
+		combined.Quarantined = append(combined.Quarantined, result.Quarantined...)
+		if result.SourceFile != "" {
+			combined.SourceFile = result.SourceFile
+		}
+		if result.Participants != 0 {
+			combined.Participants = result.Participants
+		}
+		combined.Rows = append(combined.Rows, result.Rows...)
 	}
 
-	content := strings.TrimSpace(response.Choices[0].Message.Content)
-	if content == "" {
-		return AuctionResults{}, errors.New("openai response content is empty")
+	return combined, quarantineErr
+}
+
+func (s *OpenAiCsvService) auctionResultsRequest(sourceFile string, prompt string, eventID *string) LLMRequest {
+	return LLMRequest{
+		Model:      s.model,
+		Prompt:     prompt,
+		Schema:     json.RawMessage(auctionResultsSchema),
+		EventID:    eventID,
+		Action:     LogActionOpenAICSVParse,
+		SourceFile: sourceFile,
 	}
+}
 
-	result, err := parseAuctionResults(content)
+func (s *OpenAiCsvService) requestAuctionResults(ctx context.Context, request LLMRequest, payload AuctionPayload) (AuctionResults, LLMUsage, error) {
+	var result AuctionResults
+	usage, err := completeWithCache(ctx, s.llm, s.cache, s.logService, request, func(content string) error {
+		parsed, err := parseAuctionResults(content)
+		if err != nil {
+			return err
+		}
+		verified, quarantined := VerifyAuctionResults(payload, parsed)
+		verified.Quarantined = quarantined
+		result = verified
+		if len(quarantined) > 0 {
+			return fmt.Errorf("%w: %d of %d rows do not match the source cells", errRowsQuarantined, len(quarantined), len(parsed.Rows))
+		}
+		return nil
+	})
+	if errors.Is(err, errRowsQuarantined) {
+		return result, usage, err
+	}
 	if err != nil {
-		return AuctionResults{}, err
+		return AuctionResults{}, usage, err
 	}
 
-	return result, nil
+	return result, usage, nil
 }
 
-func buildCsvPrompt(payload AuctionPayload, batchIndex int) string {
+type csvPromptData struct {
+	Payload string
+}
+
+func buildCsvPrompt(template *PromptTemplate, payload AuctionPayload, batchIndex int) (string, error) {
 	request := struct {
 		SourceFile   string     `json:"source_file"`
 		Participants int        `json:"participants"`
@@ -299,17 +386,7 @@ func buildCsvPrompt(payload AuctionPayload, batchIndex int) string {
 		payloadJSON = []byte(`{}`)
 	}
 
-	return fmt.Sprintf(`Instructions:
-1. Convert the provided rows into the auction_results schema.
-2. Map headers to canonical field names.
-3. Convert decimal commas to decimal points.
-4. Convert "-" or empty cells to null.
-5. Coerce numeric values to numbers.
-6. Do not include year or month fields; they will be derived from source_file.
-7. Return only JSON that matches the provided schema.
-
-Payload:
-%s`, payloadJSON)
+	return template.Render(csvPromptData{Payload: string(payloadJSON)})
 }
 
 func parseAuctionResults(content string) (AuctionResults, error) {
@@ -324,6 +401,13 @@ func parseAuctionResults(content string) (AuctionResults, error) {
 		trimmed = strings.TrimSpace(trimmed)
 	}
 
+	if auctionResultsJSONSchemaErr != nil {
+		return AuctionResults{}, fmt.Errorf("compile auction results schema: %w", auctionResultsJSONSchemaErr)
+	}
+	if err := auctionResultsJSONSchema.Validate([]byte(trimmed)); err != nil {
+		return AuctionResults{}, fmt.Errorf("validate openai json against schema: %w", err)
+	}
+
 	var result AuctionResults
 	if err := json.Unmarshal([]byte(trimmed), &result); err != nil {
 		return AuctionResults{}, fmt.Errorf("parse openai json: %w", err)
@@ -437,13 +521,75 @@ func validateAuctionResults(result AuctionResults) error {
 	return nil
 }
 
-func estimateTokens(rowCount int, columnCount int) int {
-	if rowCount <= 0 || columnCount <= 0 {
-		return 0
+func estimateRequestTokens(request LLMRequest) int {
+	chars := len(request.Prompt) + len(request.Schema)
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+func planCsvBatches(template *PromptTemplate, payload AuctionPayload, maxRows int, maxTokens int) ([][][]string, int, error) {
+	var batches [][][]string
+	var largest int
+	for _, rows := range splitRows(payload.Rows, maxRows) {
+		fitted, estimate, err := fitRowsToBudget(template, payload, rows, len(batches)+1, maxTokens)
+		if err != nil {
+			return nil, 0, err
+		}
+		batches = append(batches, fitted...)
+		if estimate > largest {
+			largest = estimate
+		}
+	}
+	return batches, largest, nil
+}
+
+func fitRowsToBudget(template *PromptTemplate, payload AuctionPayload, rows [][]string, batchIndex int, maxTokens int) ([][][]string, int, error) {
+	payload.Rows = rows
+	prompt, err := buildCsvPrompt(template, payload, batchIndex)
+	if err != nil {
+		return nil, 0, err
+	}
+	estimate := estimateRequestTokens(LLMRequest{
+		Prompt: prompt,
+		Schema: json.RawMessage(auctionResultsSchema),
+	})
+	if estimate <= maxTokens {
+		return [][][]string{rows}, estimate, nil
+	}
+	if len(rows) <= 1 {
+		return nil, 0, fmt.Errorf("single row estimate %d exceeds token budget %d", estimate, maxTokens)
 	}
 
-	chars := rowCount * columnCount * syntheticCellLength
-	return chars / 4
+	middle := len(rows) / 2
+	left, leftEstimate, err := fitRowsToBudget(template, payload, rows[:middle], batchIndex, maxTokens)
+	if err != nil {
+		return nil, 0, err
+	}
+	right, rightEstimate, err := fitRowsToBudget(template, payload, rows[middle:], batchIndex+len(left), maxTokens)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rightEstimate > leftEstimate {
+		leftEstimate = rightEstimate
+	}
+	return append(left, right...), leftEstimate, nil
+}
+
+func isContextLengthError(err error) bool {
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	if statusErr.StatusCode != http.StatusBadRequest && statusErr.StatusCode != http.StatusRequestEntityTooLarge {
+		return false
+	}
+
+	body := strings.ToLower(statusErr.Body)
+	for _, marker := range []string{"context_length_exceeded", "context length", "context window", "maximum context", "too many tokens", "prompt is too long"} {
+		if strings.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 func splitRows(rows [][]string, maxRows int) [][][]string {
@@ -461,15 +607,3 @@ func splitRows(rows [][]string, maxRows int) [][][]string {
 	}
 	return batches
 }
-
-type openAiStructuredRequest struct {
-	Model          string               `json:"model"`
-	Messages       []openAiMessage      `json:"messages"`
-	Temperature    float32              `json:"temperature"`
-	ResponseFormat openAiResponseFormat `json:"response_format"`
-}
-
-type openAiResponseFormat struct {
-	Type       string          `json:"type"`
-	JSONSchema json.RawMessage `json:"json_schema"`
-}
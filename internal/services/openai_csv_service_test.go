@@ -1,74 +1,35 @@
 package services
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	"solback/internal/llmfake"
 )
 
 func TestOpenAiCsvServiceParseAuctionResults(t *testing.T) {
 	payload := AuctionPayload{
 		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
 		Participants: 34,
-		Headers:      []string{"Region", "Technology", "Total Volume Auctionned"},
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
 		Rows: [][]string{
-			{"Region1", "Tech1", "1"},
+			{"Region1", "Tech1", "1", "1", "0,3"},
 		},
 	}
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/v1/chat/completions" {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		var req openAiStructuredRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		if req.ResponseFormat.Type != "json_schema" {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		var schema struct {
-			Name string `json:"name"`
-		}
-		if err := json.Unmarshal(req.ResponseFormat.JSONSchema, &schema); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		if schema.Name != "auction_results" {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		if len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content, payload.SourceFile) {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-
-		resp := openAiChatResponse{
-			Choices: []openAiChoice{
-				{Message: openAiResponseMessage{Content: `{"source_file":"20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx","participants":34,"rows":[{"region":"Region1","technology":"Tech1","total_volume_auctioned":1,"total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3}]}`}},
-			},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer server.Close()
+	fake, server := newFakeLLMServer(t, llmfake.Options{APIKey: "test-key"})
 
 	logWriter := &stubLogWriter{}
-	service, err := NewOpenAiCsvService("test-key", logWriter, server.Client(), server.URL)
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), &stubLLMCache{}, nil, "csv-model", nil, logWriter)
 	if err != nil {
 		t.Fatalf("NewOpenAiCsvService: %v", err)
 	}
@@ -86,63 +47,55 @@ func TestOpenAiCsvServiceParseAuctionResults(t *testing.T) {
 	if result.Rows[0].Month != 8 {
 		t.Fatalf("month = %v, want 8", result.Rows[0].Month)
 	}
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Model != "csv-model" || calls[0].Schema != "auction_results" || !strings.Contains(calls[0].Prompt, payload.SourceFile) {
+		t.Fatalf("calls = %+v, want one auction_results call for csv-model", calls)
+	}
+	if result.PromptName != PromptAuctionResults || result.PromptVersion != "v1" {
+		t.Fatalf("prompt = %s@%s, want auction_results@v1", result.PromptName, result.PromptVersion)
+	}
 	if len(logWriter.entries) == 0 {
 		t.Fatalf("expected log entries")
 	}
-	var hasFilename bool
+	var hasFilename, hasPrompt bool
 	for _, entry := range logWriter.entries {
-		if entry.message != nil && strings.Contains(*entry.message, "source_file="+payload.SourceFile) {
+		if entry.message == nil {
+			continue
+		}
+		if strings.Contains(*entry.message, "source_file="+payload.SourceFile) {
 			hasFilename = true
-			break
+		}
+		if strings.Contains(*entry.message, "prompt=auction_results@v1") {
+			hasPrompt = true
 		}
 	}
 	if !hasFilename {
 		t.Fatalf("expected log entry with source_file")
 	}
+	if !hasPrompt {
+		t.Fatalf("expected log entry with prompt version")
+	}
 }
 
 func TestOpenAiCsvServiceParseAuctionResultsPartial(t *testing.T) {
 	payload := AuctionPayload{
 		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
 		Participants: 34,
-		Headers:      []string{"Region", "Technology", "Total Volume Auctionned"},
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
 	}
 	rows := make([][]string, csvMaxRowsPerRequest+1)
 	for i := range rows {
-		rows[i] = []string{"Region1", "Tech1", "1"}
+		rows[i] = []string{"Region1", "Tech1", "1", "1", "0,3"}
 	}
 	payload.Rows = rows
 
-	var calls int32
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/v1/chat/completions" {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		}
-
-		call := atomic.AddInt32(&calls, 1)
-		if call == 1 {
-			resp := openAiChatResponse{
-				Choices: []openAiChoice{
-					{Message: openAiResponseMessage{Content: `{"source_file":"20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx","participants":34,"rows":[{"region":"Region1","technology":"Tech1","total_volume_auctioned":1,"total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3}]}`}},
-				},
-			}
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(resp)
-			return
-		}
-
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(`{"error":"boom"}`))
-	}))
-	defer server.Close()
+	_, server := newFakeLLMServer(t, llmfake.Options{
+		APIKey: "test-key",
+		Faults: []llmfake.Fault{{Status: http.StatusInternalServerError, Match: `"batch":2}`}},
+	})
 
 	logWriter := &stubLogWriter{}
-	service, err := NewOpenAiCsvService("test-key", logWriter, server.Client(), server.URL)
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), &stubLLMCache{}, nil, "csv-model", nil, logWriter)
 	if err != nil {
 		t.Fatalf("NewOpenAiCsvService: %v", err)
 	}
@@ -151,8 +104,8 @@ func TestOpenAiCsvServiceParseAuctionResultsPartial(t *testing.T) {
 	if err == nil {
 		t.Fatalf("expected error")
 	}
-	if len(result.Rows) != 1 {
-		t.Fatalf("rows = %d, want 1", len(result.Rows))
+	if len(result.Rows) != csvMaxRowsPerRequest {
+		t.Fatalf("rows = %d, want the %d rows of the first batch", len(result.Rows), csvMaxRowsPerRequest)
 	}
 	if result.Rows[0].Year != 2025 {
 		t.Fatalf("year = %v, want 2025", result.Rows[0].Year)
@@ -164,3 +117,334 @@ func TestOpenAiCsvServiceParseAuctionResultsPartial(t *testing.T) {
 		t.Fatalf("source_file = %q, want %q", result.SourceFile, payload.SourceFile)
 	}
 }
+
+func TestOpenAiCsvServiceParseAuctionResultsConcurrentOrder(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+	}
+	batches := 4
+	for i := 0; i < csvMaxRowsPerRequest*(batches-1)+1; i++ {
+		payload.Rows = append(payload.Rows, []string{fmt.Sprintf("Region%d", i/csvMaxRowsPerRequest), "Tech1", "1", "1", "0,3"})
+	}
+
+	fake, err := llmfake.New(llmfake.Options{})
+	if err != nil {
+		t.Fatalf("llmfake.New: %v", err)
+	}
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&peak)
+			if current <= seen || atomic.CompareAndSwapInt32(&peak, seen, current) {
+				break
+			}
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var batch int
+		for i := 1; i <= batches; i++ {
+			if strings.Contains(string(body), fmt.Sprintf(`\"batch\":%d}`, i)) {
+				batch = i
+			}
+		}
+		// Later batches answer first so ordering cannot rely on completion order.
+		time.Sleep(time.Duration(batches-batch) * 15 * time.Millisecond)
+		fake.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	pool, err := NewBatchPool(batches)
+	if err != nil {
+		t.Fatalf("NewBatchPool: %v", err)
+	}
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), &stubLLMCache{}, nil, "csv-model", pool, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewOpenAiCsvService: %v", err)
+	}
+
+	result, err := service.ParseAuctionResults(context.Background(), payload, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != len(payload.Rows) {
+		t.Fatalf("rows = %d, want %d", len(result.Rows), len(payload.Rows))
+	}
+	for i, row := range result.Rows {
+		if row.Region != payload.Rows[i][0] {
+			t.Fatalf("row %d region = %q, want batch order", i, row.Region)
+		}
+	}
+	if peak < 2 {
+		t.Fatalf("peak in-flight = %d, want concurrent batches", peak)
+	}
+}
+
+func TestPlanCsvBatchesSplitsByTokenEstimate(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+	}
+	longRegion := strings.Repeat("Auvergne-Rhone-Alpes ", 20)
+	for i := 0; i < 100; i++ {
+		payload.Rows = append(payload.Rows, []string{fmt.Sprintf("%s%d", longRegion, i), "Tech1", "1", "1", "0,3"})
+	}
+
+	batches, estimate, err := planCsvBatches(testPromptTemplate(t, PromptAuctionResults), payload, csvMaxRowsPerRequest, csvMaxTokenEstimate)
+	if err != nil {
+		t.Fatalf("planCsvBatches: %v", err)
+	}
+	if len(batches) < 2 {
+		t.Fatalf("batches = %d, want the rows split by token budget", len(batches))
+	}
+	if estimate > csvMaxTokenEstimate {
+		t.Fatalf("estimate = %d, want at most %d", estimate, csvMaxTokenEstimate)
+	}
+	var rows int
+	for index, batch := range batches {
+		if len(batch) == 0 {
+			t.Fatalf("batch %d is empty", index)
+		}
+		if got := batch[0][0]; got != payload.Rows[rows][0] {
+			t.Fatalf("batch %d starts with %q, want row %d", index, got, rows)
+		}
+		rows += len(batch)
+	}
+	if rows != len(payload.Rows) {
+		t.Fatalf("rows = %d, want %d", rows, len(payload.Rows))
+	}
+
+	payload.Rows = [][]string{{strings.Repeat("x", csvMaxTokenEstimate*charsPerToken), "Tech1", "1", "0,3"}}
+	if _, _, err := planCsvBatches(testPromptTemplate(t, PromptAuctionResults), payload, csvMaxRowsPerRequest, csvMaxTokenEstimate); err == nil {
+		t.Fatalf("expected error for a single row over the budget")
+	}
+}
+
+func TestOpenAiCsvServiceSplitsOnContextLengthError(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+	}
+	for i := 0; i < 5; i++ {
+		payload.Rows = append(payload.Rows, []string{fmt.Sprintf("Region%d", i), "Tech1", "1", "1", "0,3"})
+	}
+
+	half := payload
+	half.Rows = payload.Rows[:2]
+	prompt, err := buildCsvPrompt(testPromptTemplate(t, PromptAuctionResults), half, 1)
+	if err != nil {
+		t.Fatalf("buildCsvPrompt: %v", err)
+	}
+	fake, server := newFakeLLMServer(t, llmfake.Options{
+		APIKey:          "test-key",
+		MaxPromptTokens: llmfake.EstimateTokens(prompt),
+	})
+
+	logWriter := &stubLogWriter{}
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), &stubLLMCache{}, nil, "csv-model", nil, logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiCsvService: %v", err)
+	}
+
+	result, err := service.ParseAuctionResults(context.Background(), payload, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != len(payload.Rows) {
+		t.Fatalf("rows = %d, want %d", len(result.Rows), len(payload.Rows))
+	}
+	for i, row := range result.Rows {
+		if row.Region != payload.Rows[i][0] {
+			t.Fatalf("row %d region = %q, want %q", i, row.Region, payload.Rows[i][0])
+		}
+	}
+	// 5 rows fail, 2 succeed, 3 fail, then 1 and 2 succeed.
+	if fake.Requests() != 5 {
+		t.Fatalf("calls = %d, want 5", fake.Requests())
+	}
+
+	var splits, calibrated int
+	for _, entry := range logWriter.entries {
+		if entry.message == nil {
+			continue
+		}
+		if strings.Contains(*entry.message, "context length exceeded, splitting") {
+			splits++
+		}
+		if entry.outcome == LogOutcomeSuccess && strings.Contains(*entry.message, "batch=1.2.2 ") && strings.Contains(*entry.message, " prompt_tokens=") {
+			calibrated++
+		}
+	}
+	if splits != 2 {
+		t.Fatalf("split logs = %d, want 2", splits)
+	}
+	if calibrated != 1 {
+		t.Fatalf("expected estimate vs actual log for batch 1.2.2")
+	}
+}
+
+func TestIsContextLengthError(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"openai":    {err: &HTTPStatusError{StatusCode: http.StatusBadRequest, Body: `{"error":{"code":"context_length_exceeded"}}`}, want: true},
+		"wrapped":   {err: fmt.Errorf("complete: %w", &HTTPStatusError{StatusCode: http.StatusRequestEntityTooLarge, Body: "prompt is too long"}), want: true},
+		"other_400": {err: &HTTPStatusError{StatusCode: http.StatusBadRequest, Body: "invalid schema"}, want: false},
+		"server":    {err: &HTTPStatusError{StatusCode: http.StatusInternalServerError, Body: "context length"}, want: false},
+		"plain":     {err: fmt.Errorf("context length"), want: false},
+	}
+	for name, tc := range cases {
+		if got := isContextLengthError(tc.err); got != tc.want {
+			t.Fatalf("%s: isContextLengthError = %t, want %t", name, got, tc.want)
+		}
+	}
+}
+
+func TestOpenAiCsvServiceQuarantinesHallucinatedRows(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+		Rows: [][]string{
+			{"Region1", "Tech1", "1", "1", "0,3"},
+			{"Region2", "Tech1", "2", "2", "0,4"},
+		},
+	}
+
+	_, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "auction_results", `{"source_file":"`+payload.SourceFile+`","participants":34,"rows":[`+
+		`{"region":"Region1","technology":"Tech1","total_volume_auctioned":1,"total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3},`+
+		`{"region":"Region2","technology":"Tech1","total_volume_auctioned":2,"total_volume_sold":2,"weighted_avg_price_eur_per_mwh":0.45},`+
+		`{"region":"Region3","technology":"Tech1","total_volume_auctioned":3,"total_volume_sold":3,"weighted_avg_price_eur_per_mwh":0.5}]}`)})
+
+	logWriter := &stubLogWriter{}
+	cache := &stubLLMCache{}
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), cache, nil, "csv-model", nil, logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiCsvService: %v", err)
+	}
+
+	result, err := service.ParseAuctionResults(context.Background(), payload, nil)
+	if !errors.Is(err, errRowsQuarantined) {
+		t.Fatalf("ParseAuctionResults err = %v, want errRowsQuarantined", err)
+	}
+	if len(cache.entries) != 0 {
+		t.Fatalf("cache entries = %d, want the unverified reply left uncached", len(cache.entries))
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Region != "Region1" || result.Rows[0].Year != 2025 {
+		t.Fatalf("rows = %+v, want only Region1", result.Rows)
+	}
+	if len(result.Quarantined) != 2 {
+		t.Fatalf("quarantined = %d, want 2", len(result.Quarantined))
+	}
+
+	var rowLogs int
+	var summary string
+	for _, entry := range logWriter.entries {
+		if entry.message == nil {
+			continue
+		}
+		if strings.Contains(*entry.message, "source_file="+payload.SourceFile+" batch=1 row=") && strings.Contains(*entry.message, "quarantined") {
+			rowLogs++
+		}
+		if strings.Contains(*entry.message, " verify ") {
+			summary = *entry.message
+			if entry.outcome != LogOutcomeFail {
+				t.Fatalf("verify outcome = %q, want %q", entry.outcome, LogOutcomeFail)
+			}
+		}
+	}
+	if rowLogs != 2 {
+		t.Fatalf("quarantine logs = %d, want 2", rowLogs)
+	}
+	if !strings.Contains(summary, "sent=2 returned=3 verified=1 quarantined=2") {
+		t.Fatalf("verify summary = %q", summary)
+	}
+}
+
+func TestOpenAiCsvServiceLogsSchemaViolations(t *testing.T) {
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+		Rows:         [][]string{{"Region1", "Tech1", "1", "1", "0,3"}},
+	}
+
+	_, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "auction_results", `{"source_file":"`+payload.SourceFile+`","participants":34,"rows":[{"region":"Region1","technology":"Tech1","total_volume_auctioned":"1","total_volume_sold":1,"weighted_avg_price_eur_per_mwh":0.3}]}`)})
+
+	logWriter := &stubLogWriter{}
+	cache := &stubLLMCache{}
+	service, err := NewOpenAiCsvService(newTestLLMClient(t, server), cache, nil, "csv-model", nil, logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiCsvService: %v", err)
+	}
+
+	if _, err := service.ParseAuctionResults(context.Background(), payload, nil); err == nil {
+		t.Fatalf("expected schema error")
+	}
+	if len(cache.entries) != 0 {
+		t.Fatalf("cache entries = %d, want invalid response not cached", len(cache.entries))
+	}
+
+	var logged bool
+	for _, entry := range logWriter.entries {
+		if entry.action == LogActionOpenAICSVParse && entry.outcome == LogOutcomeFail && entry.message != nil &&
+			strings.Contains(*entry.message, "$.rows[0].total_volume_auctioned: expected number, got string") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Fatalf("expected schema violation path in %s log", LogActionOpenAICSVParse)
+	}
+}
+
+func TestOpenAiCsvServiceAgainstFakeServer(t *testing.T) {
+	fake, server := newFakeLLMServer(t, llmfake.Options{Faults: []llmfake.Fault{{Status: http.StatusTooManyRequests, Times: 1}}})
+
+	logWriter := &stubLogWriter{}
+	retrier, err := NewRetrier(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, nil, logWriter)
+	if err != nil {
+		t.Fatalf("NewRetrier: %v", err)
+	}
+	client, err := NewOpenAiCompatibleClient("", server.URL, 0, server.Client(), retrier, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+	service, err := NewOpenAiCsvService(client, &stubLLMCache{}, nil, "csv-model", nil, logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiCsvService: %v", err)
+	}
+
+	payload := AuctionPayload{
+		SourceFile:   "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx",
+		Participants: 34,
+		Headers:      []string{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price"},
+		Rows: [][]string{
+			{"Normandie", "Hydraulique", "1 200", "1 100", "0,41"},
+			{"Bretagne", "Eolien", "300", "-", "0,38"},
+		},
+	}
+	result, err := service.ParseAuctionResults(context.Background(), payload, nil)
+	if err != nil {
+		t.Fatalf("ParseAuctionResults: %v", err)
+	}
+	if len(result.Rows) != 2 || len(result.Quarantined) != 0 {
+		t.Fatalf("rows = %d quarantined = %d, want 2 verified rows", len(result.Rows), len(result.Quarantined))
+	}
+	if result.Rows[0].TotalVolumeAuctioned != 1200 || result.Rows[1].TotalVolumeSold != 0 || result.Rows[1].WeightedAvgPriceEurPerMwh != 0.38 {
+		t.Fatalf("rows = %+v", result.Rows)
+	}
+	if fake.Requests() != 2 {
+		t.Fatalf("fake requests = %d, want a retry after the simulated 429", fake.Requests())
+	}
+}
@@ -4,79 +4,197 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"solback/internal/llmfake"
 )
 
-func TestOpenAiServiceExtractZipLinkSuccess(t *testing.T) {
+func TestOpenAiServiceExtractZipLinksSuccess(t *testing.T) {
+	html := `<table><tr><td>GO 2023-2024 Global Results</td><td><a href="https://example.com/old.zip">zip</a></td></tr><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/file.zip">zip</a></td></tr></table>`
+
+	content := `{"error":"","links":[{"period":"2023-2024","description":"GO 2023-2024 Global Results","link":"https://example.com/old.zip"},{"period":"2024-2025","description":"GO 2024-2025 Global Results","link":"https://example.com/file.zip"}]}`
+	fake, server := newFakeLLMServer(t, llmfake.Options{APIKey: "test-key", FixturesDir: writeLLMFixture(t, "zip_links", content)})
+
+	logWriter := &stubLogWriter{}
+	service, err := NewOpenAiService(newTestLLMClient(t, server), &stubLLMCache{}, nil, "html-model", logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiService: %v", err)
+	}
+
+	result, err := service.ExtractZipLinks(context.Background(), html, nil)
+	if err != nil {
+		t.Fatalf("ExtractZipLinks: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("result error = %q, want empty", result.Error)
+	}
+	if len(result.Links) != 2 {
+		t.Fatalf("links = %d, want 2", len(result.Links))
+	}
+	if result.Links[0].Link != "https://example.com/file.zip" || result.Links[0].Period != "2024-2025" {
+		t.Fatalf("first link = %+v, want newest season first", result.Links[0])
+	}
+	if result.Links[1].Link != "https://example.com/old.zip" {
+		t.Fatalf("second link = %q, want %q", result.Links[1].Link, "https://example.com/old.zip")
+	}
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Model != "html-model" || calls[0].Schema != "zip_links" || !strings.Contains(calls[0].Prompt, "GO 2024-2025 Global Results") {
+		t.Fatalf("calls = %+v, want one zip_links call for html-model", calls)
+	}
+
+	if len(logWriter.entries) != 2 {
+		t.Fatalf("log entries = %d, want 2", len(logWriter.entries))
+	}
+	if logWriter.entries[0].action != LogActionLLMCache || !strings.HasPrefix(*logWriter.entries[0].message, "miss ") {
+		t.Fatalf("first log = %+v, want cache miss", logWriter.entries[0])
+	}
+	if logWriter.entries[1].outcome != LogOutcomeSuccess {
+		t.Fatalf("log outcome = %q, want %q", logWriter.entries[1].outcome, LogOutcomeSuccess)
+	}
+}
+
+func TestOpenAiServiceExtractZipLinksUsesCache(t *testing.T) {
 	html := `<table><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/file.zip">zip</a></td></tr></table>`
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/v1/chat/completions" {
-			w.WriteHeader(http.StatusNotFound)
-			return
+	fake, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "zip_links", `{"error":"","links":[{"period":"2024-2025","description":"GO 2024-2025 Global Results","link":"https://example.com/file.zip"}]}`)})
+
+	logWriter := &stubLogWriter{}
+	cache := &stubLLMCache{}
+	service, err := NewOpenAiService(newTestLLMClient(t, server), cache, nil, "html-model", logWriter)
+	if err != nil {
+		t.Fatalf("NewOpenAiService: %v", err)
+	}
+
+	eventID := "event-1"
+	for i := 0; i < 2; i++ {
+		result, err := service.ExtractZipLinks(context.Background(), html, &eventID)
+		if err != nil {
+			t.Fatalf("ExtractZipLinks %d: %v", i, err)
 		}
-		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
-			w.WriteHeader(http.StatusUnauthorized)
-			return
+		if len(result.Links) != 1 || result.Links[0].Link != "https://example.com/file.zip" {
+			t.Fatalf("links = %+v, want cached link", result.Links)
 		}
+	}
+	if fake.Requests() != 1 {
+		t.Fatalf("api calls = %d, want 1", fake.Requests())
+	}
+	if len(cache.entries) != 1 {
+		t.Fatalf("cache entries = %d, want 1", len(cache.entries))
+	}
 
-		var req openAiChatRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			return
+	var hits, misses int
+	for _, entry := range logWriter.entries {
+		if entry.action != LogActionLLMCache {
+			continue
 		}
-		if len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content, "GO 2024-2025 Global Results") {
-			w.WriteHeader(http.StatusBadRequest)
-			return
+		if entry.eventID == nil || *entry.eventID != eventID {
+			t.Fatalf("cache log eventID = %v, want %q", entry.eventID, eventID)
 		}
-
-		resp := openAiChatResponse{
-			Choices: []openAiChoice{
-				{Message: openAiResponseMessage{Content: `{"error":"","period":"2024-2025","description":"GO 2024-2025 Global Results","link":"https://example.com/file.zip"}`}},
-			},
+		switch {
+		case strings.HasPrefix(*entry.message, "hit "):
+			hits++
+		case strings.HasPrefix(*entry.message, "miss "):
+			misses++
 		}
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(resp)
-	}))
-	defer server.Close()
+	}
+	if hits != 1 || misses != 1 {
+		t.Fatalf("hits = %d misses = %d, want 1 and 1", hits, misses)
+	}
+}
 
-	logWriter := &stubLogWriter{}
-	service, err := NewOpenAiService("test-key", logWriter, server.Client(), server.URL)
+func TestOpenAiServiceExtractZipLinksSkipsInvalidCacheEntry(t *testing.T) {
+	html := `<table><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/file.zip">zip</a></td></tr></table>`
+	content := `{"error":"","links":[{"period":"2024-2025","description":"GO 2024-2025 Global Results","link":"https://example.com/file.zip"}]}`
+
+	fake, server := newFakeLLMServer(t, llmfake.Options{FixturesDir: writeLLMFixture(t, "zip_links", content)})
+
+	tables, err := ExtractZipTables(html)
+	if err != nil {
+		t.Fatalf("ExtractZipTables: %v", err)
+	}
+	prompt, err := buildOpenAiPrompt(testPromptTemplate(t, PromptZipLinks), strings.Join(tables, "\n"))
+	if err != nil {
+		t.Fatalf("buildOpenAiPrompt: %v", err)
+	}
+	key := llmCacheKey(LLMRequest{Model: "html-model", Prompt: prompt, Schema: json.RawMessage(zipLinksSchema)}, LLMEndpoint{BaseURL: server.URL})
+	cache := &stubLLMCache{entries: map[string]string{key: `{"error":"","links":[]}`}}
+	service, err := NewOpenAiService(newTestLLMClient(t, server), cache, nil, "html-model", &stubLogWriter{})
 	if err != nil {
 		t.Fatalf("NewOpenAiService: %v", err)
 	}
 
-	result, err := service.ExtractZipLink(context.Background(), html, nil)
-	if err != nil {
-		t.Fatalf("ExtractZipLink: %v", err)
+	if _, err := service.ExtractZipLinks(context.Background(), html, nil); err != nil {
+		t.Fatalf("ExtractZipLinks: %v", err)
 	}
-	if result.Error != "" {
-		t.Fatalf("result error = %q, want empty", result.Error)
+	if fake.Requests() != 1 {
+		t.Fatalf("api calls = %d, want 1", fake.Requests())
 	}
-	if result.Link != "https://example.com/file.zip" {
-		t.Fatalf("link = %q, want %q", result.Link, "https://example.com/file.zip")
+	if cache.entries[key] != content {
+		t.Fatalf("cache entry = %q, want refreshed response", cache.entries[key])
 	}
+}
 
-	if len(logWriter.entries) != 1 {
-		t.Fatalf("log entries = %d, want 1", len(logWriter.entries))
-	}
-	if logWriter.entries[0].outcome != LogOutcomeSuccess {
-		t.Fatalf("log outcome = %q, want %q", logWriter.entries[0].outcome, LogOutcomeSuccess)
+func TestOpenAiServiceExtractZipLinksRetriesInvalidOutput(t *testing.T) {
+	html := `<table><tr><td>GO 2024-2025 Global Results</td><td><a href="https://example.com/file.zip">zip</a></td></tr></table>`
+	content := `{"error":"","links":[{"period":"2024-2025","description":"GO 2024-2025 Global Results","link":"https://example.com/file.zip"}]}`
+
+	for _, tc := range []struct {
+		name    string
+		invalid int
+		calls   int
+		wantErr bool
+	}{
+		{name: "recovers", invalid: 1, calls: 2},
+		{name: "exhausted", invalid: 5, calls: zipLinksContentAttempts, wantErr: true},
+	} {
+		fake, server := newFakeLLMServer(t, llmfake.Options{
+			FixturesDir: writeLLMFixture(t, "zip_links", content),
+			Faults:      []llmfake.Fault{{Content: `{"error":"","links":[]}`, Times: tc.invalid}},
+		})
+
+		cache := &stubLLMCache{}
+		service, err := NewOpenAiService(newTestLLMClient(t, server), cache, nil, "html-model", &stubLogWriter{})
+		if err != nil {
+			t.Fatalf("%s NewOpenAiService: %v", tc.name, err)
+		}
+
+		result, err := service.ExtractZipLinks(context.Background(), html, nil)
+		if tc.wantErr != (err != nil) {
+			t.Fatalf("%s err = %v, want error %v", tc.name, err, tc.wantErr)
+		}
+		if fake.Requests() != tc.calls {
+			t.Fatalf("%s api calls = %d, want %d", tc.name, fake.Requests(), tc.calls)
+		}
+		if tc.wantErr {
+			if len(cache.entries) != 0 {
+				t.Fatalf("%s cache entries = %d, want none", tc.name, len(cache.entries))
+			}
+			continue
+		}
+		if len(result.Links) != 1 || len(cache.entries) != 1 {
+			t.Fatalf("%s links = %d cache entries = %d, want 1 and 1", tc.name, len(result.Links), len(cache.entries))
+		}
+		if cache.lookups != 1 {
+			t.Fatalf("%s cache lookups = %d, want retries to bypass the cache", tc.name, cache.lookups)
+		}
 	}
 }
 
-func TestOpenAiServiceExtractZipLinkEmptyHTML(t *testing.T) {
+func TestOpenAiServiceExtractZipLinksEmptyHTML(t *testing.T) {
 	logWriter := &stubLogWriter{}
-	service, err := NewOpenAiService("test-key", logWriter, http.DefaultClient, "https://example.com")
+	client, err := NewOpenAiCompatibleClient("test-key", "https://example.com", 0, http.DefaultClient, nil, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+	service, err := NewOpenAiService(client, &stubLLMCache{}, nil, "html-model", logWriter)
 	if err != nil {
 		t.Fatalf("NewOpenAiService: %v", err)
 	}
 
-	result, err := service.ExtractZipLink(context.Background(), "", nil)
+	result, err := service.ExtractZipLinks(context.Background(), "", nil)
 	if err != nil {
-		t.Fatalf("ExtractZipLink: %v", err)
+		t.Fatalf("ExtractZipLinks: %v", err)
 	}
 	if result.Error != "EMPTY_HTML" {
 		t.Fatalf("error = %q, want %q", result.Error, "EMPTY_HTML")
@@ -88,3 +206,43 @@ func TestOpenAiServiceExtractZipLinkEmptyHTML(t *testing.T) {
 		t.Fatalf("log outcome = %q, want %q", logWriter.entries[0].outcome, LogOutcomeFail)
 	}
 }
+
+func TestValidateOpenAiResult(t *testing.T) {
+	valid := OpenAiLinkCandidate{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}
+
+	if err := validateOpenAiResult(OpenAiResult{Links: []OpenAiLinkCandidate{valid}}); err != nil {
+		t.Fatalf("valid result: %v", err)
+	}
+	if err := validateOpenAiResult(OpenAiResult{Error: "NO_RESULTS"}); err != nil {
+		t.Fatalf("no results: %v", err)
+	}
+	if err := validateOpenAiResult(OpenAiResult{Error: "NO_RESULTS", Links: []OpenAiLinkCandidate{valid}}); err == nil {
+		t.Fatalf("expected error for error result with links")
+	}
+	if err := validateOpenAiResult(OpenAiResult{}); err == nil {
+		t.Fatalf("expected error for empty links")
+	}
+	if err := validateOpenAiResult(OpenAiResult{Links: []OpenAiLinkCandidate{valid, valid}}); err == nil {
+		t.Fatalf("expected error for duplicate links")
+	}
+	invalid := valid
+	invalid.Link = "http://example.com/file.zip"
+	if err := validateOpenAiResult(OpenAiResult{Links: []OpenAiLinkCandidate{valid, invalid}}); err == nil {
+		t.Fatalf("expected error for non-https link")
+	}
+}
+
+func TestParseOpenAiResultValidatesSchema(t *testing.T) {
+	if _, err := parseOpenAiResult("```json\n{\"error\":\"\",\"links\":[{\"period\":\"2024-2025\",\"description\":\"GO results\",\"link\":\"https://example.com/file.zip\"}]}\n```"); err != nil {
+		t.Fatalf("parseOpenAiResult valid: %v", err)
+	}
+
+	_, err := parseOpenAiResult(`{"error":"","links":[{"period":"2024-2025","description":"GO results","link":"https://example.com/file.zip","confidence":0.9}]}`)
+	if err == nil || !strings.Contains(err.Error(), `$.links[0]: unknown property "confidence"`) {
+		t.Fatalf("err = %v, want unknown property at $.links[0]", err)
+	}
+	_, err = parseOpenAiResult(`{"error":"invalid request"}`)
+	if err == nil || !strings.Contains(err.Error(), `$: missing required property "links"`) {
+		t.Fatalf("err = %v, want missing links", err)
+	}
+}
@@ -9,19 +9,26 @@ import (
 	"path"
 	"strings"
 
+	"solback/internal/models"
+
 	"github.com/google/uuid"
 )
 
+var ErrLinkNotInSource = errors.New("link not in source")
+
 type PipelineService struct {
-	sourceService SourceProvider
-	htmlService   HtmlFetcher
-	openAiService OpenAiExtractor
-	zipService    ZipDownloader
-	xlsxService   ZipProcessor
-	fileService   ProcessedFileTracker
-	csvService    AuctionParser
-	dataService   DataStorer
-	logService    LogWriter
+	sourceService  SourceProvider
+	htmlService    HtmlFetcher
+	openAiService  OpenAiExtractor
+	zipService     ZipDownloader
+	xlsxService    ZipProcessor
+	fileService    ProcessedFileTracker
+	linkService    LinkRecorder
+	csvService     AuctionParser
+	payloadService PayloadRecorder
+	dataService    DataStorer
+	workbookPool   *BatchPool
+	logService     LogWriter
 }
 
 func NewPipelineService(
@@ -31,8 +38,11 @@ func NewPipelineService(
 	zipService ZipDownloader,
 	xlsxService ZipProcessor,
 	fileService ProcessedFileTracker,
+	linkService LinkRecorder,
 	csvService AuctionParser,
+	payloadService PayloadRecorder,
 	dataService DataStorer,
+	workbookPool *BatchPool,
 	logService LogWriter,
 ) (*PipelineService, error) {
 	if sourceService == nil {
@@ -53,26 +63,42 @@ func NewPipelineService(
 	if fileService == nil {
 		return nil, errors.New("processed file service is nil")
 	}
+	if linkService == nil {
+		return nil, errors.New("discovered link service is nil")
+	}
 	if csvService == nil {
 		return nil, errors.New("csv service is nil")
 	}
+	if payloadService == nil {
+		return nil, errors.New("payload service is nil")
+	}
 	if dataService == nil {
 		return nil, errors.New("data service is nil")
 	}
 	if logService == nil {
 		return nil, errors.New("log service is nil")
 	}
+	if workbookPool == nil {
+		sequential, err := NewBatchPool(1)
+		if err != nil {
+			return nil, err
+		}
+		workbookPool = sequential
+	}
 
 	return &PipelineService{
-		sourceService: sourceService,
-		htmlService:   htmlService,
-		openAiService: openAiService,
-		zipService:    zipService,
-		xlsxService:   xlsxService,
-		fileService:   fileService,
-		csvService:    csvService,
-		dataService:   dataService,
-		logService:    logService,
+		sourceService:  sourceService,
+		htmlService:    htmlService,
+		openAiService:  openAiService,
+		zipService:     zipService,
+		xlsxService:    xlsxService,
+		fileService:    fileService,
+		linkService:    linkService,
+		csvService:     csvService,
+		payloadService: payloadService,
+		dataService:    dataService,
+		workbookPool:   workbookPool,
+		logService:     logService,
 	}, nil
 }
 
@@ -98,9 +124,15 @@ func (s *PipelineService) Refresh(ctx context.Context) error {
 	if s.fileService == nil {
 		return errors.New("processed file service is nil")
 	}
+	if s.linkService == nil {
+		return errors.New("discovered link service is nil")
+	}
 	if s.csvService == nil {
 		return errors.New("csv service is nil")
 	}
+	if s.payloadService == nil {
+		return errors.New("payload service is nil")
+	}
 	if s.dataService == nil {
 		return errors.New("data service is nil")
 	}
@@ -121,136 +153,423 @@ func (s *PipelineService) Refresh(ctx context.Context) error {
 		return fmt.Errorf("get sources: %w", err)
 	}
 
+	var summary refreshSummary
 	var refreshErr error
 	for _, source := range sources {
-		if source.URL == "" {
-			failMsg := "source url is empty"
-			_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
-			if refreshErr == nil {
-				refreshErr = errors.New("source url is empty")
-			}
-			continue
+		if err := s.refreshSource(ctx, source, eventID, &summary); err != nil && refreshErr == nil {
+			refreshErr = err
 		}
+	}
 
-		result, err := s.htmlService.Fetch(ctx, source.URL)
-		if err != nil {
-			failMsg := fmt.Sprintf("fetch url=%s: %v", source.URL, err)
-			_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
-			if refreshErr == nil {
-				refreshErr = fmt.Errorf("fetch url=%s: %w", source.URL, err)
-			}
-			continue
+	summaryOutcome := LogOutcomeSuccess
+	if refreshErr != nil {
+		summaryOutcome = LogOutcomeFail
+	}
+	summaryMsg := fmt.Sprintf("pipeline refresh finished sources=%d unchanged=%d workbooks=%d rows=%d quarantined=%d", len(sources), summary.unchanged, summary.workbooks, summary.rows, summary.quarantined)
+	_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, summaryOutcome, &summaryMsg)
+
+	return refreshErr
+}
+
+type refreshSummary struct {
+	unchanged   int
+	workbooks   int
+	rows        int
+	quarantined int
+}
+
+func (s *PipelineService) refreshSource(ctx context.Context, source models.Source, eventID string, summary *refreshSummary) error {
+	if source.URL == "" {
+		failMsg := "source url is empty"
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
+		return errors.New("source url is empty")
+	}
+
+	policy, err := NewEgressPolicy(source.URL, sourceAllowedHosts(source))
+	if err != nil {
+		failMsg := fmt.Sprintf("egress policy url=%s: %v", source.URL, err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
+		return fmt.Errorf("egress policy url=%s: %w", source.URL, err)
+	}
+	ctx = WithEgressPolicy(ctx, policy)
+
+	validators := sourceValidators(source)
+	result, err := s.htmlService.Fetch(ctx, source.URL, validators, &eventID)
+	if err != nil {
+		failMsg := fmt.Sprintf("fetch url=%s: %v", source.URL, err)
+		switch {
+		case errors.Is(err, ErrBodyTooLarge):
+			failMsg = fmt.Sprintf("html rejected reason=too_large url=%s: %v", source.URL, err)
+		case errors.Is(err, ErrUnexpectedContentType):
+			failMsg = fmt.Sprintf("html rejected reason=content_type url=%s: %v", source.URL, err)
+		case errors.Is(err, ErrEgressBlocked):
+			failMsg = fmt.Sprintf("html rejected reason=egress url=%s: %v", source.URL, err)
 		}
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
+		return fmt.Errorf("fetch url=%s: %w", source.URL, err)
+	}
+
+	if result.StatusCode == http.StatusNotModified {
+		summary.unchanged++
+		unchangedMsg := fmt.Sprintf("source unchanged url=%s reason=not_modified", source.URL)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeSuccess, &unchangedMsg)
+		return nil
+	}
+
+	var sourceErr error
+	outcome := LogOutcomeSuccess
+	if result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices {
+		outcome = LogOutcomeFail
+	}
+
+	resultMsg := fmt.Sprintf("url=%s status=%d", source.URL, result.StatusCode)
+	if logErr := s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, outcome, &resultMsg); logErr != nil {
+		sourceErr = fmt.Errorf("log retrieval result: %w", logErr)
+	}
 
-		outcome := LogOutcomeSuccess
-		if result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices {
-			outcome = LogOutcomeFail
+	if outcome == LogOutcomeFail {
+		if sourceErr == nil {
+			sourceErr = fmt.Errorf("request failed for %s", source.URL)
 		}
+		return sourceErr
+	}
+
+	fetched := HtmlValidators{ETag: result.ETag, LastModified: result.LastModified, ContentHash: result.ContentHash}
+	if validators.ContentHash != "" && validators.ContentHash == result.ContentHash {
+		summary.unchanged++
+		unchangedMsg := fmt.Sprintf("source unchanged url=%s reason=content_hash content_hash=%s", source.URL, result.ContentHash)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeSuccess, &unchangedMsg)
+		s.saveValidators(ctx, source, fetched, eventID)
+		return sourceErr
+	}
+
+	htmlBody := result.Body
+	if resolved, err := ResolveZipLinks(source.URL, result.Body); err == nil {
+		htmlBody = resolved
+	} else {
+		failMsg := fmt.Sprintf("resolve zip links: %v", err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+	}
 
-		resultMsg := fmt.Sprintf("url=%s status=%d", source.URL, result.StatusCode)
-		if logErr := s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, outcome, &resultMsg); logErr != nil && refreshErr == nil {
-			refreshErr = fmt.Errorf("log retrieval result: %w", logErr)
+	openAiResult, err := s.openAiService.ExtractZipLinks(ctx, htmlBody, &eventID)
+	if err != nil {
+		if sourceErr == nil {
+			sourceErr = fmt.Errorf("openai extract: %w", err)
+		}
+		return sourceErr
+	}
+	if openAiResult.Error != "" {
+		if sourceErr == nil {
+			sourceErr = fmt.Errorf("openai extract returned error: %s", openAiResult.Error)
 		}
+		return sourceErr
+	}
 
-		if outcome == LogOutcomeFail {
-			if refreshErr == nil {
-				refreshErr = fmt.Errorf("request failed for %s", source.URL)
-			}
+	candidates, err := s.verifyLinks(ctx, source.URL, htmlBody, openAiResult.Links, eventID)
+	if err != nil && sourceErr == nil {
+		sourceErr = err
+	}
+
+	for _, link := range s.selectLinks(ctx, source.URL, candidates, eventID) {
+		ingested, err := s.processZip(ctx, source.URL, link, eventID, summary)
+		if err != nil && sourceErr == nil {
+			sourceErr = err
+		}
+		if !ingested {
 			continue
 		}
-
-		htmlBody := result.Body
-		if resolved, err := ResolveZipLinks(source.URL, result.Body); err == nil {
-			htmlBody = resolved
-		} else {
-			failMsg := fmt.Sprintf("resolve zip links: %v", err)
+		if err := s.linkService.MarkIngested(ctx, link); err != nil {
+			failMsg := fmt.Sprintf("mark link ingested link=%s: %v", link, err)
 			_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+			if sourceErr == nil {
+				sourceErr = err
+			}
 		}
+	}
 
-		openAiResult, err := s.openAiService.ExtractZipLink(ctx, htmlBody, &eventID)
-		if err != nil {
-			if refreshErr == nil {
-				refreshErr = fmt.Errorf("openai extract: %w", err)
-			}
+	if sourceErr == nil {
+		s.saveValidators(ctx, source, fetched, eventID)
+	}
+	return sourceErr
+}
+
+func (s *PipelineService) saveValidators(ctx context.Context, source models.Source, validators HtmlValidators, eventID string) {
+	if source.ID == "" {
+		return
+	}
+	if err := s.sourceService.UpdateValidators(ctx, source.ID, validators); err != nil {
+		failMsg := fmt.Sprintf("save source validators url=%s: %v", source.URL, err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRetrieval, LogOutcomeFail, &failMsg)
+	}
+}
+
+func sourceValidators(source models.Source) HtmlValidators {
+	var validators HtmlValidators
+	if source.ETag != nil {
+		validators.ETag = *source.ETag
+	}
+	if source.LastModified != nil {
+		validators.LastModified = *source.LastModified
+	}
+	if source.ContentHash != nil {
+		validators.ContentHash = *source.ContentHash
+	}
+	return validators
+}
+
+func (s *PipelineService) verifyLinks(ctx context.Context, sourceURL string, htmlBody string, candidates []OpenAiLinkCandidate, eventID string) ([]OpenAiLinkCandidate, error) {
+	anchors, err := ExtractAnchors(sourceURL, htmlBody)
+	if err != nil {
+		failMsg := fmt.Sprintf("extract anchors source=%s: %v", sourceURL, err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+	}
+
+	verified := make([]OpenAiLinkCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		key, err := normalizeLink(candidate.Link)
+		anchor, ok := anchors[key]
+		if err != nil || !ok {
+			failMsg := fmt.Sprintf("link rejected link=%s source=%s", candidate.Link, sourceURL)
+			_ = s.logService.CreateLog(ctx, &eventID, LogActionLinkNotInSource, LogOutcomeFail, &failMsg)
 			continue
 		}
-		if openAiResult.Error != "" && refreshErr == nil {
-			refreshErr = fmt.Errorf("openai extract returned error: %s", openAiResult.Error)
+		candidate.Evidence = anchor.Context
+		verified = append(verified, candidate)
+	}
+
+	if len(verified) == 0 && len(candidates) > 0 {
+		return nil, fmt.Errorf("%w: rejected=%d source=%s", ErrLinkNotInSource, len(candidates), sourceURL)
+	}
+	return verified, nil
+}
+
+func (s *PipelineService) selectLinks(ctx context.Context, sourceURL string, candidates []OpenAiLinkCandidate, eventID string) []string {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	recorded, err := s.linkService.RecordLinks(ctx, sourceURL, candidates)
+	if err != nil {
+		failMsg := fmt.Sprintf("record discovered links source=%s: %v", sourceURL, err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+		return []string{candidates[0].Link}
+	}
+
+	ingested := make(map[string]bool, len(recorded))
+	for _, entry := range recorded {
+		ingested[entry.Link] = entry.IngestedAt != nil
+	}
+
+	links := make([]string, 0, len(candidates))
+	for index, candidate := range candidates {
+		if index == 0 || !ingested[candidate.Link] {
+			links = append(links, candidate.Link)
 		}
+	}
+
+	msg := fmt.Sprintf("discovered links=%d selected=%d source=%s", len(candidates), len(links), sourceURL)
+	_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeSuccess, &msg)
+
+	return links
+}
+
+func (s *PipelineService) processZip(ctx context.Context, sourceURL string, link string, eventID string, summary *refreshSummary) (bool, error) {
+	var zipErr error
+	zipName, nameErr := extractZipFilename(link)
+	if nameErr != nil {
+		failMsg := fmt.Sprintf("extract zip filename: %v", nameErr)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+		zipErr = nameErr
+	}
+
+	zipResult, err := s.zipService.Download(ctx, link, sourceURL, &eventID)
+	if err != nil {
+		if zipErr == nil {
+			zipErr = fmt.Errorf("zip download: %w", err)
+		}
+		return false, zipErr
+	}
 
-		zipName, nameErr := extractZipFilename(openAiResult.Link)
-		if nameErr != nil {
-			failMsg := fmt.Sprintf("extract zip filename: %v", nameErr)
+	if zipName != "" && zipResult.ContentHash != "" {
+		zipStatus, err := s.fileService.ZipStatus(ctx, zipName, zipResult.ContentHash)
+		if err != nil {
+			failMsg := fmt.Sprintf("check processed zip filename=%s: %v", zipName, err)
 			_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
-			if refreshErr == nil {
-				refreshErr = nameErr
-			}
-		} else {
-			processed, err := s.fileService.IsProcessed(ctx, zipName)
+			return false, err
+		}
+		switch zipStatus {
+		case FileStatusUnchanged:
+			skipMsg := fmt.Sprintf("skip processed zip filename=%s", zipName)
+			_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeSuccess, &skipMsg)
+			return true, nil
+		case FileStatusRevised:
+			revisionMsg := fmt.Sprintf("zip revision detected filename=%s content_hash=%s", zipName, zipResult.ContentHash)
+			_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRevision, LogOutcomeSuccess, &revisionMsg)
+		}
+	}
+
+	payloads, err := s.xlsxService.ExtractAuctionPayloads(ctx, zipResult.Bytes)
+	if err != nil {
+		failMsg := fmt.Sprintf("extract xlsx: %v", err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+		if zipErr == nil {
+			zipErr = fmt.Errorf("extract xlsx: %w", err)
+		}
+		return false, zipErr
+	}
+	successMsg := fmt.Sprintf("extracted xlsx files=%d url=%s", len(payloads), zipResult.URL)
+	_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeSuccess, &successMsg)
+
+	workbookFailed := false
+	var jobs []workbookJob
+	for _, payload := range payloads {
+		status := FileStatusNew
+		if payload.ContentHash != "" {
+			status, err = s.fileService.WorkbookStatus(ctx, payload.SourceFile, payload.ContentHash)
 			if err != nil {
-				failMsg := fmt.Sprintf("check processed zip filename=%s: %v", zipName, err)
+				failMsg := fmt.Sprintf("check processed workbook source_file=%s: %v", payload.SourceFile, err)
 				_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
-				if refreshErr == nil {
-					refreshErr = err
+				if zipErr == nil {
+					zipErr = err
 				}
+				workbookFailed = true
+				continue
 			}
-			if processed {
-				skipMsg := fmt.Sprintf("skip processed zip filename=%s", zipName)
+			if status == FileStatusUnchanged {
+				skipMsg := fmt.Sprintf("skip processed workbook source_file=%s zip_filename=%s", payload.SourceFile, zipName)
 				_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeSuccess, &skipMsg)
 				continue
 			}
-		}
-
-		zipResult, err := s.zipService.Download(ctx, openAiResult.Link, source.URL, &eventID)
-		if err != nil {
-			if refreshErr == nil {
-				refreshErr = fmt.Errorf("zip download: %w", err)
+			if status == FileStatusRevised {
+				revisionMsg := fmt.Sprintf("workbook revision detected source_file=%s zip_filename=%s content_hash=%s", payload.SourceFile, zipName, payload.ContentHash)
+				_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRevision, LogOutcomeSuccess, &revisionMsg)
 			}
-			continue
 		}
 
-		payloads, err := s.xlsxService.ExtractAuctionPayloads(ctx, zipResult.Bytes)
-		if err != nil {
-			failMsg := fmt.Sprintf("extract xlsx: %v", err)
+		if err := s.payloadService.SavePayload(ctx, payload, zipName); err != nil {
+			failMsg := fmt.Sprintf("save payload source_file=%s: %v", payload.SourceFile, err)
 			_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
-			if refreshErr == nil {
-				refreshErr = fmt.Errorf("extract xlsx: %w", err)
+		}
+
+		jobs = append(jobs, workbookJob{payload: payload, status: status})
+	}
+
+	parsedJobs := s.parseWorkbooks(ctx, jobs, eventID)
+	for index, job := range jobs {
+		payload := job.payload
+		parsed, err := parsedJobs[index].result, parsedJobs[index].err
+		complete := err == nil && len(parsed.Quarantined) == 0
+		if !complete {
+			if zipErr == nil && err != nil {
+				zipErr = fmt.Errorf("openai csv parse: %w", err)
+			}
+			if zipErr == nil {
+				zipErr = fmt.Errorf("source_file=%s quarantined rows=%d", payload.SourceFile, len(parsed.Quarantined))
 			}
+			workbookFailed = true
+		}
+		summary.quarantined += len(parsed.Quarantined)
+		if len(parsed.Rows) == 0 {
+			workbookFailed = true
+			continue
+		}
+		if job.status == FileStatusRevised && !complete {
+			skipMsg := fmt.Sprintf("keep stored rows source_file=%s rows=%d quarantined=%d: revised workbook parsed incompletely", payload.SourceFile, len(parsed.Rows), len(parsed.Quarantined))
+			_ = s.logService.CreateLog(ctx, &eventID, LogActionDataRevision, LogOutcomeFail, &skipMsg)
 			continue
 		}
-		successMsg := fmt.Sprintf("extracted xlsx files=%d url=%s", len(payloads), zipResult.URL)
-		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeSuccess, &successMsg)
-
-		storedRows := 0
-		for _, payload := range payloads {
-			parsed, err := s.csvService.ParseAuctionResults(ctx, payload, &eventID)
-			if err != nil && refreshErr == nil {
-				refreshErr = fmt.Errorf("openai csv parse: %w", err)
+		parsed.ZipName = zipName
+
+		var details []AuctionDetailResults
+		for _, detail := range payload.Details {
+			detailResults, err := ParseAuctionDetails(detail)
+			if err != nil {
+				failMsg := fmt.Sprintf("source_file=%s sheet=%s parse details: %v", detail.SourceFile, detail.Sheet, err)
+				_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+				if zipErr == nil {
+					zipErr = fmt.Errorf("parse auction details: %w", err)
+				}
 			}
-			if len(parsed.Rows) == 0 {
+			if len(detailResults.Rows) == 0 {
 				continue
 			}
-			if _, err := s.dataService.StoreAuctionResults(ctx, parsed, &eventID); err != nil {
-				if refreshErr == nil {
-					refreshErr = fmt.Errorf("store auction results: %w", err)
+			detailResults.ZipName = zipName
+			details = append(details, detailResults)
+		}
+
+		if job.status == FileStatusRevised {
+			_, err = s.dataService.ReplaceAuctionResults(ctx, parsed, details, &eventID)
+		} else {
+			_, err = s.dataService.StoreAuctionResults(ctx, parsed, &eventID)
+		}
+		if err != nil {
+			if zipErr == nil {
+				zipErr = fmt.Errorf("store auction results: %w", err)
+			}
+			workbookFailed = true
+			continue
+		}
+		summary.workbooks++
+		summary.rows += len(parsed.Rows)
+
+		if job.status != FileStatusRevised {
+			for _, detailResults := range details {
+				if _, err := s.dataService.StoreAuctionDetails(ctx, detailResults, &eventID); err != nil && zipErr == nil {
+					zipErr = fmt.Errorf("store auction details: %w", err)
 				}
-			} else {
-				storedRows += len(parsed.Rows)
 			}
 		}
 
-		if storedRows > 0 && zipName != "" {
-			if err := s.fileService.MarkProcessed(ctx, zipName); err != nil {
-				failMsg := fmt.Sprintf("mark processed zip filename=%s: %v", zipName, err)
+		if complete && zipName != "" && payload.ContentHash != "" {
+			if err := s.fileService.MarkWorkbookProcessed(ctx, zipName, payload.SourceFile, payload.ContentHash); err != nil {
+				failMsg := fmt.Sprintf("mark processed workbook source_file=%s: %v", payload.SourceFile, err)
 				_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
-				if refreshErr == nil {
-					refreshErr = err
+				if zipErr == nil {
+					zipErr = err
 				}
+				workbookFailed = true
 			}
 		}
 	}
 
-	return refreshErr
+	if workbookFailed || zipName == "" {
+		return false, zipErr
+	}
+	if err := s.fileService.MarkProcessed(ctx, zipName, zipResult.ContentHash); err != nil {
+		failMsg := fmt.Sprintf("mark processed zip filename=%s: %v", zipName, err)
+		_ = s.logService.CreateLog(ctx, &eventID, LogActionZipProcess, LogOutcomeFail, &failMsg)
+		if zipErr == nil {
+			zipErr = err
+		}
+		return false, zipErr
+	}
+
+	return true, zipErr
+}
+
+type workbookJob struct {
+	payload AuctionPayload
+	status  FileStatus
+}
+
+type workbookParse struct {
+	result AuctionResults
+	err    error
+}
+
+func (s *PipelineService) parseWorkbooks(ctx context.Context, jobs []workbookJob, eventID string) []workbookParse {
+	parsed := make([]workbookParse, len(jobs))
+
+	errs := s.workbookPool.Run(ctx, len(jobs), func(ctx context.Context, index int) error {
+		result, err := s.csvService.ParseAuctionResults(ctx, jobs[index].payload, &eventID)
+		parsed[index].result = result
+		return err
+	})
+	for index, err := range errs {
+		parsed[index].err = err
+	}
+
+	return parsed
 }
 
 func extractZipFilename(link string) (string, error) {
@@ -270,3 +589,10 @@ func extractZipFilename(link string) (string, error) {
 	}
 	return base, nil
 }
+
+func sourceAllowedHosts(source models.Source) []string {
+	if source.AllowedHosts == nil {
+		return nil
+	}
+	return strings.Split(*source.AllowedHosts, ",")
+}
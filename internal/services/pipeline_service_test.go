@@ -3,8 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"solback/internal/models"
 )
@@ -12,6 +16,7 @@ import (
 type stubSourceService struct {
 	sources []models.Source
 	err     error
+	saved   map[string]HtmlValidators
 }
 
 func (s stubSourceService) GetSources(ctx context.Context) ([]models.Source, error) {
@@ -21,12 +26,23 @@ func (s stubSourceService) GetSources(ctx context.Context) ([]models.Source, err
 	return s.sources, nil
 }
 
+func (s stubSourceService) UpdateValidators(ctx context.Context, sourceID string, validators HtmlValidators) error {
+	if s.saved != nil {
+		s.saved[sourceID] = validators
+	}
+	return nil
+}
+
 type stubHtmlFetcher struct {
-	results map[string]HtmlResult
-	errs    map[string]error
+	results    map[string]HtmlResult
+	errs       map[string]error
+	validators map[string]HtmlValidators
 }
 
-func (s stubHtmlFetcher) Fetch(ctx context.Context, url string) (HtmlResult, error) {
+func (s stubHtmlFetcher) Fetch(ctx context.Context, url string, validators HtmlValidators, eventID *string) (HtmlResult, error) {
+	if s.validators != nil {
+		s.validators[url] = validators
+	}
 	if err, ok := s.errs[url]; ok {
 		return HtmlResult{URL: url}, err
 	}
@@ -39,6 +55,7 @@ func (s stubHtmlFetcher) Fetch(ctx context.Context, url string) (HtmlResult, err
 type stubOpenAiExtractor struct {
 	result OpenAiResult
 	err    error
+	calls  *int
 }
 
 type stubZipDownloader struct {
@@ -46,7 +63,10 @@ type stubZipDownloader struct {
 	err    error
 }
 
-func (s stubOpenAiExtractor) ExtractZipLink(ctx context.Context, html string, eventID *string) (OpenAiResult, error) {
+func (s stubOpenAiExtractor) ExtractZipLinks(ctx context.Context, html string, eventID *string) (OpenAiResult, error) {
+	if s.calls != nil {
+		*s.calls++
+	}
 	if s.err != nil {
 		return OpenAiResult{}, s.err
 	}
@@ -73,30 +93,94 @@ func (s stubZipProcessor) ExtractAuctionPayloads(ctx context.Context, zipBytes [
 }
 
 type stubProcessedFileTracker struct {
-	processed map[string]bool
+	zips      map[string]string
+	processed map[string]string
 	err       error
 	marked    []string
 }
 
-func (s *stubProcessedFileTracker) IsProcessed(ctx context.Context, filename string) (bool, error) {
+func (s *stubProcessedFileTracker) ZipStatus(ctx context.Context, filename string, contentHash string) (FileStatus, error) {
 	if s.err != nil {
-		return false, s.err
+		return "", s.err
 	}
-	if s.processed == nil {
-		return false, nil
-	}
-	return s.processed[filename], nil
+	return stubFileStatus(s.zips, filename, contentHash), nil
 }
 
-func (s *stubProcessedFileTracker) MarkProcessed(ctx context.Context, filename string) error {
+func (s *stubProcessedFileTracker) MarkProcessed(ctx context.Context, filename string, contentHash string) error {
 	if s.err != nil {
 		return s.err
 	}
 	s.marked = append(s.marked, filename)
+	if s.zips == nil {
+		s.zips = map[string]string{}
+	}
+	s.zips[filename] = contentHash
+	return nil
+}
+
+func (s *stubProcessedFileTracker) WorkbookStatus(ctx context.Context, workbookFilename string, contentHash string) (FileStatus, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	return stubFileStatus(s.processed, workbookFilename, contentHash), nil
+}
+
+func (s *stubProcessedFileTracker) MarkWorkbookProcessed(ctx context.Context, zipFilename string, workbookFilename string, contentHash string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.marked = append(s.marked, zipFilename+"/"+workbookFilename)
 	if s.processed == nil {
-		s.processed = map[string]bool{}
+		s.processed = map[string]string{}
 	}
-	s.processed[filename] = true
+	s.processed[workbookFilename] = contentHash
+	return nil
+}
+
+func stubFileStatus(hashes map[string]string, name string, contentHash string) FileStatus {
+	stored, ok := hashes[name]
+	if !ok {
+		return FileStatusNew
+	}
+	if stored == contentHash {
+		return FileStatusUnchanged
+	}
+	return FileStatusRevised
+}
+
+type stubLinkRecorder struct {
+	ingested map[string]bool
+	err      error
+	recorded []string
+	evidence []string
+}
+
+func (s *stubLinkRecorder) RecordLinks(ctx context.Context, sourceURL string, candidates []OpenAiLinkCandidate) ([]models.DiscoveredLink, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	links := make([]models.DiscoveredLink, 0, len(candidates))
+	for _, candidate := range candidates {
+		s.recorded = append(s.recorded, candidate.Link)
+		s.evidence = append(s.evidence, candidate.Evidence)
+		entry := models.DiscoveredLink{SourceURL: sourceURL, Link: candidate.Link, Period: candidate.Period, Description: candidate.Description}
+		if s.ingested[candidate.Link] {
+			now := time.Now()
+			entry.IngestedAt = &now
+		}
+		links = append(links, entry)
+	}
+	return links, nil
+}
+
+func (s *stubLinkRecorder) MarkIngested(ctx context.Context, link string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.ingested == nil {
+		s.ingested = map[string]bool{}
+	}
+	s.ingested[link] = true
 	return nil
 }
 
@@ -112,16 +196,66 @@ func (s stubAuctionParser) ParseAuctionResults(ctx context.Context, payload Auct
 	return s.result, nil
 }
 
-type stubDataStorer struct {
-	count int
+type concurrentAuctionParser struct {
+	running int32
+	peak    int32
+}
+
+func (s *concurrentAuctionParser) ParseAuctionResults(ctx context.Context, payload AuctionPayload, eventID *string) (AuctionResults, error) {
+	current := atomic.AddInt32(&s.running, 1)
+	for {
+		seen := atomic.LoadInt32(&s.peak)
+		if current <= seen || atomic.CompareAndSwapInt32(&s.peak, seen, current) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(&s.running, -1)
+	return AuctionResults{SourceFile: payload.SourceFile}, nil
+}
+
+type stubPayloadRecorder struct {
+	saved []string
 	err   error
 }
 
-func (s *stubDataStorer) StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (int, error) {
+func (s *stubPayloadRecorder) SavePayload(ctx context.Context, payload AuctionPayload, zipName string) error {
+	s.saved = append(s.saved, payload.SourceFile)
+	return s.err
+}
+
+type stubDataStorer struct {
+	count           int
+	replaced        int
+	replacedDetails int
+	detailCount     int
+	err             error
+}
+
+func (s *stubDataStorer) StoreAuctionResults(ctx context.Context, results AuctionResults, eventID *string) (UpsertCounts, error) {
 	if s.err != nil {
-		return 0, s.err
+		return UpsertCounts{}, s.err
 	}
 	s.count += len(results.Rows)
+	return UpsertCounts{Inserted: len(results.Rows)}, nil
+}
+
+func (s *stubDataStorer) ReplaceAuctionResults(ctx context.Context, results AuctionResults, details []AuctionDetailResults, eventID *string) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.replaced += len(results.Rows)
+	for _, detail := range details {
+		s.replacedDetails += len(detail.Rows)
+	}
+	return len(results.Rows), nil
+}
+
+func (s *stubDataStorer) StoreAuctionDetails(ctx context.Context, results AuctionDetailResults, eventID *string) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.detailCount += len(results.Rows)
 	return len(results.Rows), nil
 }
 
@@ -132,22 +266,26 @@ func TestPipelineServiceRefresh(t *testing.T) {
 	}
 	htmlFetcher := stubHtmlFetcher{
 		results: map[string]HtmlResult{
-			"https://example.com/ok":   {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table>"},
+			"https://example.com/ok":   {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
 			"https://example.com/fail": {URL: "https://example.com/fail", StatusCode: http.StatusInternalServerError, Body: "fail"},
 		},
 	}
 
 	logWriter := &stubLogWriter{}
 	dataStorer := &stubDataStorer{}
+	payloadRecorder := &stubPayloadRecorder{}
 	service, err := NewPipelineService(
 		stubSourceService{sources: sources},
 		htmlFetcher,
-		stubOpenAiExtractor{result: OpenAiResult{Link: "https://example.com/file.zip"}},
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
 		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}},
 		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
 		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
 		stubAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		payloadRecorder,
 		dataStorer,
+		nil,
 		logWriter,
 	)
 	if err != nil {
@@ -157,6 +295,9 @@ func TestPipelineServiceRefresh(t *testing.T) {
 	if err := service.Refresh(context.Background()); err == nil {
 		t.Fatalf("Refresh: expected error")
 	}
+	if len(payloadRecorder.saved) != 1 || payloadRecorder.saved[0] != "file.xlsx" {
+		t.Fatalf("saved payloads = %v, want [file.xlsx]", payloadRecorder.saved)
+	}
 
 	if len(logWriter.entries) < 3 {
 		t.Fatalf("log entries = %d, want at least 3", len(logWriter.entries))
@@ -197,8 +338,11 @@ func TestPipelineServiceRefreshSourceError(t *testing.T) {
 		stubZipDownloader{},
 		stubZipProcessor{},
 		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
 		stubAuctionParser{},
+		&stubPayloadRecorder{},
 		&stubDataStorer{},
+		nil,
 		logWriter,
 	)
 	if err != nil {
@@ -222,7 +366,7 @@ func TestPipelineServiceRefreshStoresPartialCsvResults(t *testing.T) {
 	}
 	htmlFetcher := stubHtmlFetcher{
 		results: map[string]HtmlResult{
-			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table>"},
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
 		},
 	}
 
@@ -231,15 +375,23 @@ func TestPipelineServiceRefreshStoresPartialCsvResults(t *testing.T) {
 	service, err := NewPipelineService(
 		stubSourceService{sources: sources},
 		htmlFetcher,
-		stubOpenAiExtractor{result: OpenAiResult{Link: "https://example.com/file.zip"}},
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
 		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}},
 		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
 		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
 		stubAuctionParser{
-			result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}},
-			err:    errors.New("partial parse failure"),
+			result: AuctionResults{
+				SourceFile:   "file.xlsx",
+				Participants: 1,
+				Rows:         []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}},
+				Quarantined:  []QuarantinedRow{{Index: 1, SourceRow: -1, Reason: "no source row"}},
+			},
+			err: errors.New("partial parse failure"),
 		},
+		&stubPayloadRecorder{},
 		dataStorer,
+		nil,
 		logWriter,
 	)
 	if err != nil {
@@ -252,31 +404,151 @@ func TestPipelineServiceRefreshStoresPartialCsvResults(t *testing.T) {
 	if dataStorer.count == 0 {
 		t.Fatalf("expected data rows to be stored")
 	}
+	last := logWriter.entries[len(logWriter.entries)-1]
+	if last.message == nil || !strings.Contains(*last.message, "pipeline refresh finished sources=1 unchanged=0 workbooks=1 rows=1 quarantined=1") {
+		t.Fatalf("summary log = %v, want refresh summary", last.message)
+	}
+	if last.outcome != LogOutcomeFail {
+		t.Fatalf("summary outcome = %q, want %q", last.outcome, LogOutcomeFail)
+	}
 }
 
-func TestPipelineServiceRefreshSkipsProcessedZip(t *testing.T) {
+func TestPipelineServiceRefreshRetriesPartiallyParsedWorkbooks(t *testing.T) {
 	sources := []models.Source{
 		{URL: "https://example.com/ok"},
 	}
 	htmlFetcher := stubHtmlFetcher{
 		results: map[string]HtmlResult{
-			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table>"},
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
 		},
 	}
 
-	processed := &stubProcessedFileTracker{processed: map[string]bool{"file.zip": true}}
+	processed := &stubProcessedFileTracker{}
+	links := &stubLinkRecorder{}
+	dataStorer := &stubDataStorer{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
+		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip"), ContentHash: "zip-hash"}},
+		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", ContentHash: "hash-1", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
+		processed,
+		links,
+		stubAuctionParser{
+			result: AuctionResults{
+				SourceFile:   "file.xlsx",
+				Participants: 1,
+				Rows:         []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}},
+			},
+			err: errors.New("batch 2 failed"),
+		},
+		&stubPayloadRecorder{},
+		dataStorer,
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	for run := 1; run <= 2; run++ {
+		if err := service.Refresh(context.Background()); err == nil {
+			t.Fatalf("Refresh run %d: expected error", run)
+		}
+		if dataStorer.count != run {
+			t.Fatalf("run %d stored = %d, want the workbook parsed again", run, dataStorer.count)
+		}
+	}
+	if len(processed.marked) != 0 {
+		t.Fatalf("marked = %v, want nothing marked after a partial parse", processed.marked)
+	}
+	if links.ingested["https://example.com/file.zip"] {
+		t.Fatalf("expected link not to be marked ingested")
+	}
+}
+
+func TestPipelineServiceRefreshStoresDetails(t *testing.T) {
+	sources := []models.Source{
+		{URL: "https://example.com/ok"},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
+		},
+	}
+
+	sourceFile := "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx"
+	payload := AuctionPayload{
+		SourceFile:   sourceFile,
+		Participants: 1,
+		Headers:      []string{"Region", "Technology"},
+		Rows:         [][]string{{"Region", "Tech"}},
+		Details: []AuctionDetailPayload{
+			{
+				SourceFile: sourceFile,
+				Sheet:      "Details",
+				Headers:    []string{"Region", "Technology", "Bid Volume", "Bid Price", "Awarded"},
+				Rows:       [][]string{{"Region", "Tech", "10", "0,5", "Yes"}, {"Region", "Tech", "5", "0,2", "No"}},
+			},
+		},
+	}
+
+	dataStorer := &stubDataStorer{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
+		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}},
+		stubZipProcessor{payloads: []AuctionPayload{payload}},
+		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
+		stubAuctionParser{result: AuctionResults{SourceFile: sourceFile, Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		&stubPayloadRecorder{},
+		dataStorer,
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if dataStorer.detailCount != 2 {
+		t.Fatalf("detail rows = %d, want 2", dataStorer.detailCount)
+	}
+}
+
+func TestPipelineServiceRefreshSkipsProcessedWorkbooks(t *testing.T) {
+	sources := []models.Source{
+		{URL: "https://example.com/ok"},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
+		},
+	}
+
+	processed := &stubProcessedFileTracker{processed: map[string]string{"old.xlsx": "hash-old"}}
 	logWriter := &stubLogWriter{}
 	dataStorer := &stubDataStorer{}
 	zipDownloader := stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}}
 	service, err := NewPipelineService(
 		stubSourceService{sources: sources},
 		htmlFetcher,
-		stubOpenAiExtractor{result: OpenAiResult{Link: "https://example.com/file.zip"}},
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
 		zipDownloader,
-		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
+		stubZipProcessor{payloads: []AuctionPayload{
+			{SourceFile: "old.xlsx", ContentHash: "hash-old", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}},
+			{SourceFile: "new.xlsx", ContentHash: "hash-new", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}},
+		}},
 		processed,
-		stubAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		&stubLinkRecorder{},
+		stubAuctionParser{result: AuctionResults{SourceFile: "new.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		&stubPayloadRecorder{},
 		dataStorer,
+		nil,
 		logWriter,
 	)
 	if err != nil {
@@ -286,10 +558,480 @@ func TestPipelineServiceRefreshSkipsProcessedZip(t *testing.T) {
 	if err := service.Refresh(context.Background()); err != nil {
 		t.Fatalf("Refresh: %v", err)
 	}
+	if dataStorer.count != 1 {
+		t.Fatalf("stored rows = %d, want 1", dataStorer.count)
+	}
+	if processed.processed["new.xlsx"] != "hash-new" {
+		t.Fatalf("expected new workbook to be marked processed")
+	}
+	if len(processed.marked) != 2 || processed.marked[0] != "file.zip/new.xlsx" || processed.marked[1] != "file.zip" {
+		t.Fatalf("marked = %v, want [file.zip/new.xlsx file.zip]", processed.marked)
+	}
+
+	dataStorer.count = 0
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh second: %v", err)
+	}
 	if dataStorer.count != 0 {
-		t.Fatalf("expected no data rows stored")
+		t.Fatalf("stored rows on second refresh = %d, want 0", dataStorer.count)
+	}
+}
+
+func TestPipelineServiceRefreshReplacesRevisedWorkbooks(t *testing.T) {
+	sources := []models.Source{
+		{URL: "https://example.com/ok"},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
+		},
+	}
+
+	processed := &stubProcessedFileTracker{
+		zips:      map[string]string{"file.zip": "zip-old"},
+		processed: map[string]string{"August_2025_old.xlsx": "hash-old"},
+	}
+	logWriter := &stubLogWriter{}
+	dataStorer := &stubDataStorer{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
+		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip"), ContentHash: "zip-new"}},
+		stubZipProcessor{payloads: []AuctionPayload{
+			{SourceFile: "August_2025_old.xlsx", ContentHash: "hash-fixed", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}, Details: []AuctionDetailPayload{{
+				SourceFile: "August_2025_old.xlsx",
+				Sheet:      "Detailed Results",
+				Headers:    []string{"Region", "Technology", "Bid Volume", "Bid Price", "Awarded"},
+				Rows:       [][]string{{"Region", "Tech", "1", "2", "Yes"}},
+			}}},
+		}},
+		processed,
+		&stubLinkRecorder{},
+		stubAuctionParser{result: AuctionResults{SourceFile: "August_2025_old.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 2}}}},
+		&stubPayloadRecorder{},
+		dataStorer,
+		nil,
+		logWriter,
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if dataStorer.replaced != 1 || dataStorer.count != 0 {
+		t.Fatalf("replaced = %d stored = %d, want 1 and 0", dataStorer.replaced, dataStorer.count)
+	}
+	if dataStorer.replacedDetails != 1 || dataStorer.detailCount != 0 {
+		t.Fatalf("replaced details = %d stored details = %d, want details replaced with the results", dataStorer.replacedDetails, dataStorer.detailCount)
+	}
+	if processed.processed["August_2025_old.xlsx"] != "hash-fixed" {
+		t.Fatalf("workbook hash = %q, want hash-fixed", processed.processed["August_2025_old.xlsx"])
+	}
+	if processed.zips["file.zip"] != "zip-new" {
+		t.Fatalf("zip hash = %q, want zip-new", processed.zips["file.zip"])
+	}
+
+	revisions := 0
+	for _, entry := range logWriter.entries {
+		if entry.action == LogActionDataRevision {
+			revisions++
+		}
+	}
+	if revisions != 2 {
+		t.Fatalf("revision logs = %d, want 2", revisions)
+	}
+
+	dataStorer.replaced = 0
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh second: %v", err)
+	}
+	if dataStorer.replaced != 0 || dataStorer.count != 0 {
+		t.Fatalf("expected unchanged zip to be skipped, replaced = %d stored = %d", dataStorer.replaced, dataStorer.count)
+	}
+}
+
+func TestPipelineServiceRefreshKeepsRevisedWorkbookOnQuarantine(t *testing.T) {
+	sources := []models.Source{
+		{URL: "https://example.com/ok"},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>"},
+		},
+	}
+
+	processed := &stubProcessedFileTracker{
+		zips:      map[string]string{"file.zip": "zip-old"},
+		processed: map[string]string{"old.xlsx": "hash-old"},
+	}
+	dataStorer := &stubDataStorer{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}},
+		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip"), ContentHash: "zip-new"}},
+		stubZipProcessor{payloads: []AuctionPayload{
+			{SourceFile: "old.xlsx", ContentHash: "hash-fixed", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}, {"Other", "Tech"}}},
+		}},
+		processed,
+		&stubLinkRecorder{},
+		stubAuctionParser{result: AuctionResults{
+			SourceFile:  "old.xlsx",
+			Rows:        []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 2}},
+			Quarantined: []QuarantinedRow{{Index: 1, SourceRow: 1, Reason: "total_volume_sold=3 not found in column 3 of source row"}},
+		}},
+		&stubPayloadRecorder{},
+		dataStorer,
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err == nil {
+		t.Fatalf("Refresh: expected quarantine error")
+	}
+	if dataStorer.replaced != 0 || dataStorer.count != 0 {
+		t.Fatalf("replaced = %d stored = %d, want stored rows left untouched", dataStorer.replaced, dataStorer.count)
 	}
 	if len(processed.marked) != 0 {
-		t.Fatalf("expected no new processed marks")
+		t.Fatalf("marked = %v, want nothing marked", processed.marked)
+	}
+	if processed.processed["old.xlsx"] != "hash-old" || processed.zips["file.zip"] != "zip-old" {
+		t.Fatalf("hashes = %v %v, want previous hashes kept", processed.processed, processed.zips)
+	}
+}
+
+func TestPipelineServiceRefreshBackfillsOlderSeasons(t *testing.T) {
+	sources := []models.Source{
+		{URL: "https://example.com/ok"},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<a href=\"/2025.zip\">2024-2025</a><a href=\"/2024.zip\">2023-2024</a><a href=\"/2023.zip\">2022-2023</a>"},
+		},
+	}
+
+	links := &stubLinkRecorder{ingested: map[string]bool{"https://example.com/2023.zip": true}}
+	processed := &stubProcessedFileTracker{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{
+			{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/2025.zip"},
+			{Period: "2023-2024", Description: "GO 2023-2024 results", Link: "https://example.com/2024.zip"},
+			{Period: "2022-2023", Description: "GO 2022-2023 results", Link: "https://example.com/2023.zip"},
+		}}},
+		stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}},
+		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
+		processed,
+		links,
+		stubAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		&stubPayloadRecorder{},
+		&stubDataStorer{},
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(links.recorded) != 3 {
+		t.Fatalf("recorded links = %d, want 3", len(links.recorded))
+	}
+	if len(processed.marked) != 2 || processed.marked[0] != "2025.zip" || processed.marked[1] != "2024.zip" {
+		t.Fatalf("processed zips = %v, want [2025.zip 2024.zip]", processed.marked)
+	}
+	if !links.ingested["https://example.com/2025.zip"] || !links.ingested["https://example.com/2024.zip"] {
+		t.Fatalf("ingested = %v, want newest and backfilled seasons marked", links.ingested)
+	}
+
+	processed.marked = nil
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh second: %v", err)
+	}
+	if len(processed.marked) != 1 || processed.marked[0] != "2025.zip" {
+		t.Fatalf("processed zips on second refresh = %v, want only newest season", processed.marked)
+	}
+}
+
+func TestPipelineServiceRefreshSkipsUnchangedSources(t *testing.T) {
+	etag := `"v1"`
+	hash := "same-hash"
+	sources := []models.Source{
+		{ID: "not-modified", URL: "https://example.com/etag", ETag: &etag},
+		{ID: "same-body", URL: "https://example.com/hash", ContentHash: &hash},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/etag": {URL: "https://example.com/etag", StatusCode: http.StatusNotModified},
+			"https://example.com/hash": {URL: "https://example.com/hash", StatusCode: http.StatusOK, Body: "<table></table>", ETag: `"v2"`, ContentHash: hash},
+		},
+		validators: map[string]HtmlValidators{},
+	}
+
+	var extractCalls int
+	saved := map[string]HtmlValidators{}
+	logWriter := &stubLogWriter{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources, saved: saved},
+		htmlFetcher,
+		stubOpenAiExtractor{err: errors.New("extractor must not run"), calls: &extractCalls},
+		stubZipDownloader{},
+		stubZipProcessor{},
+		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
+		stubAuctionParser{},
+		&stubPayloadRecorder{},
+		&stubDataStorer{},
+		nil,
+		logWriter,
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if extractCalls != 0 {
+		t.Fatalf("extract calls = %d, want 0 for unchanged sources", extractCalls)
+	}
+	if htmlFetcher.validators["https://example.com/etag"].ETag != etag {
+		t.Fatalf("validators = %+v, want stored etag sent", htmlFetcher.validators["https://example.com/etag"])
+	}
+	if got := saved["same-body"]; got.ETag != `"v2"` || got.ContentHash != hash {
+		t.Fatalf("saved validators = %+v, want refreshed etag", got)
+	}
+
+	var reasons []string
+	for _, entry := range logWriter.entries {
+		if entry.message != nil && strings.HasPrefix(*entry.message, "source unchanged") {
+			reasons = append(reasons, *entry.message)
+		}
+	}
+	if len(reasons) != 2 || !strings.Contains(reasons[0], "reason=not_modified") || !strings.Contains(reasons[1], "reason=content_hash") {
+		t.Fatalf("unchanged logs = %v", reasons)
+	}
+	last := logWriter.entries[len(logWriter.entries)-1]
+	if last.message == nil || !strings.Contains(*last.message, "sources=2 unchanged=2") {
+		t.Fatalf("summary log = %v, want unchanged count", last.message)
+	}
+}
+
+func TestPipelineServiceRefreshSavesValidatorsAfterSuccess(t *testing.T) {
+	sources := []models.Source{{ID: "source-id", URL: "https://example.com/ok"}}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<table></table><a href=\"/file.zip\">GO 2024-2025 results</a>", ETag: `"v1"`, LastModified: "Wed, 19 Nov 2025 10:00:00 GMT", ContentHash: "hash-1"},
+		},
+	}
+	links := OpenAiResult{Links: []OpenAiLinkCandidate{{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/file.zip"}}}
+
+	newService := func(extractor stubOpenAiExtractor, saved map[string]HtmlValidators) *PipelineService {
+		service, err := NewPipelineService(
+			stubSourceService{sources: sources, saved: saved},
+			htmlFetcher,
+			extractor,
+			stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}},
+			stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
+			&stubProcessedFileTracker{},
+			&stubLinkRecorder{},
+			stubAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+			&stubPayloadRecorder{},
+			&stubDataStorer{},
+			nil,
+			&stubLogWriter{},
+		)
+		if err != nil {
+			t.Fatalf("NewPipelineService: %v", err)
+		}
+		return service
+	}
+
+	saved := map[string]HtmlValidators{}
+	if err := newService(stubOpenAiExtractor{err: errors.New("llm down")}, saved).Refresh(context.Background()); err == nil {
+		t.Fatalf("Refresh: expected extract error")
+	}
+	if len(saved) != 0 {
+		t.Fatalf("saved = %v, want validators kept until the source is processed", saved)
+	}
+
+	if err := newService(stubOpenAiExtractor{result: links}, saved).Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	want := HtmlValidators{ETag: `"v1"`, LastModified: "Wed, 19 Nov 2025 10:00:00 GMT", ContentHash: "hash-1"}
+	if saved["source-id"] != want {
+		t.Fatalf("saved = %+v, want %+v", saved["source-id"], want)
+	}
+}
+
+func TestPipelineServiceRefreshRejectsLinksNotInSource(t *testing.T) {
+	sources := []models.Source{{ID: "source-id", URL: "https://example.com/ok"}}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<p>Results</p><ul><li>Season 83: <a href=\"/file.zip#download\"><span>GO 2024-2025</span>\n results</a></li></ul><a href=\"/other.pdf\">Rules</a>", ContentHash: "hash-1"},
+		},
+	}
+
+	links := &stubLinkRecorder{}
+	zips := stubZipDownloader{result: ZipResult{URL: "https://example.com/file.zip", StatusCode: http.StatusOK, Bytes: []byte("zip")}}
+	logWriter := &stubLogWriter{}
+	saved := map[string]HtmlValidators{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources, saved: saved},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{
+			{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://EXAMPLE.com:443/file.zip"},
+			{Period: "2023-2024", Description: "GO 2023-2024 results", Link: "https://example.com/injected.zip"},
+		}}},
+		zips,
+		stubZipProcessor{payloads: []AuctionPayload{{SourceFile: "file.xlsx", Participants: 1, Headers: []string{"Region", "Technology"}, Rows: [][]string{{"Region", "Tech"}}}}},
+		&stubProcessedFileTracker{},
+		links,
+		stubAuctionParser{result: AuctionResults{SourceFile: "file.xlsx", Participants: 1, Rows: []AuctionRow{{Year: 2025, Month: 8, Region: "Region", Technology: "Tech", TotalVolumeAuctioned: 1, TotalVolumeSold: 1, WeightedAvgPriceEurPerMwh: 1}}}},
+		&stubPayloadRecorder{},
+		&stubDataStorer{},
+		nil,
+		logWriter,
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(links.recorded) != 1 || links.recorded[0] != "https://EXAMPLE.com:443/file.zip" {
+		t.Fatalf("recorded = %v, want only the link found on the page", links.recorded)
+	}
+	if links.evidence[0] != "Season 83: GO 2024-2025 results" {
+		t.Fatalf("evidence = %q, want the text around the anchor", links.evidence[0])
+	}
+	if saved["source-id"].ContentHash != "hash-1" {
+		t.Fatalf("saved = %v, want validators saved while verified links remain", saved)
+	}
+
+	var rejected []string
+	for _, entry := range logWriter.entries {
+		if entry.action == LogActionLinkNotInSource && entry.outcome == LogOutcomeFail && entry.message != nil {
+			rejected = append(rejected, *entry.message)
+		}
+	}
+	if len(rejected) != 1 || !strings.Contains(rejected[0], "link=https://example.com/injected.zip") {
+		t.Fatalf("rejected logs = %v, want injected link", rejected)
+	}
+}
+
+func TestPipelineServiceRefreshFailsWhenNoLinkIsInSource(t *testing.T) {
+	sources := []models.Source{{ID: "source-id", URL: "https://example.com/ok"}}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/ok": {URL: "https://example.com/ok", StatusCode: http.StatusOK, Body: "<a href=\"/file.zip\">GO 2024-2025 results</a>", ContentHash: "hash-1"},
+		},
+	}
+
+	links := &stubLinkRecorder{}
+	saved := map[string]HtmlValidators{}
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources, saved: saved},
+		htmlFetcher,
+		stubOpenAiExtractor{result: OpenAiResult{Links: []OpenAiLinkCandidate{
+			{Period: "2024-2025", Description: "GO 2024-2025 results", Link: "https://example.com/injected.zip"},
+		}}},
+		stubZipDownloader{},
+		stubZipProcessor{},
+		&stubProcessedFileTracker{},
+		links,
+		stubAuctionParser{},
+		&stubPayloadRecorder{},
+		&stubDataStorer{},
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); !errors.Is(err, ErrLinkNotInSource) {
+		t.Fatalf("Refresh err = %v, want ErrLinkNotInSource", err)
+	}
+	if len(links.recorded) != 0 {
+		t.Fatalf("recorded = %v, want none", links.recorded)
+	}
+	if len(saved) != 0 {
+		t.Fatalf("saved = %v, want validators kept when every link is rejected", saved)
+	}
+}
+
+func TestPipelineServiceRefreshStopsForUnchangedSources(t *testing.T) {
+	etag := `"v1"`
+	hash := "page-hash"
+	sources := []models.Source{
+		{ID: "etag-id", URL: "https://example.com/etag", ETag: &etag},
+		{ID: "hash-id", URL: "https://example.com/hash", ContentHash: &hash},
+	}
+	htmlFetcher := stubHtmlFetcher{
+		results: map[string]HtmlResult{
+			"https://example.com/etag": {URL: "https://example.com/etag", StatusCode: http.StatusNotModified},
+			"https://example.com/hash": {URL: "https://example.com/hash", StatusCode: http.StatusOK, Body: "<html></html>", ContentHash: hash},
+		},
+	}
+
+	var extractCalls int
+	service, err := NewPipelineService(
+		stubSourceService{sources: sources},
+		htmlFetcher,
+		stubOpenAiExtractor{err: errors.New("extractor must not run"), calls: &extractCalls},
+		stubZipDownloader{err: errors.New("download must not run")},
+		stubZipProcessor{},
+		&stubProcessedFileTracker{},
+		&stubLinkRecorder{},
+		stubAuctionParser{},
+		&stubPayloadRecorder{},
+		&stubDataStorer{},
+		nil,
+		&stubLogWriter{},
+	)
+	if err != nil {
+		t.Fatalf("NewPipelineService: %v", err)
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if extractCalls != 0 {
+		t.Fatalf("extract calls = %d, want the pipeline to stop for unchanged pages", extractCalls)
+	}
+}
+
+func TestPipelineServiceParseWorkbooksBoundsConcurrency(t *testing.T) {
+	parser := &concurrentAuctionParser{}
+	pool, err := NewBatchPool(2)
+	if err != nil {
+		t.Fatalf("NewBatchPool: %v", err)
+	}
+	service := &PipelineService{csvService: parser, workbookPool: pool}
+
+	jobs := make([]workbookJob, 6)
+	for index := range jobs {
+		jobs[index].payload.SourceFile = fmt.Sprintf("file-%d.xlsx", index)
+	}
+	parsed := service.parseWorkbooks(context.Background(), jobs, "event-id")
+
+	if parser.peak > 2 {
+		t.Fatalf("peak = %d, want at most 2 workbooks parsed at once", parser.peak)
+	}
+	for index, result := range parsed {
+		if result.err != nil || result.result.SourceFile != jobs[index].payload.SourceFile {
+			t.Fatalf("parsed[%d] = %+v, want result for %s", index, result, jobs[index].payload.SourceFile)
+		}
 	}
 }
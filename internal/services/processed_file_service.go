@@ -11,6 +11,14 @@ import (
 	"gorm.io/gorm"
 )
 
+type FileStatus string
+
+const (
+	FileStatusNew       FileStatus = "new"
+	FileStatusUnchanged FileStatus = "unchanged"
+	FileStatusRevised   FileStatus = "revised"
+)
+
 type ProcessedFileService struct {
 	db *gorm.DB
 }
@@ -23,26 +31,29 @@ func NewProcessedFileService(db *gorm.DB) (*ProcessedFileService, error) {
 	return &ProcessedFileService{db: db}, nil
 }
 
-func (s *ProcessedFileService) IsProcessed(ctx context.Context, filename string) (bool, error) {
+func (s *ProcessedFileService) ZipStatus(ctx context.Context, filename string, contentHash string) (FileStatus, error) {
 	if s == nil {
-		return false, errors.New("processed file service is nil")
+		return "", errors.New("processed file service is nil")
 	}
 	if s.db == nil {
-		return false, errors.New("db is nil")
+		return "", errors.New("db is nil")
 	}
 	if filename == "" {
-		return false, errors.New("filename is empty")
+		return "", errors.New("filename is empty")
+	}
+	if contentHash == "" {
+		return "", errors.New("content hash is empty")
 	}
 
-	var count int64
-	if err := s.db.WithContext(ctx).Model(&models.ProcessedFile{}).Where("zip_filename = ?", filename).Count(&count).Error; err != nil {
-		return false, fmt.Errorf("check processed file: %w", err)
+	var entries []models.ProcessedFile
+	if err := s.db.WithContext(ctx).Where("zip_filename = ? AND workbook_filename = ?", filename, "").Find(&entries).Error; err != nil {
+		return "", fmt.Errorf("check processed file: %w", err)
 	}
 
-	return count > 0, nil
+	return fileStatus(entries, contentHash), nil
 }
 
-func (s *ProcessedFileService) MarkProcessed(ctx context.Context, filename string) error {
+func (s *ProcessedFileService) MarkProcessed(ctx context.Context, filename string, contentHash string) error {
 	if s == nil {
 		return errors.New("processed file service is nil")
 	}
@@ -55,12 +66,90 @@ func (s *ProcessedFileService) MarkProcessed(ctx context.Context, filename strin
 
 	entry := models.ProcessedFile{
 		ZipFilename: filename,
+		ContentHash: contentHash,
 		ProcessedAt: time.Now().UTC(),
 	}
 
-	if err := s.db.WithContext(ctx).Where("zip_filename = ?", filename).FirstOrCreate(&entry).Error; err != nil {
+	if err := s.db.WithContext(ctx).
+		Where("zip_filename = ? AND workbook_filename = ?", filename, "").
+		Assign(models.ProcessedFile{ContentHash: contentHash, ProcessedAt: entry.ProcessedAt}).
+		FirstOrCreate(&entry).Error; err != nil {
 		return fmt.Errorf("mark processed file: %w", err)
 	}
 
 	return nil
 }
+
+func (s *ProcessedFileService) WorkbookStatus(ctx context.Context, workbookFilename string, contentHash string) (FileStatus, error) {
+	if s == nil {
+		return "", errors.New("processed file service is nil")
+	}
+	if s.db == nil {
+		return "", errors.New("db is nil")
+	}
+	if workbookFilename == "" {
+		return "", errors.New("workbook filename is empty")
+	}
+	if contentHash == "" {
+		return "", errors.New("content hash is empty")
+	}
+
+	var entries []models.ProcessedFile
+	if err := s.db.WithContext(ctx).Where("workbook_filename = ?", workbookFilename).Find(&entries).Error; err != nil {
+		return "", fmt.Errorf("check processed workbook: %w", err)
+	}
+
+	return fileStatus(entries, contentHash), nil
+}
+
+func (s *ProcessedFileService) MarkWorkbookProcessed(ctx context.Context, zipFilename string, workbookFilename string, contentHash string) error {
+	if s == nil {
+		return errors.New("processed file service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if zipFilename == "" {
+		return errors.New("zip filename is empty")
+	}
+	if workbookFilename == "" {
+		return errors.New("workbook filename is empty")
+	}
+	if contentHash == "" {
+		return errors.New("content hash is empty")
+	}
+
+	entry := models.ProcessedFile{
+		ZipFilename:      zipFilename,
+		WorkbookFilename: workbookFilename,
+		ContentHash:      contentHash,
+		ProcessedAt:      time.Now().UTC(),
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("zip_filename = ? AND workbook_filename = ?", zipFilename, workbookFilename).
+		Assign(models.ProcessedFile{ContentHash: contentHash, ProcessedAt: entry.ProcessedAt}).
+		FirstOrCreate(&entry).Error; err != nil {
+		return fmt.Errorf("mark processed workbook: %w", err)
+	}
+
+	return nil
+}
+
+// Entries written before content hashes were tracked have an empty hash; a file
+// with only such entries is reported as new so its workbooks are checked again.
+func fileStatus(entries []models.ProcessedFile, contentHash string) FileStatus {
+	hashed := false
+	for _, entry := range entries {
+		if entry.ContentHash == contentHash {
+			return FileStatusUnchanged
+		}
+		if entry.ContentHash != "" {
+			hashed = true
+		}
+	}
+	if !hashed {
+		return FileStatusNew
+	}
+	return FileStatusRevised
+}
@@ -11,13 +11,13 @@ import (
 func createProcessedFilesTable(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	query := "CREATE TABLE processed_files (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), zip_filename TEXT NOT NULL UNIQUE, processed_at DATETIME NOT NULL)"
+	query := "CREATE TABLE processed_files (id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), zip_filename TEXT NOT NULL, workbook_filename TEXT NOT NULL DEFAULT '', content_hash TEXT NOT NULL DEFAULT '', processed_at DATETIME NOT NULL, UNIQUE (zip_filename, workbook_filename))"
 	if err := db.Exec(query).Error; err != nil {
 		t.Fatalf("create processed_files table: %v", err)
 	}
 }
 
-func TestProcessedFileServiceMarkAndCheck(t *testing.T) {
+func TestProcessedFileServiceMarkIdempotent(t *testing.T) {
 	db := openTestDB(t)
 	createProcessedFilesTable(t, db)
 
@@ -26,28 +26,83 @@ func TestProcessedFileServiceMarkAndCheck(t *testing.T) {
 		t.Fatalf("NewProcessedFileService: %v", err)
 	}
 
-	processed, err := service.IsProcessed(context.Background(), "file.zip")
+	if err := service.MarkProcessed(context.Background(), "file.zip", "hash-1"); err != nil {
+		t.Fatalf("MarkProcessed: %v", err)
+	}
+	if err := service.MarkProcessed(context.Background(), "file.zip", "hash-1"); err != nil {
+		t.Fatalf("MarkProcessed second: %v", err)
+	}
+
+	var count int64
+	if err := db.Model(&struct {
+		ZipFilename string
+		ProcessedAt time.Time
+	}{}).Table("processed_files").Where("zip_filename = ?", "file.zip").Count(&count).Error; err != nil {
+		t.Fatalf("count processed files: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("count = %d, want 1", count)
+	}
+}
+
+func TestProcessedFileServiceWorkbookTracking(t *testing.T) {
+	db := openTestDB(t)
+	createProcessedFilesTable(t, db)
+
+	service, err := NewProcessedFileService(db)
 	if err != nil {
-		t.Fatalf("IsProcessed: %v", err)
+		t.Fatalf("NewProcessedFileService: %v", err)
 	}
-	if processed {
-		t.Fatalf("expected file not processed")
+
+	status, err := service.WorkbookStatus(context.Background(), "august.xlsx", "hash-1")
+	if err != nil {
+		t.Fatalf("WorkbookStatus: %v", err)
+	}
+	if status != FileStatusNew {
+		t.Fatalf("status = %s, want %s", status, FileStatusNew)
 	}
 
-	if err := service.MarkProcessed(context.Background(), "file.zip"); err != nil {
-		t.Fatalf("MarkProcessed: %v", err)
+	if err := service.MarkWorkbookProcessed(context.Background(), "GO_2024_2025.zip", "august.xlsx", "hash-1"); err != nil {
+		t.Fatalf("MarkWorkbookProcessed: %v", err)
+	}
+
+	status, err = service.WorkbookStatus(context.Background(), "august.xlsx", "hash-1")
+	if err != nil {
+		t.Fatalf("WorkbookStatus: %v", err)
+	}
+	if status != FileStatusUnchanged {
+		t.Fatalf("status = %s, want %s", status, FileStatusUnchanged)
+	}
+
+	status, err = service.WorkbookStatus(context.Background(), "august.xlsx", "hash-2")
+	if err != nil {
+		t.Fatalf("WorkbookStatus: %v", err)
+	}
+	if status != FileStatusRevised {
+		t.Fatalf("status = %s, want %s", status, FileStatusRevised)
 	}
 
-	processed, err = service.IsProcessed(context.Background(), "file.zip")
+	zipStatus, err := service.ZipStatus(context.Background(), "GO_2024_2025.zip", "hash-1")
 	if err != nil {
-		t.Fatalf("IsProcessed: %v", err)
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if zipStatus != FileStatusNew {
+		t.Fatalf("zip status = %s, want workbook entry not to mark zip processed", zipStatus)
+	}
+
+	if err := service.MarkWorkbookProcessed(context.Background(), "GO_2024_2025.zip", "august.xlsx", "hash-2"); err != nil {
+		t.Fatalf("MarkWorkbookProcessed update: %v", err)
 	}
-	if !processed {
-		t.Fatalf("expected file processed")
+	var count int64
+	if err := db.Table("processed_files").Where("workbook_filename = ?", "august.xlsx").Count(&count).Error; err != nil {
+		t.Fatalf("count processed workbooks: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("count = %d, want 1", count)
 	}
 }
 
-func TestProcessedFileServiceMarkIdempotent(t *testing.T) {
+func TestProcessedFileServiceZipStatus(t *testing.T) {
 	db := openTestDB(t)
 	createProcessedFilesTable(t, db)
 
@@ -56,21 +111,85 @@ func TestProcessedFileServiceMarkIdempotent(t *testing.T) {
 		t.Fatalf("NewProcessedFileService: %v", err)
 	}
 
-	if err := service.MarkProcessed(context.Background(), "file.zip"); err != nil {
+	status, err := service.ZipStatus(context.Background(), "file.zip", "hash-1")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if status != FileStatusNew {
+		t.Fatalf("status = %s, want %s", status, FileStatusNew)
+	}
+
+	if err := service.MarkProcessed(context.Background(), "file.zip", "hash-1"); err != nil {
 		t.Fatalf("MarkProcessed: %v", err)
 	}
-	if err := service.MarkProcessed(context.Background(), "file.zip"); err != nil {
-		t.Fatalf("MarkProcessed second: %v", err)
+
+	status, err = service.ZipStatus(context.Background(), "file.zip", "hash-1")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if status != FileStatusUnchanged {
+		t.Fatalf("status = %s, want %s", status, FileStatusUnchanged)
 	}
 
-	var count int64
-	if err := db.Model(&struct {
-		ZipFilename string
-		ProcessedAt time.Time
-	}{}).Table("processed_files").Where("zip_filename = ?", "file.zip").Count(&count).Error; err != nil {
-		t.Fatalf("count processed files: %v", err)
+	status, err = service.ZipStatus(context.Background(), "file.zip", "hash-2")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
 	}
-	if count != 1 {
-		t.Fatalf("count = %d, want 1", count)
+	if status != FileStatusRevised {
+		t.Fatalf("status = %s, want %s", status, FileStatusRevised)
+	}
+
+	if err := service.MarkProcessed(context.Background(), "file.zip", "hash-2"); err != nil {
+		t.Fatalf("MarkProcessed revision: %v", err)
+	}
+	status, err = service.ZipStatus(context.Background(), "file.zip", "hash-2")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if status != FileStatusUnchanged {
+		t.Fatalf("status after revision = %s, want %s", status, FileStatusUnchanged)
+	}
+}
+
+func TestProcessedFileServiceTreatsMissingHashAsNew(t *testing.T) {
+	db := openTestDB(t)
+	createProcessedFilesTable(t, db)
+
+	service, err := NewProcessedFileService(db)
+	if err != nil {
+		t.Fatalf("NewProcessedFileService: %v", err)
+	}
+
+	insert := `INSERT INTO processed_files (zip_filename, workbook_filename, processed_at) VALUES
+		('file.zip', '', CURRENT_TIMESTAMP),
+		('file.zip', 'august.xlsx', CURRENT_TIMESTAMP)`
+	if err := db.Exec(insert).Error; err != nil {
+		t.Fatalf("insert legacy entries: %v", err)
+	}
+
+	status, err := service.ZipStatus(context.Background(), "file.zip", "hash-1")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if status != FileStatusNew {
+		t.Fatalf("zip status = %s, want %s so its workbooks are checked", status, FileStatusNew)
+	}
+	status, err = service.WorkbookStatus(context.Background(), "august.xlsx", "hash-2")
+	if err != nil {
+		t.Fatalf("WorkbookStatus: %v", err)
+	}
+	if status != FileStatusNew {
+		t.Fatalf("workbook status = %s, want %s", status, FileStatusNew)
+	}
+
+	if err := service.MarkProcessed(context.Background(), "file.zip", "hash-1"); err != nil {
+		t.Fatalf("MarkProcessed: %v", err)
+	}
+	status, err = service.ZipStatus(context.Background(), "file.zip", "hash-3")
+	if err != nil {
+		t.Fatalf("ZipStatus: %v", err)
+	}
+	if status != FileStatusRevised {
+		t.Fatalf("zip status after marking = %s, want %s", status, FileStatusRevised)
 	}
 }
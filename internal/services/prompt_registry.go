@@ -0,0 +1,215 @@
+package services
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	PromptZipLinks       = "zip_links"
+	PromptAuctionResults = "auction_results"
+	promptFileSuffix     = ".tmpl"
+)
+
+var ErrUnknownPrompt = errors.New("unknown prompt")
+
+//go:embed prompts
+var embeddedPrompts embed.FS
+
+type PromptTemplate struct {
+	Name     string
+	Version  string
+	Source   string
+	template *template.Template
+}
+
+type PromptInfo struct {
+	Name     string   `json:"name"`
+	Active   string   `json:"active"`
+	Versions []string `json:"versions"`
+}
+
+type PromptRegistry struct {
+	templates map[string]map[string]*PromptTemplate
+	active    map[string]string
+}
+
+func NewPromptRegistry(dir string, activeVersions map[string]string) (*PromptRegistry, error) {
+	registry := &PromptRegistry{
+		templates: map[string]map[string]*PromptTemplate{},
+		active:    map[string]string{},
+	}
+
+	embedded, err := fs.Sub(embeddedPrompts, "prompts")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded prompts: %w", err)
+	}
+	if err := registry.load(embedded, "embedded"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("open prompts dir: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("prompts dir %s is not a directory", dir)
+		}
+		if err := registry.load(os.DirFS(dir), dir); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, versions := range registry.templates {
+		registry.active[name] = latestPromptVersion(versions)
+	}
+	for name, version := range activeVersions {
+		if _, err := registry.Template(name, version); err != nil {
+			return nil, fmt.Errorf("active prompt %s: %w", name, err)
+		}
+		registry.active[name] = version
+	}
+
+	return registry, nil
+}
+
+func (r *PromptRegistry) load(files fs.FS, source string) error {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return fmt.Errorf("read prompts from %s: %w", source, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		versions, err := fs.ReadDir(files, name)
+		if err != nil {
+			return fmt.Errorf("read prompt %s from %s: %w", name, source, err)
+		}
+		for _, file := range versions {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), promptFileSuffix) {
+				continue
+			}
+			version := strings.TrimSuffix(file.Name(), promptFileSuffix)
+			content, err := fs.ReadFile(files, path.Join(name, file.Name()))
+			if err != nil {
+				return fmt.Errorf("read prompt %s@%s from %s: %w", name, version, source, err)
+			}
+			parsed, err := template.New(name + "@" + version).Option("missingkey=error").Parse(strings.TrimSuffix(string(content), "\n"))
+			if err != nil {
+				return fmt.Errorf("parse prompt %s@%s from %s: %w", name, version, source, err)
+			}
+
+			if r.templates[name] == nil {
+				r.templates[name] = map[string]*PromptTemplate{}
+			}
+			r.templates[name][version] = &PromptTemplate{
+				Name:     name,
+				Version:  version,
+				Source:   source,
+				template: parsed,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *PromptRegistry) Template(name string, version string) (*PromptTemplate, error) {
+	if r == nil {
+		return nil, errors.New("prompt registry is nil")
+	}
+
+	versions, ok := r.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPrompt, name)
+	}
+	if version == "" {
+		version = r.active[name]
+	}
+	prompt, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s@%s", ErrUnknownPrompt, name, version)
+	}
+	return prompt, nil
+}
+
+func (r *PromptRegistry) ListPrompts() []PromptInfo {
+	if r == nil {
+		return nil
+	}
+
+	prompts := make([]PromptInfo, 0, len(r.templates))
+	for name, versions := range r.templates {
+		info := PromptInfo{Name: name, Active: r.active[name]}
+		for version := range versions {
+			info.Versions = append(info.Versions, version)
+		}
+		sort.Slice(info.Versions, func(i, j int) bool {
+			return comparePromptVersions(info.Versions[i], info.Versions[j]) < 0
+		})
+		prompts = append(prompts, info)
+	}
+	sort.Slice(prompts, func(i, j int) bool {
+		return prompts[i].Name < prompts[j].Name
+	})
+	return prompts
+}
+
+func (p *PromptTemplate) Render(data any) (string, error) {
+	if p == nil || p.template == nil {
+		return "", errors.New("prompt template is nil")
+	}
+
+	var builder strings.Builder
+	if err := p.template.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("render prompt %s@%s: %w", p.Name, p.Version, err)
+	}
+	return builder.String(), nil
+}
+
+func (p *PromptTemplate) Label() string {
+	if p == nil {
+		return ""
+	}
+	return p.Name + "@" + p.Version
+}
+
+func defaultPromptRegistry(prompts *PromptRegistry) (*PromptRegistry, error) {
+	if prompts != nil {
+		return prompts, nil
+	}
+	return NewPromptRegistry("", nil)
+}
+
+func latestPromptVersion(versions map[string]*PromptTemplate) string {
+	var latest string
+	for version := range versions {
+		if latest == "" || comparePromptVersions(version, latest) > 0 {
+			latest = version
+		}
+	}
+	return latest
+}
+
+func comparePromptVersions(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	bNumber, bErr := strconv.Atoi(strings.TrimPrefix(b, "v"))
+	if aErr == nil && bErr == nil && aNumber != bNumber {
+		if aNumber < bNumber {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePromptFile(t *testing.T, dir string, name string, version string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+		t.Fatalf("create prompt dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name, version+promptFileSuffix), []byte(content), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+}
+
+func TestPromptRegistryEmbeddedDefaults(t *testing.T) {
+	registry, err := NewPromptRegistry("", nil)
+	if err != nil {
+		t.Fatalf("NewPromptRegistry: %v", err)
+	}
+
+	prompts := registry.ListPrompts()
+	if len(prompts) != 2 || prompts[0].Name != PromptAuctionResults || prompts[1].Name != PromptZipLinks {
+		t.Fatalf("prompts = %+v, want auction_results and zip_links", prompts)
+	}
+	for _, prompt := range prompts {
+		if prompt.Active != "v1" {
+			t.Fatalf("%s active = %q, want v1", prompt.Name, prompt.Active)
+		}
+	}
+
+	template, err := registry.Template(PromptAuctionResults, "")
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	rendered, err := template.Render(csvPromptData{Payload: `{"rows":[]}`})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.HasPrefix(rendered, "Instructions:\n") || !strings.HasSuffix(rendered, "Payload:\n{\"rows\":[]}") {
+		t.Fatalf("rendered = %q", rendered)
+	}
+	if template.Label() != "auction_results@v1" || template.Source != "embedded" {
+		t.Fatalf("template = %s from %s", template.Label(), template.Source)
+	}
+
+	if _, err := registry.Template(PromptAuctionResults, "v9"); !errors.Is(err, ErrUnknownPrompt) {
+		t.Fatalf("unknown version err = %v, want ErrUnknownPrompt", err)
+	}
+	if _, err := registry.Template("missing", ""); !errors.Is(err, ErrUnknownPrompt) {
+		t.Fatalf("unknown name err = %v, want ErrUnknownPrompt", err)
+	}
+	if _, err := template.Render(zipLinksPromptData{HTML: "<a></a>"}); err == nil {
+		t.Fatalf("expected render error for wrong data")
+	}
+}
+
+func TestPromptRegistryDirectoryOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, PromptAuctionResults, "v2", "Parse carefully.\n{{.Payload}}\n")
+	writePromptFile(t, dir, PromptAuctionResults, "v10", "Parse strictly.\n{{.Payload}}\n")
+	writePromptFile(t, dir, PromptZipLinks, "v1", "Find zips:\n{{.HTML}}\n")
+
+	registry, err := NewPromptRegistry(dir, nil)
+	if err != nil {
+		t.Fatalf("NewPromptRegistry: %v", err)
+	}
+
+	active, err := registry.Template(PromptAuctionResults, "")
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	if active.Version != "v10" {
+		t.Fatalf("active version = %q, want v10", active.Version)
+	}
+	zipLinks, err := registry.Template(PromptZipLinks, "v1")
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	rendered, err := zipLinks.Render(zipLinksPromptData{HTML: "<table></table>"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if rendered != "Find zips:\n<table></table>" || zipLinks.Source != dir {
+		t.Fatalf("override = %q from %s", rendered, zipLinks.Source)
+	}
+
+	prompts := registry.ListPrompts()
+	if got := strings.Join(prompts[0].Versions, ","); got != "v1,v2,v10" {
+		t.Fatalf("versions = %s, want v1,v2,v10", got)
+	}
+
+	pinned, err := NewPromptRegistry(dir, map[string]string{PromptAuctionResults: "v2"})
+	if err != nil {
+		t.Fatalf("NewPromptRegistry pinned: %v", err)
+	}
+	if template, _ := pinned.Template(PromptAuctionResults, ""); template.Version != "v2" {
+		t.Fatalf("pinned version = %q, want v2", template.Version)
+	}
+	if _, err := NewPromptRegistry(dir, map[string]string{PromptAuctionResults: "v3"}); err == nil {
+		t.Fatalf("expected error for unknown pinned version")
+	}
+}
+
+func TestPromptRegistryInvalidDirectory(t *testing.T) {
+	if _, err := NewPromptRegistry(filepath.Join(t.TempDir(), "missing"), nil); err == nil {
+		t.Fatalf("expected error for missing prompts dir")
+	}
+
+	dir := t.TempDir()
+	writePromptFile(t, dir, PromptZipLinks, "v2", "{{.HTML")
+	if _, err := NewPromptRegistry(dir, nil); err == nil || !strings.Contains(err.Error(), "zip_links@v2") {
+		t.Fatalf("err = %v, want parse error for zip_links@v2", err)
+	}
+}
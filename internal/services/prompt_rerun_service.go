@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"solback/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	PromptDiffAdded   = "added"
+	PromptDiffRemoved = "removed"
+	PromptDiffChanged = "changed"
+)
+
+type PromptRowValues struct {
+	TotalVolumeAuctioned      float64 `json:"total_volume_auctioned"`
+	TotalVolumeSold           float64 `json:"total_volume_sold"`
+	WeightedAvgPriceEurPerMwh float64 `json:"weighted_avg_price_eur_per_mwh"`
+	PromptVersion             string  `json:"prompt_version,omitempty"`
+}
+
+type PromptRowDiff struct {
+	Year       int              `json:"year"`
+	Month      int              `json:"month"`
+	Region     string           `json:"region"`
+	Technology string           `json:"technology"`
+	Status     string           `json:"status"`
+	Stored     *PromptRowValues `json:"stored,omitempty"`
+	Rerun      *PromptRowValues `json:"rerun,omitempty"`
+}
+
+type PromptComparison struct {
+	SourceFile    string          `json:"source_file"`
+	PromptName    string          `json:"prompt_name"`
+	PromptVersion string          `json:"prompt_version"`
+	EventID       string          `json:"event_id"`
+	StoredRows    int             `json:"stored_rows"`
+	RerunRows     int             `json:"rerun_rows"`
+	Quarantined   int             `json:"quarantined"`
+	Unchanged     int             `json:"unchanged"`
+	Differences   []PromptRowDiff `json:"differences"`
+	Error         string          `json:"error,omitempty"`
+}
+
+type PromptRerunService struct {
+	db         *gorm.DB
+	prompts    *PromptRegistry
+	payloads   PayloadLoader
+	parser     PromptedAuctionParser
+	logService LogWriter
+}
+
+func NewPromptRerunService(db *gorm.DB, prompts *PromptRegistry, payloads PayloadLoader, parser PromptedAuctionParser, logService LogWriter) (*PromptRerunService, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if prompts == nil {
+		return nil, errors.New("prompt registry is nil")
+	}
+	if payloads == nil {
+		return nil, errors.New("payload loader is nil")
+	}
+	if parser == nil {
+		return nil, errors.New("prompted parser is nil")
+	}
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &PromptRerunService{
+		db:         db,
+		prompts:    prompts,
+		payloads:   payloads,
+		parser:     parser,
+		logService: logService,
+	}, nil
+}
+
+func (s *PromptRerunService) ListPrompts() []PromptInfo {
+	if s == nil {
+		return nil
+	}
+	return s.prompts.ListPrompts()
+}
+
+func (s *PromptRerunService) RerunPayload(ctx context.Context, sourceFile string, promptVersion string) (PromptComparison, error) {
+	if s == nil {
+		return PromptComparison{}, errors.New("prompt rerun service is nil")
+	}
+	if s.db == nil {
+		return PromptComparison{}, errors.New("db is nil")
+	}
+	if s.prompts == nil {
+		return PromptComparison{}, errors.New("prompt registry is nil")
+	}
+	if s.payloads == nil {
+		return PromptComparison{}, errors.New("payload loader is nil")
+	}
+	if s.parser == nil {
+		return PromptComparison{}, errors.New("prompted parser is nil")
+	}
+	if s.logService == nil {
+		return PromptComparison{}, errors.New("log service is nil")
+	}
+
+	template, err := s.prompts.Template(PromptAuctionResults, promptVersion)
+	if err != nil {
+		return PromptComparison{}, err
+	}
+	payload, err := s.payloads.GetPayload(ctx, sourceFile)
+	if err != nil {
+		return PromptComparison{}, err
+	}
+
+	var stored []models.AuctionResult
+	if err := s.db.WithContext(ctx).Where("source_file = ?", sourceFile).Find(&stored).Error; err != nil {
+		return PromptComparison{}, fmt.Errorf("load stored rows: %w", err)
+	}
+
+	eventID := uuid.NewString()
+	startMsg := fmt.Sprintf("rerun source_file=%s prompt=%s stored_rows=%d", sourceFile, template.Label(), len(stored))
+	_ = s.logService.CreateLog(ctx, &eventID, LogActionOpenAICSVParse, LogOutcomeSuccess, &startMsg)
+
+	result, parseErr := s.parser.ParseAuctionResultsWithPrompt(ctx, payload, template.Version, &eventID)
+	if parseErr != nil && len(result.Rows) == 0 {
+		return PromptComparison{}, fmt.Errorf("rerun source_file=%s prompt=%s: %w", sourceFile, template.Label(), parseErr)
+	}
+
+	comparison := compareAuctionRows(stored, result)
+	comparison.SourceFile = sourceFile
+	comparison.PromptName = template.Name
+	comparison.PromptVersion = template.Version
+	comparison.EventID = eventID
+	if parseErr != nil {
+		comparison.Error = parseErr.Error()
+	}
+
+	outcome := LogOutcomeSuccess
+	if parseErr != nil {
+		outcome = LogOutcomeFail
+	}
+	msg := fmt.Sprintf("rerun source_file=%s prompt=%s stored_rows=%d rerun_rows=%d unchanged=%d differences=%d quarantined=%d", sourceFile, template.Label(), comparison.StoredRows, comparison.RerunRows, comparison.Unchanged, len(comparison.Differences), comparison.Quarantined)
+	_ = s.logService.CreateLog(ctx, &eventID, LogActionOpenAICSVParse, outcome, &msg)
+
+	return comparison, nil
+}
+
+func compareAuctionRows(stored []models.AuctionResult, result AuctionResults) PromptComparison {
+	comparison := PromptComparison{
+		StoredRows:  len(stored),
+		RerunRows:   len(result.Rows),
+		Quarantined: len(result.Quarantined),
+		Differences: []PromptRowDiff{},
+	}
+
+	storedByKey := make(map[string]models.AuctionResult, len(stored))
+	for _, row := range stored {
+		storedByKey[auctionResultKey(row.Year, row.Month, row.Region, row.Technology)] = row
+	}
+
+	seen := make(map[string]bool, len(result.Rows))
+	for _, row := range result.Rows {
+		year, month := int(math.Trunc(row.Year)), int(math.Trunc(row.Month))
+		key := auctionResultKey(year, month, row.Region, row.Technology)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		rerun := &PromptRowValues{
+			TotalVolumeAuctioned:      row.TotalVolumeAuctioned,
+			TotalVolumeSold:           row.TotalVolumeSold,
+			WeightedAvgPriceEurPerMwh: row.WeightedAvgPriceEurPerMwh,
+			PromptVersion:             result.PromptVersion,
+		}
+		diff := PromptRowDiff{Year: year, Month: month, Region: row.Region, Technology: row.Technology, Rerun: rerun}
+
+		existing, ok := storedByKey[key]
+		if !ok {
+			diff.Status = PromptDiffAdded
+			comparison.Differences = append(comparison.Differences, diff)
+			continue
+		}
+
+		diff.Stored = storedRowValues(existing)
+		if diff.Stored.TotalVolumeAuctioned == rerun.TotalVolumeAuctioned &&
+			diff.Stored.TotalVolumeSold == rerun.TotalVolumeSold &&
+			diff.Stored.WeightedAvgPriceEurPerMwh == rerun.WeightedAvgPriceEurPerMwh {
+			comparison.Unchanged++
+			continue
+		}
+		diff.Status = PromptDiffChanged
+		comparison.Differences = append(comparison.Differences, diff)
+	}
+
+	for key, row := range storedByKey {
+		if seen[key] {
+			continue
+		}
+		comparison.Differences = append(comparison.Differences, PromptRowDiff{
+			Year:       row.Year,
+			Month:      row.Month,
+			Region:     row.Region,
+			Technology: row.Technology,
+			Status:     PromptDiffRemoved,
+			Stored:     storedRowValues(row),
+		})
+	}
+
+	sort.Slice(comparison.Differences, func(i, j int) bool {
+		left, right := comparison.Differences[i], comparison.Differences[j]
+		return auctionResultKey(left.Year, left.Month, left.Region, left.Technology) < auctionResultKey(right.Year, right.Month, right.Region, right.Technology)
+	})
+
+	return comparison
+}
+
+func storedRowValues(row models.AuctionResult) *PromptRowValues {
+	values := &PromptRowValues{
+		TotalVolumeAuctioned: row.TotalVolumeAuctioned,
+		TotalVolumeSold:      row.TotalVolumeSold,
+	}
+	if row.WeightedAvgPriceEurPerMwh != nil {
+		values.WeightedAvgPriceEurPerMwh = *row.WeightedAvgPriceEurPerMwh
+	}
+	if row.PromptVersion != nil {
+		values.PromptVersion = *row.PromptVersion
+	}
+	return values
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubPayloadLoader struct {
+	payload AuctionPayload
+	err     error
+}
+
+func (s stubPayloadLoader) GetPayload(ctx context.Context, sourceFile string) (AuctionPayload, error) {
+	if s.err != nil {
+		return AuctionPayload{}, s.err
+	}
+	return s.payload, nil
+}
+
+type stubPromptedParser struct {
+	result  AuctionResults
+	err     error
+	version string
+}
+
+func (s *stubPromptedParser) ParseAuctionResultsWithPrompt(ctx context.Context, payload AuctionPayload, promptVersion string, eventID *string) (AuctionResults, error) {
+	s.version = promptVersion
+	return s.result, s.err
+}
+
+func TestPromptRerunServiceComparesRows(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionResultsTable(t, db)
+
+	sourceFile := "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx"
+	insert := `INSERT INTO auction_results (source_file, participants, year, month, region, technology, total_volume_auctioned, total_volume_sold, weighted_avg_price_eur_per_mwh, number_of_winners, prompt_name, prompt_version)
+		VALUES (?, 34, 2025, 8, ?, 'Solar', ?, ?, ?, 1, 'auction_results', 'v1')`
+	for _, row := range []struct {
+		region string
+		volume float64
+		price  float64
+	}{
+		{region: "Normandie", volume: 10, price: 0.3},
+		{region: "Bretagne", volume: 20, price: 0.4},
+		{region: "Corse", volume: 5, price: 0.5},
+	} {
+		if err := db.Exec(insert, sourceFile, row.region, row.volume, row.volume, row.price).Error; err != nil {
+			t.Fatalf("insert auction result: %v", err)
+		}
+	}
+
+	registry, err := NewPromptRegistry("", nil)
+	if err != nil {
+		t.Fatalf("NewPromptRegistry: %v", err)
+	}
+	parser := &stubPromptedParser{result: AuctionResults{
+		SourceFile:    sourceFile,
+		PromptName:    PromptAuctionResults,
+		PromptVersion: "v1",
+		Rows: []AuctionRow{
+			{Year: 2025, Month: 8, Region: "Normandie", Technology: "Solar", TotalVolumeAuctioned: 10, TotalVolumeSold: 10, WeightedAvgPriceEurPerMwh: 0.3},
+			{Year: 2025, Month: 8, Region: "Bretagne", Technology: "Solar", TotalVolumeAuctioned: 20, TotalVolumeSold: 20, WeightedAvgPriceEurPerMwh: 0.45},
+			{Year: 2025, Month: 8, Region: "Occitanie", Technology: "Solar", TotalVolumeAuctioned: 7, TotalVolumeSold: 7, WeightedAvgPriceEurPerMwh: 0.6},
+		},
+		Quarantined: []QuarantinedRow{{Index: 3, SourceRow: -1}},
+	}}
+	logWriter := &stubLogWriter{}
+	service, err := NewPromptRerunService(db, registry, stubPayloadLoader{payload: AuctionPayload{SourceFile: sourceFile}}, parser, logWriter)
+	if err != nil {
+		t.Fatalf("NewPromptRerunService: %v", err)
+	}
+
+	comparison, err := service.RerunPayload(context.Background(), sourceFile, "v1")
+	if err != nil {
+		t.Fatalf("RerunPayload: %v", err)
+	}
+	if parser.version != "v1" {
+		t.Fatalf("parser version = %q, want v1", parser.version)
+	}
+	if comparison.PromptName != PromptAuctionResults || comparison.PromptVersion != "v1" || comparison.EventID == "" {
+		t.Fatalf("comparison = %+v", comparison)
+	}
+	if comparison.StoredRows != 3 || comparison.RerunRows != 3 || comparison.Unchanged != 1 || comparison.Quarantined != 1 {
+		t.Fatalf("comparison counts = %+v", comparison)
+	}
+
+	want := map[string]string{"Bretagne": PromptDiffChanged, "Corse": PromptDiffRemoved, "Occitanie": PromptDiffAdded}
+	if len(comparison.Differences) != len(want) {
+		t.Fatalf("differences = %+v", comparison.Differences)
+	}
+	for _, diff := range comparison.Differences {
+		if want[diff.Region] != diff.Status {
+			t.Fatalf("%s status = %q, want %q", diff.Region, diff.Status, want[diff.Region])
+		}
+		if diff.Status == PromptDiffChanged && (diff.Stored.WeightedAvgPriceEurPerMwh != 0.4 || diff.Rerun.WeightedAvgPriceEurPerMwh != 0.45 || diff.Stored.PromptVersion != "v1") {
+			t.Fatalf("changed diff = %+v %+v", diff.Stored, diff.Rerun)
+		}
+	}
+
+	var count int64
+	if err := db.Table("auction_results").Count(&count).Error; err != nil {
+		t.Fatalf("count auction results: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("stored rows = %d, want rerun to leave rows untouched", count)
+	}
+}
+
+func TestPromptRerunServiceErrors(t *testing.T) {
+	db := openTestDB(t)
+	createAuctionResultsTable(t, db)
+
+	registry, err := NewPromptRegistry("", nil)
+	if err != nil {
+		t.Fatalf("NewPromptRegistry: %v", err)
+	}
+
+	service, err := NewPromptRerunService(db, registry, stubPayloadLoader{err: ErrPayloadNotFound}, &stubPromptedParser{}, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewPromptRerunService: %v", err)
+	}
+	if _, err := service.RerunPayload(context.Background(), "file.xlsx", "v9"); !errors.Is(err, ErrUnknownPrompt) {
+		t.Fatalf("err = %v, want ErrUnknownPrompt", err)
+	}
+	if _, err := service.RerunPayload(context.Background(), "file.xlsx", ""); !errors.Is(err, ErrPayloadNotFound) {
+		t.Fatalf("err = %v, want ErrPayloadNotFound", err)
+	}
+
+	service, err = NewPromptRerunService(db, registry, stubPayloadLoader{}, &stubPromptedParser{err: errors.New("boom")}, &stubLogWriter{})
+	if err != nil {
+		t.Fatalf("NewPromptRerunService: %v", err)
+	}
+	if _, err := service.RerunPayload(context.Background(), "file.xlsx", ""); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrBodyTooLarge          = errors.New("response body too large")
+	ErrUnexpectedContentType = errors.New("unexpected content type")
+)
+
+var zipSignatures = [][]byte{
+	[]byte("PK\x03\x04"),
+	[]byte("PK\x05\x06"),
+}
+
+func readLimitedBody(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("%w: content-length %d exceeds limit %d", ErrBodyTooLarge, resp.ContentLength, maxBytes)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return body, err
+	}
+	if maxBytes > 0 && int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("%w: body exceeds limit %d", ErrBodyTooLarge, maxBytes)
+	}
+	return body, nil
+}
+
+func checkHTMLContent(declared string, body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	sniffed := http.DetectContentType(body)
+	if isHTMLMediaType(sniffed) {
+		return nil
+	}
+	if isHTMLMediaType(declared) && strings.HasPrefix(sniffed, "text/plain") && !json.Valid(body) {
+		return nil
+	}
+	return fmt.Errorf("%w: expected html, declared=%q sniffed=%q", ErrUnexpectedContentType, declared, sniffed)
+}
+
+func isHTMLMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+func checkZipContent(declared string, body []byte) error {
+	for _, signature := range zipSignatures {
+		if bytes.HasPrefix(body, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected zip, declared=%q sniffed=%q", ErrUnexpectedContentType, declared, http.DetectContentType(body))
+}
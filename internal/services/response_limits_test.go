@@ -0,0 +1,58 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadLimitedBody(t *testing.T) {
+	resp := &http.Response{ContentLength: 11, Body: io.NopCloser(strings.NewReader("hello world"))}
+	if _, err := readLimitedBody(resp, 10); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("content-length err = %v, want ErrBodyTooLarge", err)
+	}
+
+	resp = &http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader("hello world"))}
+	if _, err := readLimitedBody(resp, 10); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("streamed err = %v, want ErrBodyTooLarge", err)
+	}
+
+	resp = &http.Response{ContentLength: -1, Body: io.NopCloser(strings.NewReader("hello"))}
+	body, err := readLimitedBody(resp, 5)
+	if err != nil || string(body) != "hello" {
+		t.Fatalf("body = %q err = %v, want body at the limit", body, err)
+	}
+}
+
+func TestCheckResponseContent(t *testing.T) {
+	zipBytes := []byte("PK\x03\x04\x14\x00\x00\x00")
+	htmlBytes := []byte("<!DOCTYPE html><html><body>results</body></html>")
+
+	if err := checkHTMLContent("text/html", htmlBytes); err != nil {
+		t.Fatalf("html: %v", err)
+	}
+	if err := checkHTMLContent("text/html; charset=utf-8", []byte("results without markup")); err != nil {
+		t.Fatalf("declared html fragment: %v", err)
+	}
+	if err := checkHTMLContent("text/plain", []byte("plain text page")); !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("plain text err = %v, want ErrUnexpectedContentType", err)
+	}
+	if err := checkHTMLContent("text/csv", []byte("region,technology\nBretagne,Solaire")); !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("csv err = %v, want ErrUnexpectedContentType", err)
+	}
+	if err := checkHTMLContent("text/html", []byte(`{"links":[]}`)); !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("json err = %v, want ErrUnexpectedContentType", err)
+	}
+	if err := checkHTMLContent("text/html", zipBytes); !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("zip as html err = %v, want ErrUnexpectedContentType", err)
+	}
+
+	if err := checkZipContent("application/octet-stream", zipBytes); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	if err := checkZipContent("application/zip", htmlBytes); !errors.Is(err, ErrUnexpectedContentType) || !strings.Contains(err.Error(), "text/html") {
+		t.Fatalf("html as zip err = %v, want sniffed text/html", err)
+	}
+}
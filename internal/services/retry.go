@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit open")
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+type HTTPStatusError struct {
+	Upstream   string
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s status %d", e.Upstream, e.StatusCode)
+	}
+	return fmt.Sprintf("%s status %d: %s", e.Upstream, e.StatusCode, e.Body)
+}
+
+func newHTTPStatusError(upstream string, resp *http.Response, body []byte) *HTTPStatusError {
+	return &HTTPStatusError{
+		Upstream:   upstream,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  map[string]int
+	openUntil map[string]time.Time
+	probing   map[string]bool
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, error) {
+	if threshold <= 0 {
+		return nil, errors.New("breaker threshold must be positive")
+	}
+	if cooldown <= 0 {
+		return nil, errors.New("breaker cooldown must be positive")
+	}
+
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		failures:  make(map[string]int),
+		openUntil: make(map[string]time.Time),
+		probing:   make(map[string]bool),
+	}, nil
+}
+
+func (b *CircuitBreaker) Allow(upstream string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until, ok := b.openUntil[upstream]
+	if !ok {
+		return nil
+	}
+	if b.now().Before(until) {
+		return fmt.Errorf("%w: upstream=%s until=%s", ErrCircuitOpen, upstream, until.UTC().Format(time.RFC3339))
+	}
+	if b.probing[upstream] {
+		return fmt.Errorf("%w: upstream=%s half-open probe in flight", ErrCircuitOpen, upstream)
+	}
+	b.probing[upstream] = true
+	return nil
+}
+
+func (b *CircuitBreaker) Success(upstream string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, upstream)
+	delete(b.openUntil, upstream)
+	delete(b.probing, upstream)
+}
+
+func (b *CircuitBreaker) Failure(upstream string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.probing[upstream] {
+		delete(b.probing, upstream)
+		b.failures[upstream] = b.threshold
+		b.openUntil[upstream] = b.now().Add(b.cooldown)
+		return
+	}
+
+	b.failures[upstream]++
+	if b.failures[upstream] >= b.threshold {
+		b.openUntil[upstream] = b.now().Add(b.cooldown)
+	}
+}
+
+func (b *CircuitBreaker) Release(upstream string) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.probing, upstream)
+}
+
+type Retrier struct {
+	policy     RetryPolicy
+	breaker    *CircuitBreaker
+	logService LogWriter
+	sleep      func(ctx context.Context, wait time.Duration) error
+	jitter     func(wait time.Duration) time.Duration
+}
+
+func NewRetrier(policy RetryPolicy, breaker *CircuitBreaker, logService LogWriter) (*Retrier, error) {
+	if policy.MaxAttempts <= 0 {
+		return nil, errors.New("max attempts must be positive")
+	}
+	if policy.BaseDelay < 0 || policy.MaxDelay < policy.BaseDelay {
+		return nil, errors.New("retry delays are invalid")
+	}
+	if logService == nil {
+		return nil, errors.New("log service is nil")
+	}
+
+	return &Retrier{
+		policy:     policy,
+		breaker:    breaker,
+		logService: logService,
+		sleep:      sleepContext,
+		jitter:     applyJitter,
+	}, nil
+}
+
+func singleAttemptRetrier() *Retrier {
+	return &Retrier{
+		policy: RetryPolicy{MaxAttempts: 1},
+		sleep:  sleepContext,
+		jitter: applyJitter,
+	}
+}
+
+func (r *Retrier) Do(ctx context.Context, upstream string, action string, eventID *string, fn func(ctx context.Context) error) error {
+	if r == nil {
+		return errors.New("retrier is nil")
+	}
+	if fn == nil {
+		return errors.New("retry func is nil")
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= r.policy.MaxAttempts; attempt++ {
+		if err := r.breaker.Allow(upstream); err != nil {
+			msg := fmt.Sprintf("upstream=%s attempt=%d/%d short-circuited: %v", upstream, attempt, r.policy.MaxAttempts, err)
+			r.log(ctx, eventID, action, LogOutcomeFail, msg)
+			if lastErr != nil {
+				return fmt.Errorf("%w (last error: %v)", err, lastErr)
+			}
+			return err
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			r.breaker.Success(upstream)
+			if attempt > 1 {
+				msg := fmt.Sprintf("upstream=%s attempt=%d/%d succeeded", upstream, attempt, r.policy.MaxAttempts)
+				r.log(ctx, eventID, action, LogOutcomeSuccess, msg)
+			}
+			return nil
+		}
+		lastErr = err
+
+		retryable := isRetryableError(ctx, err)
+		if retryable {
+			r.breaker.Failure(upstream)
+		} else {
+			r.breaker.Release(upstream)
+		}
+
+		wait, retry := r.nextWait(attempt, err, retryable)
+		msg := fmt.Sprintf("upstream=%s attempt=%d/%d retryable=%t retry=%t wait=%s: %v", upstream, attempt, r.policy.MaxAttempts, retryable, retry, wait, err)
+		r.log(ctx, eventID, action, LogOutcomeFail, msg)
+		if !retry {
+			return err
+		}
+
+		if err := r.sleep(ctx, wait); err != nil {
+			return fmt.Errorf("wait before retry: %w", err)
+		}
+	}
+
+	return lastErr
+}
+
+func (r *Retrier) nextWait(attempt int, err error, retryable bool) (time.Duration, bool) {
+	if !retryable || attempt >= r.policy.MaxAttempts {
+		return 0, false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		if statusErr.RetryAfter > r.policy.MaxDelay {
+			return r.policy.MaxDelay, true
+		}
+		return statusErr.RetryAfter, true
+	}
+
+	wait := r.policy.BaseDelay << (attempt - 1)
+	if wait <= 0 || wait > r.policy.MaxDelay {
+		wait = r.policy.MaxDelay
+	}
+	return r.jitter(wait), true
+}
+
+func (r *Retrier) log(ctx context.Context, eventID *string, action string, outcome string, msg string) {
+	if r.logService == nil {
+		return
+	}
+	_ = r.logService.CreateLog(ctx, eventID, action, outcome, &msg)
+}
+
+func isRetryableError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrEgressBlocked) {
+		return false
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+	if wait := when.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func upstreamHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Host
+}
+
+func applyJitter(wait time.Duration) time.Duration {
+	if wait <= 0 {
+		return 0
+	}
+	half := wait / 2
+	return half + time.Duration(rand.Int64N(int64(wait-half)+1))
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
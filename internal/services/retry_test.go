@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"solback/internal/llmfake"
+)
+
+func newTestRetrier(t *testing.T, attempts int, breaker *CircuitBreaker, logWriter LogWriter) (*Retrier, *[]time.Duration) {
+	t.Helper()
+
+	retrier, err := NewRetrier(RetryPolicy{MaxAttempts: attempts, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, breaker, logWriter)
+	if err != nil {
+		t.Fatalf("NewRetrier: %v", err)
+	}
+
+	var waits []time.Duration
+	retrier.sleep = func(ctx context.Context, wait time.Duration) error {
+		waits = append(waits, wait)
+		return nil
+	}
+	retrier.jitter = func(wait time.Duration) time.Duration { return wait }
+	return retrier, &waits
+}
+
+func TestRetrierBackoffAndRetryAfter(t *testing.T) {
+	logWriter := &stubLogWriter{}
+	retrier, waits := newTestRetrier(t, 4, nil, logWriter)
+
+	errs := []error{
+		&HTTPStatusError{Upstream: "test", StatusCode: http.StatusServiceUnavailable},
+		&HTTPStatusError{Upstream: "test", StatusCode: http.StatusTooManyRequests, RetryAfter: 700 * time.Millisecond},
+		&HTTPStatusError{Upstream: "test", StatusCode: http.StatusBadGateway},
+		nil,
+	}
+	var calls int
+	err := retrier.Do(context.Background(), "example.com", LogActionZipDownload, nil, func(ctx context.Context) error {
+		err := errs[calls]
+		calls++
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if calls != 4 {
+		t.Fatalf("calls = %d, want 4", calls)
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 700 * time.Millisecond, 400 * time.Millisecond}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", *waits, want)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", *waits, want)
+		}
+	}
+
+	if len(logWriter.entries) != 4 {
+		t.Fatalf("log entries = %d, want 4", len(logWriter.entries))
+	}
+	if !strings.Contains(*logWriter.entries[1].message, "attempt=2/4") || !strings.Contains(*logWriter.entries[1].message, "wait=700ms") {
+		t.Fatalf("second log = %q, want attempt and wait", *logWriter.entries[1].message)
+	}
+	if logWriter.entries[3].outcome != LogOutcomeSuccess {
+		t.Fatalf("last log outcome = %q, want %q", logWriter.entries[3].outcome, LogOutcomeSuccess)
+	}
+}
+
+func TestRetrierStopsOnNonRetryableError(t *testing.T) {
+	retrier, waits := newTestRetrier(t, 4, nil, &stubLogWriter{})
+
+	var calls int
+	err := retrier.Do(context.Background(), "example.com", LogActionZipDownload, nil, func(ctx context.Context) error {
+		calls++
+		return &HTTPStatusError{Upstream: "test", StatusCode: http.StatusBadRequest}
+	})
+	if err == nil {
+		t.Fatalf("Do: expected error")
+	}
+	if calls != 1 || len(*waits) != 0 {
+		t.Fatalf("calls = %d waits = %v, want a single attempt", calls, *waits)
+	}
+
+}
+
+func TestRetrierCapsRetryAfterAtMaxDelay(t *testing.T) {
+	retrier, waits := newTestRetrier(t, 2, nil, &stubLogWriter{})
+
+	var calls int
+	err := retrier.Do(context.Background(), "example.com", LogActionZipDownload, nil, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return &HTTPStatusError{Upstream: "test", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+	if len(*waits) != 1 || (*waits)[0] != time.Second {
+		t.Fatalf("waits = %v, want [1s]", *waits)
+	}
+}
+
+func TestRetrierCircuitBreaker(t *testing.T) {
+	breaker, err := NewCircuitBreaker(2, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCircuitBreaker: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	retrier, _ := newTestRetrier(t, 3, breaker, &stubLogWriter{})
+
+	var calls int
+	failing := func(ctx context.Context) error {
+		calls++
+		return &HTTPStatusError{Upstream: "test", StatusCode: http.StatusInternalServerError}
+	}
+	err = retrier.Do(context.Background(), "example.com", LogActionDataRetrieval, nil, failing)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do error = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2 before the circuit opens", calls)
+	}
+
+	if err := retrier.Do(context.Background(), "other.com", LogActionDataRetrieval, nil, func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("Do other upstream: %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	calls = 0
+	if err := retrier.Do(context.Background(), "example.com", LogActionDataRetrieval, nil, func(ctx context.Context) error {
+		calls++
+		return nil
+	}); err != nil {
+		t.Fatalf("Do after cooldown: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 after cooldown", calls)
+	}
+	if err := breaker.Allow("example.com"); err != nil {
+		t.Fatalf("Allow after success: %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker, err := NewCircuitBreaker(2, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCircuitBreaker: %v", err)
+	}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Failure("example.com")
+	breaker.Failure("example.com")
+	if err := breaker.Allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow while open = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := breaker.Allow("example.com"); err != nil {
+		t.Fatalf("Allow probe after cooldown: %v", err)
+	}
+	if err := breaker.Allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow during probe = %v, want ErrCircuitOpen", err)
+	}
+
+	breaker.Failure("example.com")
+	if err := breaker.Allow("example.com"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow after failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := breaker.Allow("example.com"); err != nil {
+		t.Fatalf("Allow second probe: %v", err)
+	}
+	breaker.Release("example.com")
+	if err := breaker.Allow("example.com"); err != nil {
+		t.Fatalf("Allow after released probe: %v", err)
+	}
+	breaker.Success("example.com")
+
+	breaker.Failure("example.com")
+	if err := breaker.Allow("example.com"); err != nil {
+		t.Fatalf("Allow after reset = %v, want a closed circuit below the threshold", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"3":                             3 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Wed, 01 Jan 2025 12:00:30 GMT": 30 * time.Second,
+		"Wed, 01 Jan 2025 11:00:00 GMT": 0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Fatalf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	ctx := context.Background()
+	if !isRetryableError(ctx, &HTTPStatusError{StatusCode: http.StatusTooManyRequests}) {
+		t.Fatalf("429 should be retryable")
+	}
+	if isRetryableError(ctx, &HTTPStatusError{StatusCode: http.StatusNotFound}) {
+		t.Fatalf("404 should not be retryable")
+	}
+	if isRetryableError(ctx, errors.New("parse openai json")) {
+		t.Fatalf("plain errors should not be retryable")
+	}
+
+	_, err := http.Get("http://127.0.0.1:1")
+	if err == nil || !isRetryableError(ctx, err) {
+		t.Fatalf("connection errors should be retryable: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if isRetryableError(cancelled, &HTTPStatusError{StatusCode: http.StatusServiceUnavailable}) {
+		t.Fatalf("errors after cancellation should not be retryable")
+	}
+}
+
+func TestOpenAiCompatibleClientRetriesRateLimit(t *testing.T) {
+	fake, server := newFakeLLMServer(t, llmfake.Options{
+		FixturesDir: writeLLMFixture(t, "text", "ok"),
+		Faults:      []llmfake.Fault{{Status: http.StatusTooManyRequests, Times: 1, RetryAfter: time.Second}},
+	})
+
+	logWriter := &stubLogWriter{}
+	retrier, waits := newTestRetrier(t, 3, nil, logWriter)
+	retrier.policy.MaxDelay = 5 * time.Second
+	client, err := NewOpenAiCompatibleClient("test-key", server.URL, 0, server.Client(), retrier, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+
+	response, err := client.Complete(context.Background(), LLMRequest{Model: "gpt-4o-mini", Prompt: "hello", Action: LogActionOpenAICSVParse})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if response.Content != "ok" {
+		t.Fatalf("content = %q, want %q", response.Content, "ok")
+	}
+	if fake.Requests() != 2 {
+		t.Fatalf("calls = %d, want 2", fake.Requests())
+	}
+	if len(*waits) != 1 || (*waits)[0] != time.Second {
+		t.Fatalf("waits = %v, want [1s]", *waits)
+	}
+	if logWriter.entries[0].action != LogActionOpenAICSVParse {
+		t.Fatalf("log action = %q, want %q", logWriter.entries[0].action, LogActionOpenAICSVParse)
+	}
+}
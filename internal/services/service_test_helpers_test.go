@@ -1,6 +1,15 @@
 package services
 
-import "context"
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"solback/internal/llmfake"
+)
 
 type loggedEntry struct {
 	eventID *string
@@ -10,6 +19,7 @@ type loggedEntry struct {
 }
 
 type stubLogWriter struct {
+	mu      sync.Mutex
 	entries []loggedEntry
 }
 
@@ -25,6 +35,8 @@ func (s *stubLogWriter) CreateLog(ctx context.Context, eventID *string, action s
 		copiedEventID = &value
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.entries = append(s.entries, loggedEntry{
 		eventID: copiedEventID,
 		action:  action,
@@ -33,3 +45,77 @@ func (s *stubLogWriter) CreateLog(ctx context.Context, eventID *string, action s
 	})
 	return nil
 }
+
+func newFakeLLMServer(t *testing.T, options llmfake.Options) (*llmfake.Server, *httptest.Server) {
+	t.Helper()
+
+	fake, err := llmfake.New(options)
+	if err != nil {
+		t.Fatalf("llmfake.New: %v", err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func writeLLMFixture(t *testing.T, schema string, content string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, schema+".json"), []byte(content), 0o644); err != nil {
+		t.Fatalf("write llm fixture: %v", err)
+	}
+	return dir
+}
+
+func newTestLLMClient(t *testing.T, server *httptest.Server) *OpenAiCompatibleClient {
+	t.Helper()
+
+	client, err := NewOpenAiCompatibleClient("test-key", server.URL, 0, server.Client(), nil, nil)
+	if err != nil {
+		t.Fatalf("NewOpenAiCompatibleClient: %v", err)
+	}
+	return client
+}
+
+type stubLLMCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+	lookups int
+}
+
+func (s *stubLLMCache) Lookup(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	response, ok := s.entries[key]
+	return response, ok, nil
+}
+
+func (s *stubLLMCache) Store(ctx context.Context, key string, model string, response string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]string)
+	}
+	s.entries[key] = response
+	return nil
+}
+
+func testPromptTemplate(t *testing.T, name string) *PromptTemplate {
+	t.Helper()
+
+	registry, err := NewPromptRegistry("", nil)
+	if err != nil {
+		t.Fatalf("NewPromptRegistry: %v", err)
+	}
+	template, err := registry.Template(name, "")
+	if err != nil {
+		t.Fatalf("Template %s: %v", name, err)
+	}
+	return template
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
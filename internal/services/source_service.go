@@ -37,3 +37,33 @@ func (s *SourceService) GetSources(ctx context.Context) ([]models.Source, error)
 
 	return sources, nil
 }
+
+func (s *SourceService) UpdateValidators(ctx context.Context, sourceID string, validators HtmlValidators) error {
+	if s == nil {
+		return errors.New("source service is nil")
+	}
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	if sourceID == "" {
+		return errors.New("source id is empty")
+	}
+
+	updates := map[string]any{
+		"etag":          optionalString(validators.ETag),
+		"last_modified": optionalString(validators.LastModified),
+		"content_hash":  optionalString(validators.ContentHash),
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Source{}).Where("id = ?", sourceID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("update source validators: %w", err)
+	}
+
+	return nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -24,7 +24,7 @@ func openTestDB(t *testing.T) *gorm.DB {
 func createSourcesTable(t *testing.T, db *gorm.DB) {
 	t.Helper()
 
-	if err := db.Exec("CREATE TABLE sources (id TEXT PRIMARY KEY, url TEXT NOT NULL, comment TEXT)").Error; err != nil {
+	if err := db.Exec("CREATE TABLE sources (id TEXT PRIMARY KEY, url TEXT NOT NULL, comment TEXT, etag TEXT, last_modified TEXT, content_hash TEXT, allowed_hosts TEXT)").Error; err != nil {
 		t.Fatalf("create sources table: %v", err)
 	}
 }
@@ -74,3 +74,37 @@ func TestSourceServiceNilReceiver(t *testing.T) {
 		t.Fatalf("GetSources nil receiver: expected error")
 	}
 }
+
+func TestSourceServiceUpdateValidators(t *testing.T) {
+	db := openTestDB(t)
+	createSourcesTable(t, db)
+
+	if err := db.Create(&models.Source{ID: "source-id", URL: "https://example.com"}).Error; err != nil {
+		t.Fatalf("insert source: %v", err)
+	}
+
+	service, err := NewSourceService(db)
+	if err != nil {
+		t.Fatalf("NewSourceService: %v", err)
+	}
+
+	if err := service.UpdateValidators(context.Background(), "source-id", HtmlValidators{ETag: `"v1"`, ContentHash: "hash"}); err != nil {
+		t.Fatalf("UpdateValidators: %v", err)
+	}
+
+	sources, err := service.GetSources(context.Background())
+	if err != nil {
+		t.Fatalf("GetSources: %v", err)
+	}
+	source := sources[0]
+	if source.ETag == nil || *source.ETag != `"v1"` || source.ContentHash == nil || *source.ContentHash != "hash" {
+		t.Fatalf("source = %+v, want stored validators", source)
+	}
+	if source.LastModified != nil {
+		t.Fatalf("LastModified = %q, want nil", *source.LastModified)
+	}
+
+	if err := service.UpdateValidators(context.Background(), "", HtmlValidators{}); err == nil {
+		t.Fatalf("UpdateValidators empty id: expected error")
+	}
+}
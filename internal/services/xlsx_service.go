@@ -4,6 +4,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -15,6 +17,7 @@ import (
 
 const (
 	aggregatedTitleMarker  = "aggregated auction results"
+	detailedTitleMarker    = "detailed auction results"
 	participantsLabelMatch = "number of participants"
 )
 
@@ -97,7 +100,6 @@ func parseXlsxFile(file *zip.File) (AuctionPayload, error) {
 		}
 		return AuctionPayload{}, err
 	}
-	_ = sheetName
 
 	participants, err := extractParticipants(rows)
 	if err != nil {
@@ -126,18 +128,70 @@ func parseXlsxFile(file *zip.File) (AuctionPayload, error) {
 		return AuctionPayload{}, errors.New("no data rows found after header")
 	}
 
+	details, err := extractDetailPayloads(workbook, sheetName, file.Name)
+	if err != nil {
+		closeErr := workbook.Close()
+		if closeErr != nil {
+			return AuctionPayload{}, fmt.Errorf("close workbook: %w", closeErr)
+		}
+		return AuctionPayload{}, err
+	}
+
 	if closeErr := workbook.Close(); closeErr != nil {
 		return AuctionPayload{}, fmt.Errorf("close workbook: %w", closeErr)
 	}
 
+	hash := sha256.Sum256(content)
+
 	return AuctionPayload{
 		SourceFile:   file.Name,
+		ContentHash:  hex.EncodeToString(hash[:]),
 		Participants: participants,
 		Headers:      headerRow,
 		Rows:         dataRows,
+		Details:      details,
 	}, nil
 }
 
+func extractDetailPayloads(workbook *excelize.File, aggregatedSheet string, sourceFile string) ([]AuctionDetailPayload, error) {
+	var details []AuctionDetailPayload
+	for _, sheet := range workbook.GetSheetList() {
+		if sheet == aggregatedSheet {
+			continue
+		}
+
+		rows, err := workbook.GetRows(sheet)
+		if err != nil {
+			return nil, fmt.Errorf("get rows for %s: %w", sheet, err)
+		}
+		if !containsTitle(rows, detailedTitleMarker) {
+			continue
+		}
+
+		// A titled sheet without the expected header is still handed on, so the
+		// detail parser reports the layout change instead of it being dropped.
+		headerIndex, headerRow, regionIndex, techIndex, err := findHeaderRow(rows)
+		if err != nil {
+			details = append(details, AuctionDetailPayload{SourceFile: sourceFile, Sheet: sheet})
+			continue
+		}
+
+		dataRows := extractDataRows(rows, headerIndex+1, len(headerRow), regionIndex, techIndex)
+		if len(dataRows) == 0 {
+			continue
+		}
+
+		details = append(details, AuctionDetailPayload{
+			SourceFile: sourceFile,
+			Sheet:      sheet,
+			Headers:    headerRow,
+			Rows:       dataRows,
+		})
+	}
+
+	return details, nil
+}
+
 func selectSheetRows(workbook *excelize.File) (string, [][]string, error) {
 	sheets := workbook.GetSheetList()
 	if len(sheets) == 0 {
@@ -148,7 +202,7 @@ func selectSheetRows(workbook *excelize.File) (string, [][]string, error) {
 	if err != nil {
 		return "", nil, fmt.Errorf("get rows for %s: %w", sheets[0], err)
 	}
-	if containsAggregatedTitle(firstRows) {
+	if containsTitle(firstRows, aggregatedTitleMarker) {
 		return sheets[0], firstRows, nil
 	}
 
@@ -157,7 +211,7 @@ func selectSheetRows(workbook *excelize.File) (string, [][]string, error) {
 		if err != nil {
 			return "", nil, fmt.Errorf("get rows for %s: %w", sheet, err)
 		}
-		if containsAggregatedTitle(rows) {
+		if containsTitle(rows, aggregatedTitleMarker) {
 			return sheet, rows, nil
 		}
 	}
@@ -165,10 +219,10 @@ func selectSheetRows(workbook *excelize.File) (string, [][]string, error) {
 	return sheets[0], firstRows, nil
 }
 
-func containsAggregatedTitle(rows [][]string) bool {
+func containsTitle(rows [][]string, marker string) bool {
 	for _, row := range rows {
 		for _, cell := range row {
-			if strings.Contains(strings.ToLower(cell), aggregatedTitleMarker) {
+			if strings.Contains(strings.ToLower(cell), marker) {
 				return true
 			}
 		}
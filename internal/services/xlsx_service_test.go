@@ -1,14 +1,75 @@
 package services
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/xuri/excelize/v2"
 )
 
+func buildDetailedWorkbookZip(t *testing.T, name string) []byte {
+	t.Helper()
+
+	workbook := excelize.NewFile()
+	aggregated := [][]interface{}{
+		{"Aggregated Auction Results"},
+		{},
+		{"Number of Participants to the Auction", "12"},
+		{},
+		{"Region", "Technology", "Total Volume Auctionned", "Total Volume Sold", "Weighted Average Price (€ / MWh)"},
+		{"Bretagne", "Solaire", "150", "100", "0.4"},
+	}
+	for i, row := range aggregated {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow("Sheet1", cell, &row); err != nil {
+			t.Fatalf("set aggregated row: %v", err)
+		}
+	}
+
+	if _, err := workbook.NewSheet("Detailed Results"); err != nil {
+		t.Fatalf("new sheet: %v", err)
+	}
+	detailed := [][]interface{}{
+		{"Detailed Auction Results"},
+		{},
+		{"Region", "Technology", "Bid Volume", "Bid Price (€ / MWh)", "Awarded"},
+		{"Bretagne", "Solaire", "100", "0.4", "Yes"},
+		{"Bretagne", "Solaire", "50", "0.1", "No"},
+	}
+	for i, row := range detailed {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow("Detailed Results", cell, &row); err != nil {
+			t.Fatalf("set detailed row: %v", err)
+		}
+	}
+
+	var xlsxBuf bytes.Buffer
+	if err := workbook.Write(&xlsxBuf); err != nil {
+		t.Fatalf("write workbook: %v", err)
+	}
+
+	var zipBuf bytes.Buffer
+	writer := zip.NewWriter(&zipBuf)
+	entry, err := writer.Create(name)
+	if err != nil {
+		t.Fatalf("create zip entry: %v", err)
+	}
+	if _, err := entry.Write(xlsxBuf.Bytes()); err != nil {
+		t.Fatalf("write zip entry: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	return zipBuf.Bytes()
+}
+
 func TestXlsxServiceExtractAuctionPayloads(t *testing.T) {
 	zipPath := filepath.Join("..", "..", "docs", "20251119_GO_2024_2025_GLOBAL_Results.zip")
 	zipBytes, err := os.ReadFile(zipPath)
@@ -81,7 +142,10 @@ func TestXlsxServiceBuildOpenAiPromptForSingleFile(t *testing.T) {
 		t.Fatalf("expected %s payload in zip", targetName)
 	}
 
-	prompt := buildCsvPrompt(target, 1)
+	prompt, err := buildCsvPrompt(testPromptTemplate(t, PromptAuctionResults), target, 1)
+	if err != nil {
+		t.Fatalf("buildCsvPrompt: %v", err)
+	}
 	if !strings.Contains(prompt, target.SourceFile) {
 		t.Fatalf("prompt missing source file")
 	}
@@ -91,3 +155,102 @@ func TestXlsxServiceBuildOpenAiPromptForSingleFile(t *testing.T) {
 
 	fmt.Printf("OpenAI prompt for %s:\n%s\n", targetName, prompt)
 }
+
+func TestXlsxServiceExtractDetailSheets(t *testing.T) {
+	name := "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx"
+	zipBytes := buildDetailedWorkbookZip(t, name)
+
+	service, err := NewXlsxService()
+	if err != nil {
+		t.Fatalf("NewXlsxService: %v", err)
+	}
+
+	payloads, err := service.ExtractAuctionPayloads(context.Background(), zipBytes)
+	if err != nil {
+		t.Fatalf("ExtractAuctionPayloads: %v", err)
+	}
+	if len(payloads) != 1 {
+		t.Fatalf("payloads = %d, want 1", len(payloads))
+	}
+	if payloads[0].Participants != 12 {
+		t.Fatalf("participants = %d, want 12", payloads[0].Participants)
+	}
+	if len(payloads[0].ContentHash) != 64 {
+		t.Fatalf("content hash = %q, want sha-256 hex", payloads[0].ContentHash)
+	}
+	if len(payloads[0].Details) != 1 {
+		t.Fatalf("details = %d, want 1", len(payloads[0].Details))
+	}
+
+	detail := payloads[0].Details[0]
+	if detail.SourceFile != name {
+		t.Fatalf("source_file = %q, want %q", detail.SourceFile, name)
+	}
+	if detail.Sheet != "Detailed Results" {
+		t.Fatalf("sheet = %q, want %q", detail.Sheet, "Detailed Results")
+	}
+	if len(detail.Rows) != 2 {
+		t.Fatalf("detail rows = %d, want 2", len(detail.Rows))
+	}
+}
+
+func TestXlsxServiceIgnoresRealAggregatedColumnsAsDetails(t *testing.T) {
+	name := "20251119_August_2025_83_GLOBAL_Results_detailedresults.xlsx"
+	workbook, err := excelize.OpenFile(filepath.Join("..", "..", "docs", name))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer workbook.Close()
+
+	// The published workbooks only carry the aggregated sheet; an untitled
+	// copy of its table must not be taken for a detail sheet.
+	rows, err := workbook.GetRows(workbook.GetSheetList()[0])
+	if err != nil {
+		t.Fatalf("get rows: %v", err)
+	}
+	if _, err := workbook.NewSheet("Participant"); err != nil {
+		t.Fatalf("new sheet: %v", err)
+	}
+	for i, row := range rows[4:] {
+		values := make([]interface{}, len(row))
+		for j, cell := range row {
+			values[j] = cell
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow("Participant", cell, &values); err != nil {
+			t.Fatalf("set row: %v", err)
+		}
+	}
+
+	var xlsxBuf bytes.Buffer
+	if err := workbook.Write(&xlsxBuf); err != nil {
+		t.Fatalf("write workbook: %v", err)
+	}
+	var zipBuf bytes.Buffer
+	writer := zip.NewWriter(&zipBuf)
+	entry, err := writer.Create(name)
+	if err != nil {
+		t.Fatalf("create zip entry: %v", err)
+	}
+	if _, err := entry.Write(xlsxBuf.Bytes()); err != nil {
+		t.Fatalf("write zip entry: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	service, err := NewXlsxService()
+	if err != nil {
+		t.Fatalf("NewXlsxService: %v", err)
+	}
+	payloads, err := service.ExtractAuctionPayloads(context.Background(), zipBuf.Bytes())
+	if err != nil {
+		t.Fatalf("ExtractAuctionPayloads: %v", err)
+	}
+	if len(payloads) != 1 || len(payloads[0].Rows) == 0 {
+		t.Fatalf("payloads = %+v, want the aggregated rows", payloads)
+	}
+	if len(payloads[0].Details) != 0 {
+		t.Fatalf("details = %d, want aggregated columns not taken for bid rows", len(payloads[0].Details))
+	}
+}
@@ -2,29 +2,45 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"solback/internal/config"
 )
 
 type ZipService struct {
 	client     *http.Client
+	retrier    *Retrier
+	maxBytes   int64
 	logService LogWriter
 }
 
-func NewZipService(logService LogWriter, client *http.Client) (*ZipService, error) {
+func NewZipService(logService LogWriter, client *http.Client, retrier *Retrier, maxBytes int64) (*ZipService, error) {
 	if logService == nil {
 		return nil, errors.New("log service is nil")
 	}
 	if client == nil {
 		client = http.DefaultClient
 	}
+	if retrier == nil {
+		retrier = singleAttemptRetrier()
+	}
+	if maxBytes < 0 {
+		return nil, errors.New("max zip bytes must not be negative")
+	}
+	if maxBytes == 0 {
+		maxBytes = config.DefaultHTTPMaxZipBytes
+	}
 
 	return &ZipService{
 		client:     client,
+		retrier:    retrier,
+		maxBytes:   maxBytes,
 		logService: logService,
 	}, nil
 }
@@ -36,6 +52,9 @@ func (s *ZipService) Download(ctx context.Context, link string, sourceURL string
 	if s.client == nil {
 		return ZipResult{}, errors.New("http client is nil")
 	}
+	if s.retrier == nil {
+		return ZipResult{}, errors.New("retrier is nil")
+	}
 	if s.logService == nil {
 		return ZipResult{}, errors.New("log service is nil")
 	}
@@ -56,43 +75,68 @@ func (s *ZipService) Download(ctx context.Context, link string, sourceURL string
 		return ZipResult{}, errors.New("zip url must end with .zip")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, zipURL, nil)
-	if err != nil {
-		failMsg := fmt.Sprintf("build zip request: %v", err)
-		_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
-		return ZipResult{}, fmt.Errorf("build zip request: %w", err)
-	}
-
-	resp, err := s.client.Do(req)
+	client := s.client
+	if policy := egressPolicyFrom(ctx); policy != nil {
+		if err := policy.Check(zipURL); err != nil {
+			failMsg := fmt.Sprintf("zip rejected reason=egress url=%s allowed=%s: %v", zipURL, strings.Join(policy.AllowedHosts(), ","), err)
+			_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
+			return ZipResult{URL: zipURL}, err
+		}
+		client = egressClient(s.client, policy)
+	}
+
+	var body []byte
+	var statusCode int
+	err = s.retrier.Do(ctx, upstreamHost(zipURL), LogActionZipDownload, eventID, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, zipURL, nil)
+		if err != nil {
+			return fmt.Errorf("build zip request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("download zip: %w", err)
+		}
+
+		data, readErr := readLimitedBody(resp, s.maxBytes)
+		closeErr := resp.Body.Close()
+		statusCode = resp.StatusCode
+		if readErr != nil {
+			return fmt.Errorf("read zip response: %w", readErr)
+		}
+		if closeErr != nil {
+			return fmt.Errorf("close zip response: %w", closeErr)
+		}
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			return newHTTPStatusError("zip download", resp, nil)
+		}
+		if err := checkZipContent(resp.Header.Get("Content-Type"), data); err != nil {
+			return fmt.Errorf("zip response: %w", err)
+		}
+
+		body = data
+		return nil
+	})
 	if err != nil {
-		failMsg := fmt.Sprintf("download zip: %v", err)
+		failMsg := fmt.Sprintf("zip download status=%d url=%s: %v", statusCode, zipURL, err)
+		switch {
+		case errors.Is(err, ErrBodyTooLarge):
+			failMsg = fmt.Sprintf("zip rejected reason=too_large status=%d url=%s limit=%d: %v", statusCode, zipURL, s.maxBytes, err)
+		case errors.Is(err, ErrUnexpectedContentType):
+			failMsg = fmt.Sprintf("zip rejected reason=content_type status=%d url=%s: %v", statusCode, zipURL, err)
+		case errors.Is(err, ErrEgressBlocked):
+			failMsg = fmt.Sprintf("zip rejected reason=egress status=%d url=%s: %v", statusCode, zipURL, err)
+		}
 		_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
-		return ZipResult{}, fmt.Errorf("download zip: %w", err)
+		return ZipResult{URL: zipURL, StatusCode: statusCode}, err
 	}
 
-	body, readErr := io.ReadAll(resp.Body)
-	closeErr := resp.Body.Close()
-	if readErr != nil {
-		failMsg := fmt.Sprintf("read zip response: %v", readErr)
-		_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
-		return ZipResult{}, fmt.Errorf("read zip response: %w", readErr)
-	}
-	if closeErr != nil {
-		failMsg := fmt.Sprintf("close zip response: %v", closeErr)
-		_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
-		return ZipResult{}, fmt.Errorf("close zip response: %w", closeErr)
-	}
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		failMsg := fmt.Sprintf("zip download status=%d url=%s", resp.StatusCode, zipURL)
-		_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeFail, &failMsg)
-		return ZipResult{URL: zipURL, StatusCode: resp.StatusCode}, fmt.Errorf("zip download failed with status %d", resp.StatusCode)
-	}
-
-	successMsg := fmt.Sprintf("zip download status=%d url=%s bytes=%d", resp.StatusCode, zipURL, len(body))
+	successMsg := fmt.Sprintf("zip download status=%d url=%s bytes=%d", statusCode, zipURL, len(body))
 	_ = s.logService.CreateLog(ctx, eventID, LogActionZipDownload, LogOutcomeSuccess, &successMsg)
 
-	return ZipResult{URL: zipURL, StatusCode: resp.StatusCode, Bytes: body}, nil
+	hash := sha256.Sum256(body)
+
+	return ZipResult{URL: zipURL, StatusCode: statusCode, Bytes: body, ContentHash: hex.EncodeToString(hash[:])}, nil
 }
 
 func resolveZipURL(link string, sourceURL string) (string, error) {
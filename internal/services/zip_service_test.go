@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -28,7 +29,7 @@ func TestZipServiceDownloadRelativeLink(t *testing.T) {
 	defer server.Close()
 
 	logWriter := &stubLogWriter{}
-	service, err := NewZipService(logWriter, server.Client())
+	service, err := NewZipService(logWriter, server.Client(), nil, 0)
 	if err != nil {
 		t.Fatalf("NewZipService: %v", err)
 	}
@@ -43,6 +44,9 @@ func TestZipServiceDownloadRelativeLink(t *testing.T) {
 	if len(result.Bytes) != len(zipBytes) {
 		t.Fatalf("zip bytes length = %d, want %d", len(result.Bytes), len(zipBytes))
 	}
+	if len(result.ContentHash) != 64 {
+		t.Fatalf("content hash length = %d, want 64", len(result.ContentHash))
+	}
 	if !strings.HasPrefix(result.URL, server.URL) {
 		t.Fatalf("resolved url = %q, want prefix %q", result.URL, server.URL)
 	}
@@ -56,7 +60,7 @@ func TestZipServiceDownloadRelativeLink(t *testing.T) {
 
 func TestZipServiceRejectsNonZip(t *testing.T) {
 	logWriter := &stubLogWriter{}
-	service, err := NewZipService(logWriter, http.DefaultClient)
+	service, err := NewZipService(logWriter, http.DefaultClient, nil, 0)
 	if err != nil {
 		t.Fatalf("NewZipService: %v", err)
 	}
@@ -68,3 +72,84 @@ func TestZipServiceRejectsNonZip(t *testing.T) {
 		t.Fatalf("expected log entries")
 	}
 }
+
+func TestZipServiceRejectsOversizedAndMislabeledBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/declared.zip":
+			w.Header().Set("Content-Length", "4096")
+			_, _ = w.Write(make([]byte, 4096))
+		case "/streamed.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write([]byte("PK\x03\x04"))
+			w.(http.Flusher).Flush()
+			_, _ = w.Write(make([]byte, 2048))
+		case "/error-page.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>Maintenance</body></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path   string
+		err    error
+		reason string
+	}{
+		{path: "/declared.zip", err: ErrBodyTooLarge, reason: "reason=too_large"},
+		{path: "/streamed.zip", err: ErrBodyTooLarge, reason: "reason=too_large"},
+		{path: "/error-page.zip", err: ErrUnexpectedContentType, reason: "reason=content_type"},
+	}
+	for _, tc := range cases {
+		logWriter := &stubLogWriter{}
+		service, err := NewZipService(logWriter, server.Client(), nil, 1024)
+		if err != nil {
+			t.Fatalf("NewZipService: %v", err)
+		}
+
+		if _, err := service.Download(context.Background(), server.URL+tc.path, "", nil); !errors.Is(err, tc.err) {
+			t.Fatalf("%s err = %v, want %v", tc.path, err, tc.err)
+		}
+		last := logWriter.entries[len(logWriter.entries)-1]
+		if last.outcome != LogOutcomeFail || last.message == nil || !strings.Contains(*last.message, tc.reason) {
+			t.Fatalf("%s log = %v, want FAIL with %s", tc.path, last.message, tc.reason)
+		}
+	}
+
+	if _, err := NewZipService(&stubLogWriter{}, nil, nil, -1); err == nil {
+		t.Fatalf("NewZipService negative limit: expected error")
+	}
+}
+
+func TestZipServiceEnforcesEgressPolicy(t *testing.T) {
+	var target string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusFound)
+	}))
+	defer server.Close()
+	target = strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/moved.zip"
+
+	policy, err := NewEgressPolicy(server.URL+"/page", nil)
+	if err != nil {
+		t.Fatalf("NewEgressPolicy: %v", err)
+	}
+	ctx := WithEgressPolicy(context.Background(), policy)
+
+	for _, link := range []string{"https://internal.example/file.zip", "/redirect.zip"} {
+		logWriter := &stubLogWriter{}
+		service, err := NewZipService(logWriter, server.Client(), nil, 0)
+		if err != nil {
+			t.Fatalf("NewZipService: %v", err)
+		}
+
+		if _, err := service.Download(ctx, link, server.URL+"/page", nil); !errors.Is(err, ErrEgressBlocked) {
+			t.Fatalf("%s err = %v, want ErrEgressBlocked", link, err)
+		}
+		last := logWriter.entries[len(logWriter.entries)-1]
+		if last.outcome != LogOutcomeFail || last.message == nil || !strings.Contains(*last.message, "reason=egress") {
+			t.Fatalf("%s log = %v, want FAIL with reason=egress", link, last.message)
+		}
+	}
+}